@@ -1,3 +1,16 @@
+DROP TABLE IF EXISTS public.api_keys;
+DROP TABLE IF EXISTS public.refresh_tokens;
+DROP TABLE IF EXISTS public.access_audit;
+DROP TABLE IF EXISTS public.video_uploads;
+DROP TABLE IF EXISTS public.download_revocations;
+DROP TABLE IF EXISTS public.video_previews;
+DROP TABLE IF EXISTS public.video_metadata;
+DROP TABLE IF EXISTS public.legal_holds;
+DROP TABLE IF EXISTS public.deliveries;
+DROP TABLE IF EXISTS public.exports;
+DROP TABLE IF EXISTS public.archive_jobs;
+DROP TABLE IF EXISTS public.stream_sources;
+DROP TABLE IF EXISTS public.cameras;
 DROP TABLE IF EXISTS public.videos;
 DROP TABLE IF EXISTS public.infos;
 DROP TABLE IF EXISTS public.user_groups;
@@ -54,7 +67,29 @@ INSERT INTO public.perms (id, name, code_name) VALUES
 (43, 'Can partially update an Info',     'patch_info'),
 (44, 'Can delete an Info',               'delete_info'),
 
-(50, 'Can download a File',               'download_file');
+(50, 'Can download a File',               'download_file'),
+
+(60, 'Can get a Stream',                 'get_stream'),
+(61, 'Can get all of the Streams',       'get_all_streams'),
+(62, 'Can set a Camera metadata',        'set_camera'),
+(63, 'Can delete a Camera metadata',     'delete_camera'),
+(64, 'Can get the Streams usage',        'get_stream_usage'),
+(65, 'Can set a Stream source',          'set_stream_source'),
+(66, 'Can get a Stream source',          'get_stream_source'),
+(67, 'Can delete a Stream source',       'delete_stream_source'),
+(68, 'Can export an archive clip',      'post_export'),
+(69, 'Can get a retention report',       'get_retention_report'),
+(70, 'Can set a legal hold',             'set_legal_hold'),
+(71, 'Can delete a legal hold',          'delete_legal_hold'),
+(72, 'Can get a Stream timeline',        'get_stream_timeline'),
+(73, 'Can reprobe a Video',              'reprobe_video'),
+(74, 'Can revoke download links',        'revoke_download_links'),
+(75, 'Can get an access audit',          'get_access_audit'),
+(76, 'Can get user sessions',            'get_user_sessions'),
+(77, 'Can delete user sessions',         'delete_user_sessions'),
+(78, 'Can post an API key',              'post_api_key'),
+(79, 'Can get API keys',                 'get_api_keys'),
+(80, 'Can delete an API key',            'delete_api_key');
 
 -------------------------------------------------------------------------------
 
@@ -86,10 +121,18 @@ ALTER SEQUENCE users_id_seq RESTART WITH 1;
 -------------------------------------------------------------------------------
 
 CREATE TABLE IF NOT EXISTS public.sessions (
-    id            SERIAL                   NOT NULL UNIQUE,
-    content       VARCHAR(640)             NOT NULL,
-    creation_date TIMESTAMP WITH TIME ZONE NOT NULL,
-    CONSTRAINT pk_sessions PRIMARY KEY (id)
+    id             SERIAL                   NOT NULL UNIQUE,
+    user_id        INTEGER                  NOT NULL,
+    client_ip      VARCHAR(45)              NOT NULL DEFAULT '',
+    user_agent     VARCHAR(255)             NOT NULL DEFAULT '',
+    creation_date  TIMESTAMP WITH TIME ZONE NOT NULL,
+    refresh_date   TIMESTAMP WITH TIME ZONE NOT NULL, -- the last refresh token was issued
+    last_seen_date TIMESTAMP WITH TIME ZONE NOT NULL,
+    CONSTRAINT pk_sessions PRIMARY KEY (id),
+    CONSTRAINT fk_sessions_users FOREIGN KEY (user_id)
+		REFERENCES public.users (id) MATCH SIMPLE
+		ON UPDATE NO ACTION
+		ON DELETE CASCADE
 );
 
 -------------------------------------------------------------------------------
@@ -197,3 +240,217 @@ CREATE TABLE IF NOT EXISTS public.videos (
 		ON UPDATE NO ACTION
 		ON DELETE CASCADE
 );
+
+-------------------------------------------------------------------------------
+
+CREATE TABLE IF NOT EXISTS public.cameras (
+    id            SERIAL           NOT NULL UNIQUE,
+    stream_id     INTEGER          NOT NULL UNIQUE,
+    display_name  VARCHAR(100)     NOT NULL,
+    site          VARCHAR(100)     NOT NULL,
+    building      VARCHAR(100)     NOT NULL,
+    floor         VARCHAR(30)      NOT NULL,
+    latitude      DOUBLE PRECISION,
+    longitude     DOUBLE PRECISION,
+    tags          TEXT[]           NOT NULL DEFAULT '{}',
+    camera_groups TEXT[]           NOT NULL DEFAULT '{}',
+    CONSTRAINT pk_cameras PRIMARY KEY (id)
+);
+
+-------------------------------------------------------------------------------
+
+CREATE TABLE IF NOT EXISTS public.stream_sources (
+    id           SERIAL      NOT NULL UNIQUE,
+    stream_id    INTEGER     NOT NULL UNIQUE,
+    url          TEXT        NOT NULL,
+    transport    VARCHAR(3)  NOT NULL DEFAULT 'tcp',
+    username_enc TEXT        NOT NULL DEFAULT '',
+    password_enc TEXT        NOT NULL DEFAULT '',
+    CONSTRAINT pk_stream_sources PRIMARY KEY (id)
+);
+
+-------------------------------------------------------------------------------
+
+CREATE TABLE IF NOT EXISTS public.archive_jobs (
+    id          SERIAL                   NOT NULL UNIQUE,
+    request_id  INTEGER                  NOT NULL UNIQUE, -- "RequestVideoArchive"."ID"
+    state       VARCHAR(7)               NOT NULL,        -- "queued", "running", "done", "failed"
+    attempts    INTEGER                  NOT NULL DEFAULT 0,
+    last_error  TEXT                     NOT NULL DEFAULT '',
+    output_path TEXT                     NOT NULL DEFAULT '',
+    update_date TIMESTAMP WITH TIME ZONE NOT NULL,
+    CONSTRAINT pk_archive_jobs PRIMARY KEY (id)
+);
+
+-------------------------------------------------------------------------------
+
+CREATE TABLE IF NOT EXISTS public.exports (
+    id            SERIAL                   NOT NULL UNIQUE,
+    user_id       INTEGER                  NOT NULL,
+    stream_id     INTEGER                  NOT NULL,
+    path_stream   TEXT                     NOT NULL,
+    from_time     VARCHAR(19)              NOT NULL,        -- "2006-01-02 15:04:05"
+    to_time       VARCHAR(19)              NOT NULL,
+    state         VARCHAR(7)               NOT NULL,        -- "queued", "running", "done", "failed"
+    progress      INTEGER                  NOT NULL DEFAULT 0,
+    error         TEXT                     NOT NULL DEFAULT '',
+    accurate      BOOLEAN                  NOT NULL DEFAULT FALSE,
+    actual_from   VARCHAR(23)              NOT NULL DEFAULT '', -- real start, "2006-01-02 15:04:05.000"
+    actual_to     VARCHAR(23)              NOT NULL DEFAULT '',
+    overlay       TEXT,                                     -- JSON of the burned-in overlay settings
+    media         TEXT,                                     -- JSON of the probed clip metadata
+    file_path     TEXT                     NOT NULL DEFAULT '',
+    creation_date TIMESTAMP WITH TIME ZONE NOT NULL,
+    update_date   TIMESTAMP WITH TIME ZONE NOT NULL,
+    CONSTRAINT pk_exports PRIMARY KEY (id),
+    CONSTRAINT fk_exports_users FOREIGN KEY (user_id)
+		REFERENCES public.users (id) MATCH SIMPLE
+		ON UPDATE NO ACTION
+		ON DELETE CASCADE
+);
+
+-------------------------------------------------------------------------------
+
+CREATE TABLE IF NOT EXISTS public.deliveries (
+    id          SERIAL                   NOT NULL UNIQUE,
+    source      VARCHAR(7)               NOT NULL,        -- "archive", "export"
+    source_id   INTEGER                  NOT NULL,        -- archive_jobs.request_id, exports.id
+    target      VARCHAR(100)             NOT NULL,        -- name from delivery.targets
+    state       VARCHAR(7)               NOT NULL,        -- "queued", "running", "done", "failed"
+    attempts    INTEGER                  NOT NULL DEFAULT 0,
+    last_error  TEXT                     NOT NULL DEFAULT '',
+    file_path   TEXT                     NOT NULL,
+    update_date TIMESTAMP WITH TIME ZONE NOT NULL,
+    CONSTRAINT pk_deliveries PRIMARY KEY (id),
+    CONSTRAINT uq_deliveries UNIQUE (source, source_id, target)
+);
+
+-------------------------------------------------------------------------------
+
+CREATE TABLE IF NOT EXISTS public.legal_holds (
+    id            SERIAL                   NOT NULL UNIQUE,
+    info_id       INTEGER                  NOT NULL UNIQUE, -- infos held from the retention
+    reason        TEXT                     NOT NULL DEFAULT '',
+    user_id       INTEGER                  NOT NULL,
+    creation_date TIMESTAMP WITH TIME ZONE NOT NULL,
+    CONSTRAINT pk_legal_holds PRIMARY KEY (id),
+    CONSTRAINT fk_legal_holds_users FOREIGN KEY (user_id)
+		REFERENCES public.users (id) MATCH SIMPLE
+		ON UPDATE NO ACTION
+		ON DELETE CASCADE
+);
+
+-------------------------------------------------------------------------------
+
+CREATE TABLE IF NOT EXISTS public.video_metadata (
+    video_id    INTEGER                  NOT NULL,        -- video_archive.id of the outer database
+    duration    DOUBLE PRECISION         NOT NULL DEFAULT 0, -- seconds
+    container   VARCHAR(100)             NOT NULL DEFAULT '',
+    video_codec VARCHAR(50)              NOT NULL DEFAULT '',
+    audio_codec VARCHAR(50)              NOT NULL DEFAULT '',
+    width       INTEGER                  NOT NULL DEFAULT 0,
+    height      INTEGER                  NOT NULL DEFAULT 0,
+    frame_rate  DOUBLE PRECISION         NOT NULL DEFAULT 0,
+    bitrate     BIGINT                   NOT NULL DEFAULT 0, -- bits per second
+    size        BIGINT                   NOT NULL DEFAULT 0, -- bytes
+    probe_error TEXT                     NOT NULL DEFAULT '',
+    probe_date  TIMESTAMP WITH TIME ZONE NOT NULL,
+    CONSTRAINT pk_video_metadata PRIMARY KEY (video_id)
+);
+
+-------------------------------------------------------------------------------
+
+CREATE TABLE IF NOT EXISTS public.video_previews (
+    video_id    INTEGER                  NOT NULL,        -- video_archive.id of the outer database
+    state       VARCHAR(7)               NOT NULL,        -- "queued", "running", "done", "failed"
+    error       TEXT                     NOT NULL DEFAULT '',
+    update_date TIMESTAMP WITH TIME ZONE NOT NULL,
+    CONSTRAINT pk_video_previews PRIMARY KEY (video_id)
+);
+
+-------------------------------------------------------------------------------
+
+CREATE TABLE IF NOT EXISTS public.download_revocations (
+    user_id     INTEGER                  NOT NULL,
+    revoke_date TIMESTAMP WITH TIME ZONE NOT NULL, -- links issued before are invalid
+    CONSTRAINT pk_download_revocations PRIMARY KEY (user_id),
+    CONSTRAINT fk_download_revocations_users FOREIGN KEY (user_id)
+		REFERENCES public.users (id) MATCH SIMPLE
+		ON UPDATE NO ACTION
+		ON DELETE CASCADE
+);
+
+-------------------------------------------------------------------------------
+
+CREATE TABLE IF NOT EXISTS public.video_uploads (
+    id            VARCHAR(32)              NOT NULL,
+    user_id       INTEGER                  NOT NULL,
+    info_id       INTEGER                  NOT NULL,
+    url           VARCHAR(1024)            NOT NULL DEFAULT '',
+    file_name     VARCHAR(260)             NOT NULL DEFAULT '', -- name on the client
+    file          VARCHAR(260)             NOT NULL,            -- path in media.dir
+    length        BIGINT                   NOT NULL,
+    upload_offset BIGINT                   NOT NULL DEFAULT 0,
+    video_id      INTEGER                  NOT NULL DEFAULT 0,  -- video_archive.id when completed
+    creation_date TIMESTAMP WITH TIME ZONE NOT NULL,
+    update_date   TIMESTAMP WITH TIME ZONE NOT NULL,
+    CONSTRAINT pk_video_uploads PRIMARY KEY (id),
+    CONSTRAINT fk_video_uploads_users FOREIGN KEY (user_id)
+		REFERENCES public.users (id) MATCH SIMPLE
+		ON UPDATE NO ACTION
+		ON DELETE CASCADE
+);
+
+-------------------------------------------------------------------------------
+
+CREATE TABLE IF NOT EXISTS public.access_audit (
+    id         SERIAL                   NOT NULL UNIQUE,
+    kind       VARCHAR(10)              NOT NULL, -- media, bundle, export or live
+    user_id    INTEGER                  NOT NULL, -- -1 for anonymous viewers
+    stream     VARCHAR(255)             NOT NULL DEFAULT '',
+    video_id   INTEGER                  NOT NULL DEFAULT 0,
+    export_id  INTEGER                  NOT NULL DEFAULT 0,
+    file       VARCHAR(1024)            NOT NULL DEFAULT '',
+    client_ip  VARCHAR(45)              NOT NULL DEFAULT '',
+    bytes_sent BIGINT                   NOT NULL DEFAULT 0,
+    start_date TIMESTAMP WITH TIME ZONE NOT NULL,
+    end_date   TIMESTAMP WITH TIME ZONE NOT NULL,
+    CONSTRAINT pk_access_audit PRIMARY KEY (id)
+);
+
+-------------------------------------------------------------------------------
+
+CREATE TABLE IF NOT EXISTS public.refresh_tokens (
+    id            SERIAL                   NOT NULL UNIQUE,
+    session_id    INTEGER                  NOT NULL,
+    token_hash    VARCHAR(64)              NOT NULL UNIQUE, -- SHA-256 of the token
+    is_used       BOOLEAN                  NOT NULL DEFAULT FALSE,
+    creation_date TIMESTAMP WITH TIME ZONE NOT NULL,
+    CONSTRAINT pk_refresh_tokens PRIMARY KEY (id),
+    CONSTRAINT fk_refresh_tokens_sessions FOREIGN KEY (session_id)
+		REFERENCES public.sessions (id) MATCH SIMPLE
+		ON UPDATE NO ACTION
+		ON DELETE CASCADE
+);
+
+-------------------------------------------------------------------------------
+
+CREATE TABLE IF NOT EXISTS public.api_keys (
+    id              SERIAL                   NOT NULL UNIQUE,
+    name            VARCHAR(100)             NOT NULL,
+    user_id         INTEGER                  NOT NULL,
+    key_prefix      VARCHAR(12)              NOT NULL, -- the start of the key shown in the lists
+    key_hash        VARCHAR(64)              NOT NULL UNIQUE, -- SHA-256 of the key
+    scopes          VARCHAR(30)[]            NOT NULL, -- code names of the perms
+    allowed_ips     VARCHAR(50)[]            NOT NULL DEFAULT '{}', -- addresses or CIDR ranges, empty allows any
+    expiration_date TIMESTAMP WITH TIME ZONE NULL,
+    last_used_date  TIMESTAMP WITH TIME ZONE NULL,
+    last_used_ip    VARCHAR(45)              NOT NULL DEFAULT '',
+    created_by      INTEGER                  NOT NULL,
+    creation_date   TIMESTAMP WITH TIME ZONE NOT NULL,
+    CONSTRAINT pk_api_keys PRIMARY KEY (id),
+    CONSTRAINT fk_api_keys_users FOREIGN KEY (user_id)
+		REFERENCES public.users (id) MATCH SIMPLE
+		ON UPDATE NO ACTION
+		ON DELETE CASCADE
+);
@@ -0,0 +1,74 @@
+package apikey
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Prefix starts every API key, it tells the keys apart from the session
+// tokens in the Authorization header.
+const Prefix = "vhk_"
+
+// ApiKey lets a service account act as its user with the permissions of
+// the scopes only. The key itself is shown once on creation, only its
+// hash is stored.
+type ApiKey struct {
+	Id             int      `json:"id"             db:"id"`
+	Name           string   `json:"name"           db:"name"`
+	UserId         int      `json:"userId"         db:"user_id"`
+	Username       string   `json:"username"       db:"username"`
+	KeyPrefix      string   `json:"keyPrefix"      db:"key_prefix"`
+	Scopes         []string `json:"scopes"         db:"scopes"`
+	AllowedIPs     []string `json:"allowedIps"     db:"allowed_ips"`
+	ExpirationDate string   `json:"expirationDate" db:"expiration_date"`
+	LastUsedDate   string   `json:"lastUsedDate"   db:"last_used_date"`
+	LastUsedIP     string   `json:"lastUsedIp"     db:"last_used_ip"`
+	CreatedBy      int      `json:"createdBy"      db:"created_by"`
+	CreationDate   string   `json:"creationDate"   db:"creation_date"`
+	Key            string   `json:"key,omitempty"  db:"-"`
+}
+
+// ApiKeyRequest describes a new key. The allowed IPs are addresses or
+// CIDR ranges, an empty list allows any address. The expiration date is
+// RFC 3339, empty for a key that does not expire.
+type ApiKeyRequest struct {
+	Name           string   `json:"name"`
+	UserId         int      `json:"userId"`
+	Scopes         []string `json:"scopes"`
+	AllowedIPs     []string `json:"allowedIps"`
+	ExpirationDate string   `json:"expirationDate"`
+}
+
+func IsApiKey(token string) bool {
+	return strings.HasPrefix(token, Prefix)
+}
+
+type ApiKeyCommon interface {
+	GetApiKeys() ([]*ApiKey, error)
+	IsApiKeyExists(id int) (bool, error)
+	DeleteApiKey(id int) error
+}
+
+type ApiKeyUseCase interface {
+	ApiKeyCommon
+
+	BindJSONApiKeyRequest(ctx *gin.Context) (*ApiKeyRequest, error)
+	IsApiKeyRequiredEmpty(name string) bool
+	IsAllowedIPsValid(allowedIPs []string) bool
+	IsExpirationDateValid(expirationDate string) bool
+	IsScopesValid(scopes []string) (bool, error)
+	IsScopesHeld(userId int, scopes []string) (bool, error)
+	CreateApiKey(req *ApiKeyRequest, createdBy int) (*ApiKey, error)
+	AtoiRequestedId(ctx *gin.Context) (int, error)
+}
+
+type ApiKeyRepository interface {
+	ApiKeyCommon
+
+	CreateApiKey(key *ApiKey, keyHash string) (int, error)
+	GetApiKeyByHash(keyHash string) (*ApiKey, error)
+	UpdateApiKeyLastUsed(id int, timestamp, clientIP string) error
+	GetExistingScopes(scopes []string) ([]string, error)
+	GetHeldScopes(userId int, scopes []string) ([]string, error)
+}
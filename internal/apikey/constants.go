@@ -0,0 +1,17 @@
+package apikey
+
+const (
+	TableName      = "api_keys"
+	Id             = "id"
+	Name           = "name"
+	UserId         = "user_id"
+	KeyPrefix      = "key_prefix"
+	KeyHash        = "key_hash"
+	Scopes         = "scopes"
+	AllowedIPs     = "allowed_ips"
+	ExpirationDate = "expiration_date"
+	LastUsedDate   = "last_used_date"
+	LastUsedIP     = "last_used_ip"
+	CreatedBy      = "created_by"
+	CreationDate   = "creation_date"
+)
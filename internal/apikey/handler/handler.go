@@ -0,0 +1,244 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"vhosting/internal/apikey"
+	msg "vhosting/internal/messages"
+	sess "vhosting/internal/session"
+	"vhosting/pkg/auth"
+	"vhosting/pkg/config"
+	"vhosting/pkg/logger"
+	"vhosting/pkg/timedate"
+	"vhosting/pkg/user"
+)
+
+type ApiKeyHandler struct {
+	cfg         *config.Config
+	useCase     apikey.ApiKeyUseCase
+	logUseCase  logger.LogUseCase
+	authUseCase auth.AuthUseCase
+	sessUseCase sess.SessUseCase
+	userUseCase user.UserUseCase
+}
+
+func NewApiKeyHandler(cfg *config.Config, useCase apikey.ApiKeyUseCase,
+	logUseCase logger.LogUseCase, authUseCase auth.AuthUseCase,
+	sessUseCase sess.SessUseCase, userUseCase user.UserUseCase) *ApiKeyHandler {
+	return &ApiKeyHandler{
+		cfg:         cfg,
+		useCase:     useCase,
+		logUseCase:  logUseCase,
+		authUseCase: authUseCase,
+		sessUseCase: sessUseCase,
+		userUseCase: userUseCase,
+	}
+}
+
+// CreateApiKey issues a key acting as the user with the requested scopes,
+// the key itself is returned only in this response. Only superusers and
+// staff create keys and only with the permissions the user already holds.
+func (h *ApiKeyHandler) CreateApiKey(ctx *gin.Context) {
+	actPermission := "post_api_key"
+
+	log := logger.Init(ctx)
+
+	hasPerms, userId := h.isPermsGranted_getUserId(ctx, log, actPermission)
+	if !hasPerms {
+		return
+	}
+
+	isSUorStaff, err := h.userUseCase.IsUserSuperuserOrStaff(log.SessionOwner)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotCheckSuperuserStaffPermissions(err))
+		return
+	}
+	if !isSUorStaff {
+		h.logUseCase.Report(ctx, log, msg.ErrorOnlyStaffCanCreateApiKeys())
+		return
+	}
+
+	// Read input, check required fields, check user existence
+	req, err := h.useCase.BindJSONApiKeyRequest(ctx)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotBindInputData(err))
+		return
+	}
+
+	if h.useCase.IsApiKeyRequiredEmpty(req.Name) {
+		h.logUseCase.Report(ctx, log, msg.ErrorApiKeyNameCannotBeEmpty())
+		return
+	}
+
+	exists, err := h.userUseCase.IsUserExists(req.UserId)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotCheckUserExistence(err))
+		return
+	}
+	if !exists {
+		h.logUseCase.Report(ctx, log, msg.ErrorUserWithRequestedIDIsNotExist())
+		return
+	}
+
+	valid, err := h.useCase.IsScopesValid(req.Scopes)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotCheckApiKeyScopes(err))
+		return
+	}
+	if !valid {
+		h.logUseCase.Report(ctx, log, msg.ErrorApiKeyScopesAreInvalid())
+		return
+	}
+
+	// The key must not get permissions which its user does not have
+	held, err := h.useCase.IsScopesHeld(req.UserId, req.Scopes)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotCheckApiKeyScopes(err))
+		return
+	}
+	if !held {
+		h.logUseCase.Report(ctx, log, msg.ErrorApiKeyScopesAreNotHeldByUser())
+		return
+	}
+
+	if !h.useCase.IsAllowedIPsValid(req.AllowedIPs) {
+		h.logUseCase.Report(ctx, log, msg.ErrorApiKeyAllowedIPsAreInvalid())
+		return
+	}
+
+	if !h.useCase.IsExpirationDateValid(req.ExpirationDate) {
+		h.logUseCase.Report(ctx, log, msg.ErrorApiKeyExpirationDateIsInvalid())
+		return
+	}
+
+	// Create API key
+	key, err := h.useCase.CreateApiKey(req, userId)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotCreateApiKey(err))
+		return
+	}
+
+	h.logUseCase.Report(ctx, log, msg.InfoApiKeyCreated(key))
+}
+
+func (h *ApiKeyHandler) GetApiKeys(ctx *gin.Context) {
+	actPermission := "get_api_keys"
+
+	log := logger.Init(ctx)
+
+	hasPerms, _ := h.isPermsGranted_getUserId(ctx, log, actPermission)
+	if !hasPerms {
+		return
+	}
+
+	keys, err := h.useCase.GetApiKeys()
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotGetApiKeys(err))
+		return
+	}
+
+	h.logUseCase.Report(ctx, log, msg.InfoGotApiKeys(keys))
+}
+
+func (h *ApiKeyHandler) DeleteApiKey(ctx *gin.Context) {
+	actPermission := "delete_api_key"
+
+	log := logger.Init(ctx)
+
+	hasPerms, _ := h.isPermsGranted_getUserId(ctx, log, actPermission)
+	if !hasPerms {
+		return
+	}
+
+	reqId, err := h.useCase.AtoiRequestedId(ctx)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotConvertRequestedIDToTypeInt(err))
+		return
+	}
+
+	exists, err := h.useCase.IsApiKeyExists(reqId)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotCheckApiKeyExistence(err))
+		return
+	}
+	if !exists {
+		h.logUseCase.Report(ctx, log, msg.ErrorApiKeyWithRequestedIDIsNotExist())
+		return
+	}
+
+	if err := h.useCase.DeleteApiKey(reqId); err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotDeleteApiKey(err))
+		return
+	}
+
+	h.logUseCase.Report(ctx, log, msg.InfoApiKeyDeleted())
+}
+
+func (h *ApiKeyHandler) isPermsGranted_getUserId(ctx *gin.Context, log *logger.Log, permission string) (bool, int) {
+	headerToken := h.authUseCase.ReadHeader(ctx)
+	if !h.authUseCase.IsTokenExists(headerToken) {
+		h.logUseCase.Report(ctx, log, msg.ErrorYouHaveNotEnoughPermissions())
+		return false, -1
+	}
+
+	session, err := h.sessUseCase.GetSessionAndDate(ctx, headerToken)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotGetSessionAndDate(err))
+		return false, -1
+	}
+	if !h.authUseCase.IsSessionExists(session) {
+		h.logUseCase.Report(ctx, log, msg.ErrorYouHaveNotEnoughPermissions())
+		return false, -1
+	}
+
+	// A leaked key must not be able to issue more keys
+	if session.ApiKeyId != 0 {
+		log.SessionOwner = session.Username
+		h.logUseCase.Report(ctx, log, msg.ErrorApiKeysCannotManageApiKeys())
+		return false, -1
+	}
+
+	if timedate.IsDateExpired(session.RefreshDate, h.cfg.SessionTTLHours) {
+		if err := h.sessUseCase.DeleteSession(headerToken); err != nil {
+			h.logUseCase.Report(ctx, log, msg.ErrorCannotDeleteSession(err))
+			return false, -1
+		}
+		h.logUseCase.Report(ctx, log, msg.ErrorYouHaveNotEnoughPermissions())
+		return false, -1
+	}
+
+	gottenUserId, err := h.userUseCase.GetUserId(session.Username)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotCheckUserExistence(err))
+		return false, -1
+	}
+	if gottenUserId < 0 {
+		if err := h.sessUseCase.DeleteSession(headerToken); err != nil {
+			h.logUseCase.Report(ctx, log, msg.ErrorCannotDeleteSession(err))
+			return false, -1
+		}
+		h.logUseCase.Report(ctx, log, msg.ErrorUserWithThisUsernameIsNotExist())
+		return false, -1
+	}
+
+	log.SessionOwner = session.Username
+
+	isSUorStaff := false
+	hasPersonalPerm := false
+	if isSUorStaff, err = h.userUseCase.IsUserSuperuserOrStaff(session.Username); err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotCheckSuperuserStaffPermissions(err))
+		return false, -1
+	}
+	if !isSUorStaff {
+		if hasPersonalPerm, err = h.userUseCase.IsUserHavePersonalPermission(gottenUserId, permission); err != nil {
+			h.logUseCase.Report(ctx, log, msg.ErrorCannotCheckPersonalPermission(err))
+			return false, -1
+		}
+	}
+
+	if !isSUorStaff && !hasPersonalPerm {
+		h.logUseCase.Report(ctx, log, msg.ErrorYouHaveNotEnoughPermissions())
+		return false, -1
+	}
+
+	return true, gottenUserId
+}
@@ -0,0 +1,23 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"vhosting/internal/apikey"
+	sess "vhosting/internal/session"
+	"vhosting/pkg/auth"
+	"vhosting/pkg/config"
+	"vhosting/pkg/logger"
+	"vhosting/pkg/user"
+)
+
+func RegisterHTTPEndpoints(router *gin.Engine, cfg *config.Config, uc apikey.ApiKeyUseCase, luc logger.LogUseCase,
+	auc auth.AuthUseCase, suc sess.SessUseCase, uuc user.UserUseCase) {
+	h := NewApiKeyHandler(cfg, uc, luc, auc, suc, uuc)
+
+	apiKeyRoute := router.Group("/apikey")
+	{
+		apiKeyRoute.POST("", h.CreateApiKey)
+		apiKeyRoute.GET("/all", h.GetApiKeys)
+		apiKeyRoute.DELETE("/:id", h.DeleteApiKey)
+	}
+}
@@ -0,0 +1,223 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+	"vhosting/internal/apikey"
+	"vhosting/internal/group"
+	perm "vhosting/internal/permission"
+	"vhosting/pkg/config"
+	qconsts "vhosting/pkg/constants/query"
+	"vhosting/pkg/db_connect"
+	"vhosting/pkg/user"
+)
+
+// keyColumns select the keys with the usernames, the table is "k" and the
+// users are "u".
+var keyColumns = fmt.Sprintf("k.%s, k.%s, k.%s, u.%s, k.%s, k.%s, k.%s, k.%s, k.%s, k.%s, k.%s, k.%s",
+	apikey.Id, apikey.Name, apikey.UserId, user.Username, apikey.KeyPrefix, apikey.Scopes,
+	apikey.AllowedIPs, apikey.ExpirationDate, apikey.LastUsedDate, apikey.LastUsedIP,
+	apikey.CreatedBy, apikey.CreationDate)
+
+var keyTable = fmt.Sprintf("%s AS k JOIN %s AS u ON u.%s=k.%s", apikey.TableName, user.TableName,
+	user.Id, apikey.UserId)
+
+type ApiKeyRepository struct {
+	cfg *config.Config
+}
+
+func NewApiKeyRepository(cfg *config.Config) *ApiKeyRepository {
+	return &ApiKeyRepository{cfg: cfg}
+}
+
+func (r *ApiKeyRepository) CreateApiKey(key *apikey.ApiKey, keyHash string) (int, error) {
+	db := db_connect.CreateLocalDBConnection(r.cfg)
+	defer db_connect.CloseDBConnection(r.cfg, db)
+
+	template := qconsts.INSERT_INTO_TBL_VALUES_VAL + " RETURNING %s"
+	tbl := fmt.Sprintf("%s (%s, %s, %s, %s, %s, %s, %s, %s, %s)", apikey.TableName, apikey.Name,
+		apikey.UserId, apikey.KeyPrefix, apikey.KeyHash, apikey.Scopes, apikey.AllowedIPs,
+		apikey.ExpirationDate, apikey.CreatedBy, apikey.CreationDate)
+	val := "($1, $2, $3, $4, $5, $6, NULLIF($7, '')::TIMESTAMPTZ, $8, $9)"
+	query := fmt.Sprintf(template, tbl, val, apikey.Id)
+
+	var id int
+	if err := db.Get(&id, query, key.Name, key.UserId, key.KeyPrefix, keyHash, pq.Array(key.Scopes),
+		pq.Array(key.AllowedIPs), key.ExpirationDate, key.CreatedBy, key.CreationDate); err != nil {
+		return -1, err
+	}
+
+	return id, nil
+}
+
+func (r *ApiKeyRepository) GetApiKeys() ([]*apikey.ApiKey, error) {
+	db := db_connect.CreateLocalDBConnection(r.cfg)
+	defer db_connect.CloseDBConnection(r.cfg, db)
+
+	template := qconsts.SELECT_COL_FROM_TBL
+	tbl := fmt.Sprintf("%s ORDER BY k.%s", keyTable, apikey.Id)
+	query := fmt.Sprintf(template, keyColumns, tbl)
+
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []*apikey.ApiKey{}
+	for rows.Next() {
+		key, err := scanApiKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// GetApiKeyByHash returns the key with zero id when there is no such key.
+func (r *ApiKeyRepository) GetApiKeyByHash(keyHash string) (*apikey.ApiKey, error) {
+	db := db_connect.CreateLocalDBConnection(r.cfg)
+	defer db_connect.CloseDBConnection(r.cfg, db)
+
+	template := qconsts.SELECT_COL_FROM_TBL_WHERE_CND
+	cnd := fmt.Sprintf("k.%s=$1", apikey.KeyHash)
+	query := fmt.Sprintf(template, keyColumns, keyTable, cnd)
+
+	rows, err := db.Query(query, keyHash)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return &apikey.ApiKey{}, rows.Err()
+	}
+
+	return scanApiKey(rows)
+}
+
+func (r *ApiKeyRepository) IsApiKeyExists(id int) (bool, error) {
+	db := db_connect.CreateLocalDBConnection(r.cfg)
+	defer db_connect.CloseDBConnection(r.cfg, db)
+
+	template := qconsts.SELECT_COL_FROM_TBL_WHERE_CND
+	col := apikey.Id
+	tbl := apikey.TableName
+	cnd := fmt.Sprintf("%s=$1", apikey.Id)
+	query := fmt.Sprintf(template, col, tbl, cnd)
+
+	rows, err := db.Query(query, id)
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	isRowPresent := rows.Next()
+	if !isRowPresent {
+		return false, nil
+	}
+
+	return true, nil
+}
+
+func (r *ApiKeyRepository) UpdateApiKeyLastUsed(id int, timestamp, clientIP string) error {
+	db := db_connect.CreateLocalDBConnection(r.cfg)
+	defer db_connect.CloseDBConnection(r.cfg, db)
+
+	template := qconsts.UPDATE_TBL_SET_VAL_WHERE_CND
+	tbl := apikey.TableName
+	val := fmt.Sprintf("%s=$1, %s=$2", apikey.LastUsedDate, apikey.LastUsedIP)
+	cnd := fmt.Sprintf("%s=$3", apikey.Id)
+	query := fmt.Sprintf(template, tbl, val, cnd)
+
+	rows, err := db.Query(query, timestamp, clientIP, id)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	return nil
+}
+
+func (r *ApiKeyRepository) DeleteApiKey(id int) error {
+	db := db_connect.CreateLocalDBConnection(r.cfg)
+	defer db_connect.CloseDBConnection(r.cfg, db)
+
+	template := qconsts.DELETE_FROM_TBL_WHERE_CND
+	tbl := apikey.TableName
+	cnd := fmt.Sprintf("%s=$1", apikey.Id)
+	query := fmt.Sprintf(template, tbl, cnd)
+
+	rows, err := db.Query(query, id)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	return nil
+}
+
+// GetExistingScopes returns the scopes which are permission code names.
+func (r *ApiKeyRepository) GetExistingScopes(scopes []string) ([]string, error) {
+	db := db_connect.CreateLocalDBConnection(r.cfg)
+	defer db_connect.CloseDBConnection(r.cfg, db)
+
+	template := qconsts.SELECT_COL_FROM_TBL_WHERE_CND
+	col := perm.Codename
+	tbl := perm.TableName
+	cnd := fmt.Sprintf("%s = ANY($1)", perm.Codename)
+	query := fmt.Sprintf(template, col, tbl, cnd)
+
+	var existing []string
+	if err := db.Select(&existing, query, pq.Array(scopes)); err != nil {
+		return nil, err
+	}
+
+	return existing, nil
+}
+
+// GetHeldScopes returns the scopes which the user holds through personal or
+// group permissions, the superusers and staff hold all of them.
+func (r *ApiKeyRepository) GetHeldScopes(userId int, scopes []string) ([]string, error) {
+	db := db_connect.CreateLocalDBConnection(r.cfg)
+	defer db_connect.CloseDBConnection(r.cfg, db)
+
+	template := qconsts.SELECT_COL_FROM_TBL_WHERE_CND
+	col := fmt.Sprintf("p.%s", perm.Codename)
+	tbl := fmt.Sprintf("%s AS p", perm.TableName)
+	cnd := fmt.Sprintf("p.%s = ANY($2) AND (EXISTS (SELECT 1 FROM %s WHERE %s=$1 AND (%s OR %s)) OR "+
+		"p.%s IN (SELECT %s FROM %s WHERE %s=$1) OR "+
+		"p.%s IN (SELECT gp.%s FROM %s AS gp JOIN %s AS ug ON ug.%s=gp.%s WHERE ug.%s=$1))",
+		perm.Codename, user.TableName, user.Id, user.IsSuperuser, user.IsStaff,
+		perm.Id, perm.PermId, perm.UPTableName, perm.UserId,
+		perm.Id, perm.PermId, perm.GPTableName, group.UGTableName, group.GroupId, perm.GroupId, group.UserId)
+	query := fmt.Sprintf(template, col, tbl, cnd)
+
+	var held []string
+	if err := db.Select(&held, query, userId, pq.Array(scopes)); err != nil {
+		return nil, err
+	}
+
+	return held, nil
+}
+
+func scanApiKey(rows interface{ Scan(...interface{}) error }) (*apikey.ApiKey, error) {
+	var key apikey.ApiKey
+	var expirationDate, lastUsedDate sql.NullString
+	if err := rows.Scan(&key.Id, &key.Name, &key.UserId, &key.Username, &key.KeyPrefix,
+		pq.Array(&key.Scopes), pq.Array(&key.AllowedIPs), &expirationDate, &lastUsedDate,
+		&key.LastUsedIP, &key.CreatedBy, &key.CreationDate); err != nil {
+		return nil, err
+	}
+	key.ExpirationDate = expirationDate.String
+	key.LastUsedDate = lastUsedDate.String
+	return &key, nil
+}
@@ -0,0 +1,152 @@
+package usecase
+
+import (
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"vhosting/internal/apikey"
+	"vhosting/pkg/config"
+	"vhosting/pkg/hasher"
+	"vhosting/pkg/timedate"
+)
+
+// keyPrefixLen is the length of the key start shown in the lists, it is
+// enough to recognize the key and not enough to use it.
+const keyPrefixLen = 12
+
+type ApiKeyUseCase struct {
+	cfg        *config.Config
+	apiKeyRepo apikey.ApiKeyRepository
+}
+
+func NewApiKeyUseCase(cfg *config.Config, apiKeyRepo apikey.ApiKeyRepository) *ApiKeyUseCase {
+	return &ApiKeyUseCase{
+		cfg:        cfg,
+		apiKeyRepo: apiKeyRepo,
+	}
+}
+
+// CreateApiKey generates the key and stores its hash, the returned key is
+// the only place where the key itself is shown.
+func (u *ApiKeyUseCase) CreateApiKey(req *apikey.ApiKeyRequest, createdBy int) (*apikey.ApiKey, error) {
+	token, err := hasher.GenerateRandomToken()
+	if err != nil {
+		return nil, err
+	}
+	key := &apikey.ApiKey{
+		Name:           req.Name,
+		UserId:         req.UserId,
+		Scopes:         uniqueStrings(req.Scopes),
+		AllowedIPs:     uniqueStrings(req.AllowedIPs),
+		ExpirationDate: req.ExpirationDate,
+		CreatedBy:      createdBy,
+		CreationDate:   timedate.GetTimestamp(),
+		Key:            apikey.Prefix + token,
+	}
+	key.KeyPrefix = key.Key[:keyPrefixLen]
+
+	if key.Id, err = u.apiKeyRepo.CreateApiKey(key, hasher.HashToken(key.Key)); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func (u *ApiKeyUseCase) GetApiKeys() ([]*apikey.ApiKey, error) {
+	return u.apiKeyRepo.GetApiKeys()
+}
+
+func (u *ApiKeyUseCase) IsApiKeyExists(id int) (bool, error) {
+	return u.apiKeyRepo.IsApiKeyExists(id)
+}
+
+func (u *ApiKeyUseCase) DeleteApiKey(id int) error {
+	return u.apiKeyRepo.DeleteApiKey(id)
+}
+
+func (u *ApiKeyUseCase) IsApiKeyRequiredEmpty(name string) bool {
+	if name == "" {
+		return true
+	}
+	return false
+}
+
+// IsScopesValid checks that the scopes are not empty and all of them are
+// permission code names.
+func (u *ApiKeyUseCase) IsScopesValid(scopes []string) (bool, error) {
+	scopes = uniqueStrings(scopes)
+	if len(scopes) == 0 {
+		return false, nil
+	}
+	existing, err := u.apiKeyRepo.GetExistingScopes(scopes)
+	if err != nil {
+		return false, err
+	}
+	return len(existing) == len(scopes), nil
+}
+
+// IsScopesHeld checks that the user holds all the scopes through personal
+// or group permissions, the superusers and staff hold all of them.
+func (u *ApiKeyUseCase) IsScopesHeld(userId int, scopes []string) (bool, error) {
+	scopes = uniqueStrings(scopes)
+	held, err := u.apiKeyRepo.GetHeldScopes(userId, scopes)
+	if err != nil {
+		return false, err
+	}
+	return len(held) == len(scopes), nil
+}
+
+func (u *ApiKeyUseCase) IsAllowedIPsValid(allowedIPs []string) bool {
+	for _, allowed := range allowedIPs {
+		if net.ParseIP(allowed) != nil {
+			continue
+		}
+		if _, _, err := net.ParseCIDR(allowed); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// IsExpirationDateValid accepts an empty date for the keys which do not
+// expire.
+func (u *ApiKeyUseCase) IsExpirationDateValid(expirationDate string) bool {
+	if expirationDate == "" {
+		return true
+	}
+	date, err := time.Parse(time.RFC3339, expirationDate)
+	if err != nil {
+		return false
+	}
+	return date.After(time.Now())
+}
+
+func (u *ApiKeyUseCase) BindJSONApiKeyRequest(ctx *gin.Context) (*apikey.ApiKeyRequest, error) {
+	var req apikey.ApiKeyRequest
+	if err := ctx.BindJSON(&req); err != nil {
+		return &req, err
+	}
+	return &req, nil
+}
+
+func (u *ApiKeyUseCase) AtoiRequestedId(ctx *gin.Context) (int, error) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		return -1, err
+	}
+	return id, nil
+}
+
+func uniqueStrings(values []string) []string {
+	unique := []string{}
+	seen := map[string]bool{}
+	for _, value := range values {
+		if value == "" || seen[value] {
+			continue
+		}
+		seen[value] = true
+		unique = append(unique, value)
+	}
+	return unique
+}
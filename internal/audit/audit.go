@@ -0,0 +1,59 @@
+package audit
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	KindMedia  = "media"  // a file sent by a download link
+	KindBundle = "bundle" // a file of a ZIP bundle
+	KindExport = "export" // an exported clip
+	KindLive   = "live"   // a live WebRTC viewing session
+)
+
+// Access is a record of the footage sent to a user. Guests watching live
+// streams have UserId -1.
+type Access struct {
+	Id        int    `json:"id"        db:"id"`
+	Kind      string `json:"kind"      db:"kind"`
+	UserId    int    `json:"userId"    db:"user_id"`
+	Stream    string `json:"stream"    db:"stream"`
+	VideoId   int    `json:"videoId"   db:"video_id"`
+	ExportId  int    `json:"exportId"  db:"export_id"`
+	File      string `json:"file"      db:"file"` // path in the media directory
+	ClientIP  string `json:"clientIp"  db:"client_ip"`
+	BytesSent int64  `json:"bytesSent" db:"bytes_sent"`
+	StartDate string `json:"startDate" db:"start_date"`
+	EndDate   string `json:"endDate"   db:"end_date"`
+}
+
+// AccessFilter selects the records by the set fields. File is matched
+// together with VideoId, the media links carry the file path only.
+type AccessFilter struct {
+	UserId  int    // -1 for any
+	Stream  string // "" for any
+	VideoId int    // 0 for any
+	File    string
+	From    time.Time // zero for any
+	To      time.Time
+	Limit   int
+	Offset  int
+}
+
+type AuditCommon interface {
+	CreateAccess(acc *Access) error
+	GetAccesses(filter *AccessFilter) ([]*Access, error)
+}
+
+type AuditUseCase interface {
+	AuditCommon
+
+	RecordAccess(acc *Access)
+	ParseAccessFilter(ctx *gin.Context) (*AccessFilter, bool)
+}
+
+type AuditRepository interface {
+	AuditCommon
+}
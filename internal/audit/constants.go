@@ -0,0 +1,18 @@
+package audit
+
+const (
+	AccTableName = "access_audit"
+	AccId        = "id"
+	AccKind      = "kind"
+	AccUserId    = "user_id"
+	AccStream    = "stream"
+	AccVideoId   = "video_id"
+	AccExportId  = "export_id"
+	AccFile      = "file"
+	AccClientIP  = "client_ip"
+	AccBytesSent = "bytes_sent"
+	AccStartDate = "start_date"
+	AccEndDate   = "end_date"
+)
+
+const AccessDatetimeLayout = "2006-01-02 15:04:05"
@@ -0,0 +1,160 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"vhosting/internal/audit"
+	msg "vhosting/internal/messages"
+	sess "vhosting/internal/session"
+	"vhosting/internal/video"
+	"vhosting/pkg/auth"
+	"vhosting/pkg/config"
+	"vhosting/pkg/logger"
+	"vhosting/pkg/timedate"
+	"vhosting/pkg/user"
+)
+
+type AuditHandler struct {
+	cfg          *config.Config
+	useCase      audit.AuditUseCase
+	logUseCase   logger.LogUseCase
+	authUseCase  auth.AuthUseCase
+	sessUseCase  sess.SessUseCase
+	userUseCase  user.UserUseCase
+	videoUseCase video.VideoUseCase
+}
+
+func NewAuditHandler(cfg *config.Config, useCase audit.AuditUseCase,
+	logUseCase logger.LogUseCase, authUseCase auth.AuthUseCase,
+	sessUseCase sess.SessUseCase, userUseCase user.UserUseCase,
+	videoUseCase video.VideoUseCase) *AuditHandler {
+	return &AuditHandler{
+		cfg:          cfg,
+		useCase:      useCase,
+		logUseCase:   logUseCase,
+		authUseCase:  authUseCase,
+		sessUseCase:  sessUseCase,
+		userUseCase:  userUseCase,
+		videoUseCase: videoUseCase,
+	}
+}
+
+// GetAccesses lists who was sent which footage and when, the latest first.
+func (h *AuditHandler) GetAccesses(ctx *gin.Context) {
+	actPermission := "get_access_audit"
+
+	log := logger.Init(ctx)
+
+	hasPerms, _ := h.isPermsGranted_getUserId(ctx, log, actPermission)
+	if !hasPerms {
+		return
+	}
+
+	filter, ok := h.useCase.ParseAccessFilter(ctx)
+	if !ok {
+		h.logUseCase.Report(ctx, log, msg.ErrorAccessFilterIsInvalid())
+		return
+	}
+
+	// The video is matched by its file too, the download links carry it
+	if filter.VideoId > 0 {
+		exists, err := h.videoUseCase.IsVideoExists(filter.VideoId)
+		if err != nil {
+			h.logUseCase.Report(ctx, log, msg.ErrorCannotCheckVideoExistence(err))
+			return
+		}
+		if exists {
+			gottenVideo, err := h.videoUseCase.GetVideo(filter.VideoId)
+			if err != nil {
+				h.logUseCase.Report(ctx, log, msg.ErrorCannotGetVideo(err))
+				return
+			}
+			filter.File = gottenVideo.File
+		}
+	}
+
+	accesses, err := h.useCase.GetAccesses(filter)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotGetAccesses(err))
+		return
+	}
+
+	h.logUseCase.Report(ctx, log, msg.InfoGotAccesses(accesses))
+}
+
+func (h *AuditHandler) isPermsGranted_getUserId(ctx *gin.Context, log *logger.Log, permission string) (bool, int) {
+	headerToken := h.authUseCase.ReadHeader(ctx)
+	if !h.authUseCase.IsTokenExists(headerToken) {
+		h.logUseCase.Report(ctx, log, msg.ErrorYouHaveNotEnoughPermissions())
+		return false, -1
+	}
+
+	session, err := h.sessUseCase.GetSessionAndDate(ctx, headerToken)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotGetSessionAndDate(err))
+		return false, -1
+	}
+	if !h.authUseCase.IsSessionExists(session) {
+		h.logUseCase.Report(ctx, log, msg.ErrorYouHaveNotEnoughPermissions())
+		return false, -1
+	}
+
+	if timedate.IsDateExpired(session.RefreshDate, h.cfg.SessionTTLHours) {
+		if err := h.sessUseCase.DeleteSession(headerToken); err != nil {
+			h.logUseCase.Report(ctx, log, msg.ErrorCannotDeleteSession(err))
+			return false, -1
+		}
+		h.logUseCase.Report(ctx, log, msg.ErrorYouHaveNotEnoughPermissions())
+		return false, -1
+	}
+
+	gottenUserId, err := h.userUseCase.GetUserId(session.Username)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotCheckUserExistence(err))
+		return false, -1
+	}
+	if gottenUserId < 0 {
+		if err := h.sessUseCase.DeleteSession(headerToken); err != nil {
+			h.logUseCase.Report(ctx, log, msg.ErrorCannotDeleteSession(err))
+			return false, -1
+		}
+		h.logUseCase.Report(ctx, log, msg.ErrorUserWithThisUsernameIsNotExist())
+		return false, -1
+	}
+
+	log.SessionOwner = session.Username
+
+	// The API keys are granted the permissions of their scopes which their
+	// users still hold
+	if session.ApiKeyId != 0 {
+		granted, err := h.sessUseCase.IsApiKeyGranted(session, permission)
+		if err != nil {
+			h.logUseCase.Report(ctx, log, msg.ErrorCannotCheckApiKeyScopes(err))
+			return false, -1
+		}
+		if !granted {
+			h.logUseCase.Report(ctx, log, msg.ErrorYouHaveNotEnoughPermissions())
+			return false, -1
+		}
+		return true, gottenUserId
+	}
+
+	isSUorStaff := false
+	hasPersonalPerm := false
+	if isSUorStaff, err = h.userUseCase.IsUserSuperuserOrStaff(session.Username); err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotCheckSuperuserStaffPermissions(err))
+		return false, -1
+	}
+	if !isSUorStaff {
+		if hasPersonalPerm, err = h.userUseCase.IsUserHavePersonalPermission(gottenUserId, permission); err != nil {
+			h.logUseCase.Report(ctx, log, msg.ErrorCannotCheckPersonalPermission(err))
+			return false, -1
+		}
+	}
+
+	if !isSUorStaff && !hasPersonalPerm {
+		h.logUseCase.Report(ctx, log, msg.ErrorYouHaveNotEnoughPermissions())
+		return false, -1
+	}
+
+	return true, gottenUserId
+}
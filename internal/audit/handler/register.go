@@ -0,0 +1,22 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"vhosting/internal/audit"
+	sess "vhosting/internal/session"
+	"vhosting/internal/video"
+	"vhosting/pkg/auth"
+	"vhosting/pkg/config"
+	"vhosting/pkg/logger"
+	"vhosting/pkg/user"
+)
+
+func RegisterHTTPEndpoints(router *gin.Engine, cfg *config.Config, uc audit.AuditUseCase, luc logger.LogUseCase,
+	auc auth.AuthUseCase, suc sess.SessUseCase, uuc user.UserUseCase, vuc video.VideoUseCase) {
+	h := NewAuditHandler(cfg, uc, luc, auc, suc, uuc, vuc)
+
+	auditRoute := router.Group("/audit")
+	{
+		auditRoute.GET("/access", h.GetAccesses)
+	}
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"vhosting/internal/audit"
+	"vhosting/pkg/config"
+	qconsts "vhosting/pkg/constants/query"
+	"vhosting/pkg/db_connect"
+)
+
+var accColumns = fmt.Sprintf("%s, %s, %s, %s, %s, %s, %s, %s, %s, %s",
+	audit.AccKind, audit.AccUserId, audit.AccStream, audit.AccVideoId,
+	audit.AccExportId, audit.AccFile, audit.AccClientIP, audit.AccBytesSent,
+	audit.AccStartDate, audit.AccEndDate)
+
+type AuditRepository struct {
+	cfg *config.Config
+}
+
+func NewAuditRepository(cfg *config.Config) *AuditRepository {
+	return &AuditRepository{cfg: cfg}
+}
+
+func (r *AuditRepository) CreateAccess(acc *audit.Access) error {
+	db := db_connect.CreateLocalDBConnection(r.cfg)
+	defer db_connect.CloseDBConnection(r.cfg, db)
+
+	template := qconsts.INSERT_INTO_TBL_VALUES_VAL + " RETURNING %s"
+	tbl := fmt.Sprintf("%s (%s)", audit.AccTableName, accColumns)
+	val := "($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)"
+	query := fmt.Sprintf(template, tbl, val, audit.AccId)
+
+	return db.QueryRow(query, acc.Kind, acc.UserId, acc.Stream, acc.VideoId,
+		acc.ExportId, acc.File, acc.ClientIP, acc.BytesSent, acc.StartDate,
+		acc.EndDate).Scan(&acc.Id)
+}
+
+// GetAccesses returns the records overlapping the period, the latest first.
+func (r *AuditRepository) GetAccesses(filter *audit.AccessFilter) ([]*audit.Access, error) {
+	db := db_connect.CreateLocalDBConnection(r.cfg)
+	defer db_connect.CloseDBConnection(r.cfg, db)
+
+	cnds := []string{"TRUE"}
+	args := []interface{}{}
+	arg := func(val interface{}) string {
+		args = append(args, val)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	if filter.UserId >= 0 {
+		cnds = append(cnds, fmt.Sprintf("%s=%s", audit.AccUserId, arg(filter.UserId)))
+	}
+	if filter.Stream != "" {
+		cnds = append(cnds, fmt.Sprintf("%s=%s", audit.AccStream, arg(filter.Stream)))
+	}
+	if filter.VideoId > 0 {
+		cnds = append(cnds, fmt.Sprintf("(%s=%s OR %s=%s)", audit.AccVideoId,
+			arg(filter.VideoId), audit.AccFile, arg(filter.File)))
+	}
+	if !filter.From.IsZero() {
+		cnds = append(cnds, fmt.Sprintf("%s >= %s", audit.AccEndDate, arg(filter.From)))
+	}
+	if !filter.To.IsZero() {
+		cnds = append(cnds, fmt.Sprintf("%s <= %s", audit.AccStartDate, arg(filter.To)))
+	}
+
+	template := qconsts.SELECT_COL_FROM_TBL_WHERE_CND
+	col := audit.AccId + ", " + accColumns
+	tbl := audit.AccTableName
+	cnd := fmt.Sprintf("%s ORDER BY %s DESC, %s DESC LIMIT %s OFFSET %s",
+		strings.Join(cnds, " AND "), audit.AccStartDate, audit.AccId,
+		arg(filter.Limit), arg(filter.Offset))
+	query := fmt.Sprintf(template, col, tbl, cnd)
+
+	accesses := []*audit.Access{}
+	if err := db.Select(&accesses, query, args...); err != nil {
+		return nil, err
+	}
+
+	return accesses, nil
+}
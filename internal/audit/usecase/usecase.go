@@ -0,0 +1,109 @@
+package usecase
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"vhosting/internal/audit"
+	msg "vhosting/internal/messages"
+	"vhosting/pkg/config"
+	"vhosting/pkg/logger"
+	"vhosting/pkg/timedate"
+)
+
+type AuditUseCase struct {
+	cfg       *config.Config
+	auditRepo audit.AuditRepository
+}
+
+func NewAuditUseCase(cfg *config.Config, auditRepo audit.AuditRepository) *AuditUseCase {
+	return &AuditUseCase{
+		cfg:       cfg,
+		auditRepo: auditRepo,
+	}
+}
+
+func (u *AuditUseCase) CreateAccess(acc *audit.Access) error {
+	return u.auditRepo.CreateAccess(acc)
+}
+
+func (u *AuditUseCase) GetAccesses(filter *audit.AccessFilter) ([]*audit.Access, error) {
+	return u.auditRepo.GetAccesses(filter)
+}
+
+// RecordAccess ends the access now and writes it. A failed record is only
+// logged, the footage has been sent already.
+func (u *AuditUseCase) RecordAccess(acc *audit.Access) {
+	if acc.StartDate == "" {
+		acc.StartDate = timedate.GetTimestamp()
+	}
+	acc.EndDate = timedate.GetTimestamp()
+	if acc.BytesSent < 0 {
+		acc.BytesSent = 0
+	}
+	if err := u.auditRepo.CreateAccess(acc); err != nil {
+		logger.Print(msg.ErrorCannotCreateAccessRecord(err))
+	}
+}
+
+// ParseAccessFilter reads "user", "stream", "video", "from", "to", "_limit"
+// and "_page" of the URL. The time is "2006-01-02 15:04:05" in local time
+// or RFC3339.
+func (u *AuditUseCase) ParseAccessFilter(ctx *gin.Context) (*audit.AccessFilter, bool) {
+	urlparams := ctx.Request.URL.Query()
+	filter := &audit.AccessFilter{UserId: -1, Stream: urlparams.Get("stream")}
+
+	var err error
+	if val := urlparams.Get("user"); val != "" {
+		if filter.UserId, err = strconv.Atoi(val); err != nil || filter.UserId < -1 {
+			return nil, false
+		}
+	}
+	if val := urlparams.Get("video"); val != "" {
+		if filter.VideoId, err = strconv.Atoi(val); err != nil || filter.VideoId <= 0 {
+			return nil, false
+		}
+	}
+	if val := urlparams.Get("from"); val != "" {
+		var ok bool
+		if filter.From, ok = parseAccessDatetime(val); !ok {
+			return nil, false
+		}
+	}
+	if val := urlparams.Get("to"); val != "" {
+		var ok bool
+		if filter.To, ok = parseAccessDatetime(val); !ok {
+			return nil, false
+		}
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && filter.To.Before(filter.From) {
+		return nil, false
+	}
+
+	page := 1
+	filter.Limit = u.cfg.PaginationGetLimitDefault
+	if val := urlparams.Get("_limit"); val != "" {
+		if filter.Limit, err = strconv.Atoi(val); err != nil || filter.Limit <= 0 {
+			return nil, false
+		}
+	}
+	if val := urlparams.Get("_page"); val != "" {
+		if page, err = strconv.Atoi(val); err != nil || page <= 0 {
+			return nil, false
+		}
+	}
+	filter.Offset = (page - 1) * filter.Limit
+
+	return filter, true
+}
+
+func parseAccessDatetime(value string) (time.Time, bool) {
+	if t, err := time.ParseInLocation(audit.AccessDatetimeLayout, value, time.Local); err == nil {
+		return t, true
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, true
+	}
+	return time.Time{}, false
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"vhosting/internal/info"
 	msg "vhosting/internal/messages"
+	"vhosting/internal/retention"
 	sess "vhosting/internal/session"
 	"vhosting/pkg/auth"
 	"vhosting/pkg/config"
@@ -21,19 +22,23 @@ type InfoHandler struct {
 	authUseCase auth.AuthUseCase
 	sessUseCase sess.SessUseCase
 	userUseCase user.UserUseCase
+
+	retentionUseCase retention.RetentionCommon
 }
 
 func NewInfoHandler(cfg *config.Config, scfg *sconfig.Config, useCase info.InfoUseCase,
 	logUseCase logger.LogUseCase, authUseCase auth.AuthUseCase,
-	sessUseCase sess.SessUseCase, userUseCase user.UserUseCase) *InfoHandler {
+	sessUseCase sess.SessUseCase, userUseCase user.UserUseCase,
+	retentionUseCase retention.RetentionCommon) *InfoHandler {
 	return &InfoHandler{
-		cfg:         cfg,
-		scfg:        scfg,
-		useCase:     useCase,
-		logUseCase:  logUseCase,
-		authUseCase: authUseCase,
-		sessUseCase: sessUseCase,
-		userUseCase: userUseCase,
+		cfg:              cfg,
+		scfg:             scfg,
+		useCase:          useCase,
+		logUseCase:       logUseCase,
+		authUseCase:      authUseCase,
+		sessUseCase:      sessUseCase,
+		userUseCase:      userUseCase,
+		retentionUseCase: retentionUseCase,
 	}
 }
 
@@ -205,6 +210,17 @@ func (h *InfoHandler) DeleteInfo(ctx *gin.Context) {
 		return
 	}
 
+	// A held info is kept until the hold is released
+	held, err := h.retentionUseCase.IsHoldExists(reqId)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotCheckLegalHoldExistence(err))
+		return
+	}
+	if held {
+		h.logUseCase.Report(ctx, log, msg.ErrorInfoIsUnderLegalHold())
+		return
+	}
+
 	if err := h.useCase.DeleteInfo(reqId); err != nil {
 		h.logUseCase.Report(ctx, log, msg.ErrorCannotDeleteInfo(err))
 		return
@@ -220,7 +236,7 @@ func (h *InfoHandler) isPermsGranted_getUserId(ctx *gin.Context, log *logger.Log
 		return false, -1
 	}
 
-	session, err := h.sessUseCase.GetSessionAndDate(headerToken)
+	session, err := h.sessUseCase.GetSessionAndDate(ctx, headerToken)
 	if err != nil {
 		h.logUseCase.Report(ctx, log, msg.ErrorCannotGetSessionAndDate(err))
 		return false, -1
@@ -230,7 +246,7 @@ func (h *InfoHandler) isPermsGranted_getUserId(ctx *gin.Context, log *logger.Log
 		return false, -1
 	}
 
-	if timedate.IsDateExpired(session.CreationDate, h.cfg.SessionTTLHours) {
+	if timedate.IsDateExpired(session.RefreshDate, h.cfg.SessionTTLHours) {
 		if err := h.sessUseCase.DeleteSession(headerToken); err != nil {
 			h.logUseCase.Report(ctx, log, msg.ErrorCannotDeleteSession(err))
 			return false, -1
@@ -239,13 +255,7 @@ func (h *InfoHandler) isPermsGranted_getUserId(ctx *gin.Context, log *logger.Log
 		return false, -1
 	}
 
-	headerNamepass, err := h.authUseCase.ParseToken(headerToken)
-	if err != nil {
-		h.logUseCase.Report(ctx, log, msg.ErrorCannotParseToken(err))
-		return false, -1
-	}
-
-	gottenUserId, err := h.userUseCase.GetUserId(headerNamepass.Username)
+	gottenUserId, err := h.userUseCase.GetUserId(session.Username)
 	if err != nil {
 		h.logUseCase.Report(ctx, log, msg.ErrorCannotCheckUserExistence(err))
 		return false, -1
@@ -259,11 +269,26 @@ func (h *InfoHandler) isPermsGranted_getUserId(ctx *gin.Context, log *logger.Log
 		return false, -1
 	}
 
-	log.SessionOwner = headerNamepass.Username
+	log.SessionOwner = session.Username
+
+	// The API keys are granted the permissions of their scopes which their
+	// users still hold
+	if session.ApiKeyId != 0 {
+		granted, err := h.sessUseCase.IsApiKeyGranted(session, permission)
+		if err != nil {
+			h.logUseCase.Report(ctx, log, msg.ErrorCannotCheckApiKeyScopes(err))
+			return false, -1
+		}
+		if !granted {
+			h.logUseCase.Report(ctx, log, msg.ErrorYouHaveNotEnoughPermissions())
+			return false, -1
+		}
+		return true, gottenUserId
+	}
 
 	isSUorStaff := false
 	hasPersonalPerm := false
-	if isSUorStaff, err = h.userUseCase.IsUserSuperuserOrStaff(headerNamepass.Username); err != nil {
+	if isSUorStaff, err = h.userUseCase.IsUserSuperuserOrStaff(session.Username); err != nil {
 		h.logUseCase.Report(ctx, log, msg.ErrorCannotCheckSuperuserStaffPermissions(err))
 		return false, -1
 	}
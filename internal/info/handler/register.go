@@ -2,6 +2,7 @@ package handler
 
 import (
 	"vhosting/internal/info"
+	"vhosting/internal/retention"
 	sess "vhosting/internal/session"
 	"vhosting/pkg/auth"
 	"vhosting/pkg/config"
@@ -13,8 +14,8 @@ import (
 )
 
 func RegisterHTTPEndpoints(router *gin.Engine, cfg *config.Config, scfg *sconfig.Config, uc info.InfoUseCase, luc logger.LogUseCase,
-	auc auth.AuthUseCase, suc sess.SessUseCase, uuc user.UserUseCase) {
-	h := NewInfoHandler(cfg, scfg, uc, luc, auc, suc, uuc)
+	auc auth.AuthUseCase, suc sess.SessUseCase, uuc user.UserUseCase, ruc retention.RetentionCommon) {
+	h := NewInfoHandler(cfg, scfg, uc, luc, auc, suc, uuc, ruc)
 
 	infoRoute := router.Group("/info")
 	{
@@ -1,11 +1,13 @@
 package messages
 
 import (
+	"fmt"
+
 	"vhosting/pkg/logger"
 )
 
 func WarningCannotConvertCvar(cvarName string, setValue interface{}) *logger.Log {
-	return &logger.Log{ErrCode: 10, Message: "Cannot convert cvar " + cvarName + ". Set default value: " + setValue.(string), ErrLevel: logger.ErrLevelWarning}
+	return &logger.Log{ErrCode: 10, Message: "Cannot convert cvar " + cvarName + ". Set default value: " + fmt.Sprint(setValue), ErrLevel: logger.ErrLevelWarning}
 }
 
 func FatalFailedToLoadConfigFile(err error) *logger.Log {
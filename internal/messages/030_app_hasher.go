@@ -7,3 +7,7 @@ import (
 func ErrorCannotWriteBytesIntoInternalVariable(err error) *logger.Log {
 	return &logger.Log{ErrCode: 30, Message: "Cannot write bytes into internal variable. Error: " + err.Error(), ErrLevel: logger.ErrLevelError}
 }
+
+func ErrorCannotGeneratePasswordSalt(err error) *logger.Log {
+	return &logger.Log{ErrCode: 31, Message: "Cannot generate password salt. Error: " + err.Error(), ErrLevel: logger.ErrLevelError}
+}
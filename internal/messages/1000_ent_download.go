@@ -1,14 +1,96 @@
 package messages
 
 import (
+	"strconv"
+
 	"vhosting/pkg/download"
 	"vhosting/pkg/logger"
 )
 
 func ErrorExtensionIsNotMp4() *logger.Log {
-	return &logger.Log{StatusCode: 405, ErrCode: 1000, Message: "Extension is not .mp4", ErrLevel: logger.ErrLevelError}
+	return &logger.Log{StatusCode: 400, ErrCode: 1000, Message: "Extension is not .mp4", ErrLevel: logger.ErrLevelError}
 }
 
 func InfoPutDownloadLink(dload *download.Download) *logger.Log {
 	return &logger.Log{StatusCode: 200, Message: dload}
 }
+
+func ErrorFilePathIsInvalid() *logger.Log {
+	return &logger.Log{StatusCode: 400, ErrCode: 1001, Message: "File path is invalid", ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorFileIsNotExist() *logger.Log {
+	return &logger.Log{StatusCode: 404, ErrCode: 1002, Message: "File is not exist", ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorCannotOpenFile(err error) *logger.Log {
+	return &logger.Log{StatusCode: 500, ErrCode: 1003, Message: "Cannot open file. Error: " + err.Error(), ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorDownloadLinkIsInvalid() *logger.Log {
+	return &logger.Log{StatusCode: 403, ErrCode: 1004, Message: "Download link is invalid", ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorDownloadLinkIsExpired() *logger.Log {
+	return &logger.Log{StatusCode: 403, ErrCode: 1005, Message: "Download link is expired", ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorDownloadLinkIsRevoked() *logger.Log {
+	return &logger.Log{StatusCode: 403, ErrCode: 1006, Message: "Download link is revoked", ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorCannotCheckDownloadLink(err error) *logger.Log {
+	return &logger.Log{StatusCode: 500, ErrCode: 1007, Message: "Cannot check download link. Error: " + err.Error(), ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorCannotRevokeDownloadLinks(err error) *logger.Log {
+	return &logger.Log{StatusCode: 500, ErrCode: 1008, Message: "Cannot revoke download links. Error: " + err.Error(), ErrLevel: logger.ErrLevelError}
+}
+
+func InfoDownloadLinksRevoked() *logger.Log {
+	return &logger.Log{StatusCode: 200, Message: "Download links revoked"}
+}
+
+func ErrorBundleItemsCannotBeEmpty() *logger.Log {
+	return &logger.Log{StatusCode: 400, ErrCode: 1009, Message: "Bundle video IDs and export IDs cannot be empty", ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorBundleHasTooManyItems(maxItems int) *logger.Log {
+	return &logger.Log{StatusCode: 400, ErrCode: 1010, Message: "Bundle has too many items. Maximum: " + strconv.Itoa(maxItems), ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorBundleVideoIsNotExist(id int) *logger.Log {
+	return &logger.Log{StatusCode: 400, ErrCode: 1011, Message: "Bundle video is not exist. ID: " + strconv.Itoa(id), ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorBundleExportIsNotExist(id int) *logger.Log {
+	return &logger.Log{StatusCode: 400, ErrCode: 1012, Message: "Bundle export is not exist. ID: " + strconv.Itoa(id), ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorBundleExportIsNotReady(id int, state string) *logger.Log {
+	return &logger.Log{StatusCode: 409, ErrCode: 1013, Message: "Bundle export is not ready. ID: " + strconv.Itoa(id) + ", state: " + state, ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorBundleFileIsNotExist(name string) *logger.Log {
+	return &logger.Log{StatusCode: 404, ErrCode: 1014, Message: "Bundle file is not exist. Name: " + name, ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorCannotWriteBundle(err error) *logger.Log {
+	return &logger.Log{StatusCode: 500, ErrCode: 1015, Message: "Cannot write bundle. Error: " + err.Error(), ErrLevel: logger.ErrLevelError}
+}
+
+func InfoBundleSent(count int) *logger.Log {
+	return &logger.Log{StatusCode: 200, Message: "Bundle sent. Files: " + strconv.Itoa(count)}
+}
+
+func ErrorDownloadQueueIsFull(limit int) *logger.Log {
+	return &logger.Log{StatusCode: 429, ErrCode: 1016, Message: "Download queue is full. Concurrent downloads limit: " + strconv.Itoa(limit), ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorDownloadQueueTimeout(limit int) *logger.Log {
+	return &logger.Log{StatusCode: 429, ErrCode: 1017, Message: "Download queue wait timed out. Concurrent downloads limit: " + strconv.Itoa(limit), ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorCannotAcquireDownloadSlot(err error) *logger.Log {
+	return &logger.Log{StatusCode: 500, ErrCode: 1018, Message: "Cannot acquire download slot. Error: " + err.Error(), ErrLevel: logger.ErrLevelError}
+}
@@ -0,0 +1,60 @@
+package messages
+
+import (
+	"fmt"
+	"strconv"
+
+	"vhosting/pkg/logger"
+)
+
+func ErrorCannotCreateArchiveDir(err error) *logger.Log {
+	return &logger.Log{ErrCode: 1100, Message: "Cannot create archive directory. Error: " + err.Error(), ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorCannotGetRequestedArchives(err error) *logger.Log {
+	return &logger.Log{ErrCode: 1101, Message: "Cannot get requested archives. Error: " + err.Error(), ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorCannotGetArchiveJob(err error) *logger.Log {
+	return &logger.Log{ErrCode: 1102, Message: "Cannot get archive job. Error: " + err.Error(), ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorCannotSetArchiveJob(err error) *logger.Log {
+	return &logger.Log{ErrCode: 1103, Message: "Cannot set archive job. Error: " + err.Error(), ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorCannotSetArchiveRecordStatus(err error) *logger.Log {
+	return &logger.Log{ErrCode: 1104, Message: "Cannot set archive record status. Error: " + err.Error(), ErrLevel: logger.ErrLevelError}
+}
+
+func WarningArchiveJobAttemptFailed(requestId, attempt int, err error) *logger.Log {
+	return &logger.Log{ErrCode: 1105, Message: "Archive job attempt failed, job is queued again. Request ID: " + strconv.Itoa(requestId) + ", attempt: " + strconv.Itoa(attempt) + ". Error: " + err.Error(), ErrLevel: logger.ErrLevelWarning}
+}
+
+func ErrorArchiveJobFailed(requestId int, err error) *logger.Log {
+	return &logger.Log{ErrCode: 1106, Message: "Archive job failed. Request ID: " + strconv.Itoa(requestId) + ". Error: " + err.Error(), ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorArchiveWorkerPanic(recovered interface{}) *logger.Log {
+	return &logger.Log{ErrCode: 1107, Message: "Archive worker recovered from panic: " + fmt.Sprint(recovered), ErrLevel: logger.ErrLevelError}
+}
+
+func InfoArchiveWorkerStarted(workers, pollIntervalSeconds int) *logger.Log {
+	return &logger.Log{Message: "Archive worker started. Workers: " + strconv.Itoa(workers) + ", poll interval: " + strconv.Itoa(pollIntervalSeconds) + " seconds"}
+}
+
+func InfoArchiveWorkerStopped() *logger.Log {
+	return &logger.Log{Message: "Archive worker stopped"}
+}
+
+func InfoArchiveJobQueued(requestId int) *logger.Log {
+	return &logger.Log{Message: "Archive job queued. Request ID: " + strconv.Itoa(requestId)}
+}
+
+func InfoArchiveJobStarted(requestId, attempt int) *logger.Log {
+	return &logger.Log{Message: "Archive job started. Request ID: " + strconv.Itoa(requestId) + ", attempt: " + strconv.Itoa(attempt)}
+}
+
+func InfoArchiveJobDone(requestId int, outputPath string) *logger.Log {
+	return &logger.Log{Message: "Archive job done. Request ID: " + strconv.Itoa(requestId) + ", output: " + outputPath}
+}
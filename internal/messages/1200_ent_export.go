@@ -0,0 +1,89 @@
+package messages
+
+import (
+	"fmt"
+	"strconv"
+
+	"vhosting/pkg/export"
+	"vhosting/pkg/logger"
+)
+
+func ErrorCannotCreateExportDir(err error) *logger.Log {
+	return &logger.Log{ErrCode: 1200, Message: "Cannot create export directory. Error: " + err.Error(), ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorCannotGetExport(err error) *logger.Log {
+	return &logger.Log{StatusCode: 500, ErrCode: 1201, Message: "Cannot get export. Error: " + err.Error(), ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorCannotUpdateExport(err error) *logger.Log {
+	return &logger.Log{ErrCode: 1202, Message: "Cannot update export. Error: " + err.Error(), ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorExportWorkerPanic(recovered interface{}) *logger.Log {
+	return &logger.Log{ErrCode: 1203, Message: "Export worker recovered from panic: " + fmt.Sprint(recovered), ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorExportFailed(id int, err error) *logger.Log {
+	return &logger.Log{ErrCode: 1204, Message: "Export failed. Export ID: " + strconv.Itoa(id) + ". Error: " + err.Error(), ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorExportPeriodIsInvalid() *logger.Log {
+	return &logger.Log{StatusCode: 400, ErrCode: 1205, Message: "Export period is invalid. Expected \"from\" before \"to\" in format \"2006-01-02 15:04:05\" or RFC3339", ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorExportPeriodIsTooLong(maxMinutes int) *logger.Log {
+	return &logger.Log{StatusCode: 400, ErrCode: 1206, Message: "Export period is too long. Max duration: " + strconv.Itoa(maxMinutes) + " minutes", ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorCannotCreateExport(err error) *logger.Log {
+	return &logger.Log{StatusCode: 500, ErrCode: 1207, Message: "Cannot create export. Error: " + err.Error(), ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorExportQueueIsFull() *logger.Log {
+	return &logger.Log{StatusCode: 503, ErrCode: 1208, Message: "Export queue is full, try again later", ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorCannotCheckExportExistence(err error) *logger.Log {
+	return &logger.Log{StatusCode: 500, ErrCode: 1209, Message: "Cannot check export existence. Error: " + err.Error(), ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorExportWithRequestedIDIsNotExist() *logger.Log {
+	return &logger.Log{StatusCode: 400, ErrCode: 1210, Message: "Export with requested ID is not exist", ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorExportFileIsNotReady(state string) *logger.Log {
+	return &logger.Log{StatusCode: 409, ErrCode: 1211, Message: "Export file is not ready. State: " + state, ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorExportFileIsNotExist() *logger.Log {
+	return &logger.Log{StatusCode: 404, ErrCode: 1212, Message: "Export file is not exist", ErrLevel: logger.ErrLevelError}
+}
+
+func InfoExportCreated(exp *export.Export) *logger.Log {
+	return &logger.Log{StatusCode: 200, Message: exp}
+}
+
+func InfoGotExport(exp *export.Export) *logger.Log {
+	return &logger.Log{StatusCode: 200, Message: exp}
+}
+
+func InfoExportWorkersStarted(workers int) *logger.Log {
+	return &logger.Log{Message: "Export workers started. Workers: " + strconv.Itoa(workers)}
+}
+
+func InfoExportStarted(id int) *logger.Log {
+	return &logger.Log{Message: "Export started. Export ID: " + strconv.Itoa(id)}
+}
+
+func InfoExportDone(id int, outputPath string) *logger.Log {
+	return &logger.Log{Message: "Export done. Export ID: " + strconv.Itoa(id) + ", output: " + outputPath}
+}
+
+func ErrorExportOverlayIsInvalid(err error) *logger.Log {
+	return &logger.Log{StatusCode: 400, ErrCode: 1213, Message: "Export overlay is invalid. Error: " + err.Error(), ErrLevel: logger.ErrLevelError}
+}
+
+func WarningCannotProbeExport(id int, err error) *logger.Log {
+	return &logger.Log{ErrCode: 1214, Message: "Cannot probe export file. Export ID: " + strconv.Itoa(id) + ". Error: " + err.Error(), ErrLevel: logger.ErrLevelWarning}
+}
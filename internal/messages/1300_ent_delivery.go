@@ -0,0 +1,36 @@
+package messages
+
+import (
+	"fmt"
+	"strconv"
+
+	"vhosting/pkg/logger"
+)
+
+func ErrorDeliveryTargetIsInvalid(name string, err error) *logger.Log {
+	return &logger.Log{ErrCode: 1300, Message: "Delivery target is invalid and skipped. Target: " + name + ". Error: " + err.Error(), ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorCannotGetDeliveries(err error) *logger.Log {
+	return &logger.Log{StatusCode: 500, ErrCode: 1301, Message: "Cannot get deliveries. Error: " + err.Error(), ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorCannotSetDelivery(err error) *logger.Log {
+	return &logger.Log{ErrCode: 1302, Message: "Cannot set delivery. Error: " + err.Error(), ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorDeliveryWorkerPanic(recovered interface{}) *logger.Log {
+	return &logger.Log{ErrCode: 1303, Message: "Delivery worker recovered from panic: " + fmt.Sprint(recovered), ErrLevel: logger.ErrLevelError}
+}
+
+func WarningDeliveryAttemptFailed(source string, sourceId int, target string, attempt int, err error) *logger.Log {
+	return &logger.Log{ErrCode: 1304, Message: "Delivery attempt failed, delivery is retried. Source: " + source + " " + strconv.Itoa(sourceId) + ", target: " + target + ", attempt: " + strconv.Itoa(attempt) + ". Error: " + err.Error(), ErrLevel: logger.ErrLevelWarning}
+}
+
+func ErrorDeliveryFailed(source string, sourceId int, target string, err error) *logger.Log {
+	return &logger.Log{ErrCode: 1305, Message: "Delivery failed. Source: " + source + " " + strconv.Itoa(sourceId) + ", target: " + target + ". Error: " + err.Error(), ErrLevel: logger.ErrLevelError}
+}
+
+func InfoDeliveryDone(source string, sourceId int, target string) *logger.Log {
+	return &logger.Log{Message: "Delivery done. Source: " + source + " " + strconv.Itoa(sourceId) + ", target: " + target}
+}
@@ -0,0 +1,69 @@
+package messages
+
+import (
+	"fmt"
+	"strconv"
+
+	"vhosting/internal/retention"
+	"vhosting/pkg/logger"
+)
+
+func ErrorCannotEnforceRetention(err error) *logger.Log {
+	return &logger.Log{StatusCode: 500, ErrCode: 1400, Message: "Cannot enforce retention. Error: " + err.Error(), ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorRetentionPanic(recovered interface{}) *logger.Log {
+	return &logger.Log{ErrCode: 1401, Message: "Retention recovered from panic: " + fmt.Sprint(recovered), ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorCannotDeleteExpiredVideo(infoId, videoId int, err error) *logger.Log {
+	return &logger.Log{ErrCode: 1402, Message: "Cannot delete expired video. Info ID: " + strconv.Itoa(infoId) + ", video ID: " + strconv.Itoa(videoId) + ". Error: " + err.Error(), ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorCannotSetLegalHold(err error) *logger.Log {
+	return &logger.Log{StatusCode: 500, ErrCode: 1403, Message: "Cannot set legal hold. Error: " + err.Error(), ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorCannotCheckLegalHoldExistence(err error) *logger.Log {
+	return &logger.Log{StatusCode: 500, ErrCode: 1404, Message: "Cannot check legal hold existence. Error: " + err.Error(), ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorLegalHoldWithRequestedInfoIDIsNotExist() *logger.Log {
+	return &logger.Log{StatusCode: 400, ErrCode: 1405, Message: "Legal hold with requested info ID is not exist", ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorCannotDeleteLegalHold(err error) *logger.Log {
+	return &logger.Log{StatusCode: 500, ErrCode: 1406, Message: "Cannot delete legal hold. Error: " + err.Error(), ErrLevel: logger.ErrLevelError}
+}
+
+func InfoExpiredVideoDeleted(infoId, videoId int, file string) *logger.Log {
+	return &logger.Log{Message: "Expired video deleted. Info ID: " + strconv.Itoa(infoId) + ", video ID: " + strconv.Itoa(videoId) + ", file: " + file}
+}
+
+func InfoGotRetentionReport(report *retention.Report) *logger.Log {
+	return &logger.Log{StatusCode: 200, Message: report}
+}
+
+func InfoLegalHoldSet() *logger.Log {
+	return &logger.Log{StatusCode: 200, Message: "Legal hold set"}
+}
+
+func InfoLegalHoldDeleted() *logger.Log {
+	return &logger.Log{StatusCode: 200, Message: "Legal hold deleted"}
+}
+
+func InfoRetentionDisabled() *logger.Log {
+	return &logger.Log{Message: "Retention is disabled"}
+}
+
+func InfoRetentionStarted(checkPeriodMinutes int, dryRun bool) *logger.Log {
+	return &logger.Log{Message: "Retention started. Check period: " + strconv.Itoa(checkPeriodMinutes) + " minutes, dry run: " + strconv.FormatBool(dryRun)}
+}
+
+func InfoRetentionReport(report *retention.Report) *logger.Log {
+	return &logger.Log{Message: "Retention check done. Dry run: " + strconv.FormatBool(report.DryRun) + ", expired videos: " + strconv.Itoa(report.Videos) + ", deleted: " + strconv.Itoa(report.Deleted) + ", held: " + strconv.Itoa(report.Held) + ", failed: " + strconv.Itoa(report.Failed)}
+}
+
+func ErrorInfoIsUnderLegalHold() *logger.Log {
+	return &logger.Log{StatusCode: 400, ErrCode: 1407, Message: "Info is under legal hold, it and its videos cannot be deleted", ErrLevel: logger.ErrLevelError}
+}
@@ -0,0 +1,22 @@
+package messages
+
+import (
+	"vhosting/internal/audit"
+	"vhosting/pkg/logger"
+)
+
+func ErrorAccessFilterIsInvalid() *logger.Log {
+	return &logger.Log{StatusCode: 400, ErrCode: 1500, Message: "Access filter is invalid", ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorCannotGetAccesses(err error) *logger.Log {
+	return &logger.Log{StatusCode: 500, ErrCode: 1501, Message: "Cannot get accesses. Error: " + err.Error(), ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorCannotCreateAccessRecord(err error) *logger.Log {
+	return &logger.Log{ErrCode: 1502, Message: "Cannot create access record. Error: " + err.Error(), ErrLevel: logger.ErrLevelError}
+}
+
+func InfoGotAccesses(accesses []*audit.Access) *logger.Log {
+	return &logger.Log{StatusCode: 200, Message: accesses}
+}
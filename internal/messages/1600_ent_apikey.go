@@ -0,0 +1,74 @@
+package messages
+
+import (
+	"vhosting/internal/apikey"
+	"vhosting/pkg/logger"
+)
+
+func ErrorApiKeyNameCannotBeEmpty() *logger.Log {
+	return &logger.Log{StatusCode: 400, ErrCode: 1600, Message: "API key name cannot be empty", ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorApiKeyScopesAreInvalid() *logger.Log {
+	return &logger.Log{StatusCode: 400, ErrCode: 1601, Message: "API key scopes must be existing permission code names", ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorApiKeyAllowedIPsAreInvalid() *logger.Log {
+	return &logger.Log{StatusCode: 400, ErrCode: 1602, Message: "API key allowed IPs must be addresses or CIDR ranges", ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorApiKeyExpirationDateIsInvalid() *logger.Log {
+	return &logger.Log{StatusCode: 400, ErrCode: 1603, Message: "API key expiration date must be RFC 3339 and in the future", ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorCannotCheckApiKeyScopes(err error) *logger.Log {
+	return &logger.Log{StatusCode: 500, ErrCode: 1604, Message: "Cannot check API key scopes. Error: " + err.Error(), ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorCannotCreateApiKey(err error) *logger.Log {
+	return &logger.Log{StatusCode: 500, ErrCode: 1605, Message: "Cannot create API key. Error: " + err.Error(), ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorCannotGetApiKeys(err error) *logger.Log {
+	return &logger.Log{StatusCode: 500, ErrCode: 1606, Message: "Cannot get API keys. Error: " + err.Error(), ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorApiKeyWithRequestedIDIsNotExist() *logger.Log {
+	return &logger.Log{StatusCode: 400, ErrCode: 1607, Message: "API key with requested ID is not exist", ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorCannotDeleteApiKey(err error) *logger.Log {
+	return &logger.Log{StatusCode: 500, ErrCode: 1608, Message: "Cannot delete API key. Error: " + err.Error(), ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorCannotCheckApiKeyExistence(err error) *logger.Log {
+	return &logger.Log{StatusCode: 500, ErrCode: 1609, Message: "Cannot check API key existence. Error: " + err.Error(), ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorApiKeysCannotManageApiKeys() *logger.Log {
+	return &logger.Log{StatusCode: 403, ErrCode: 1610, Message: "API keys cannot manage API keys", ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorCannotGetApiKey(err error) *logger.Log {
+	return &logger.Log{ErrCode: 1611, Message: "Cannot get API key. Error: " + err.Error(), ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorApiKeyScopesAreNotHeldByUser() *logger.Log {
+	return &logger.Log{StatusCode: 400, ErrCode: 1612, Message: "API key scopes must be permissions the user holds", ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorOnlyStaffCanCreateApiKeys() *logger.Log {
+	return &logger.Log{StatusCode: 403, ErrCode: 1613, Message: "Only superusers and staff can create API keys", ErrLevel: logger.ErrLevelError}
+}
+
+func InfoApiKeyCreated(key *apikey.ApiKey) *logger.Log {
+	return &logger.Log{StatusCode: 201, Message: key}
+}
+
+func InfoGotApiKeys(keys []*apikey.ApiKey) *logger.Log {
+	return &logger.Log{StatusCode: 200, Message: keys}
+}
+
+func InfoApiKeyDeleted() *logger.Log {
+	return &logger.Log{StatusCode: 200, Message: "API key deleted"}
+}
@@ -1,6 +1,7 @@
 package messages
 
 import (
+	sess "vhosting/internal/session"
 	"vhosting/pkg/logger"
 )
 
@@ -15,3 +16,59 @@ func ErrorCannotCreateSession(err error) *logger.Log {
 func ErrorCannotGetSessionAndDate(err error) *logger.Log {
 	return &logger.Log{StatusCode: 500, ErrCode: 302, Message: "Cannot get session and date. Error: " + err.Error()}
 }
+
+func ErrorCannotGetSessions(err error) *logger.Log {
+	return &logger.Log{StatusCode: 500, ErrCode: 303, Message: "Cannot get sessions. Error: " + err.Error(), ErrLevel: logger.ErrLevelError}
+}
+
+func InfoGotSessions(sessions []*sess.Session) *logger.Log {
+	return &logger.Log{StatusCode: 200, Message: sessions}
+}
+
+func ErrorSessionWithRequestedIDIsNotExist() *logger.Log {
+	return &logger.Log{StatusCode: 400, ErrCode: 304, Message: "Session with requested ID is not exist", ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorYouMustBeSignedInForManagingSessions() *logger.Log {
+	return &logger.Log{StatusCode: 401, ErrCode: 305, Message: "You must be signed-in for managing sessions", ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorCannotRevokeSessions(err error) *logger.Log {
+	return &logger.Log{StatusCode: 500, ErrCode: 306, Message: "Cannot revoke sessions. Error: " + err.Error(), ErrLevel: logger.ErrLevelError}
+}
+
+func InfoSessionRevoked() *logger.Log {
+	return &logger.Log{StatusCode: 200, Message: "Session revoked"}
+}
+
+func InfoYouHaveSuccessfullySignedOutEverywhere() *logger.Log {
+	return &logger.Log{StatusCode: 202, Message: "You have successfully signed-out from all sessions"}
+}
+
+func InfoUserSessionsRevoked() *logger.Log {
+	return &logger.Log{StatusCode: 200, Message: "User sessions revoked"}
+}
+
+func ErrorRefreshTokenCannotBeEmpty() *logger.Log {
+	return &logger.Log{StatusCode: 400, ErrCode: 307, Message: "Refresh token cannot be empty", ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorRefreshTokenIsInvalid() *logger.Log {
+	return &logger.Log{StatusCode: 401, ErrCode: 308, Message: "Refresh token is invalid", ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorRefreshTokenIsReused() *logger.Log {
+	return &logger.Log{StatusCode: 401, ErrCode: 309, Message: "Refresh token is already used, the session is revoked", ErrLevel: logger.ErrLevelWarning}
+}
+
+func ErrorRefreshTokenIsExpired() *logger.Log {
+	return &logger.Log{StatusCode: 401, ErrCode: 310, Message: "Refresh token is expired", ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorCannotRefreshSession(err error) *logger.Log {
+	return &logger.Log{StatusCode: 500, ErrCode: 311, Message: "Cannot refresh session. Error: " + err.Error(), ErrLevel: logger.ErrLevelError}
+}
+
+func InfoSessionRefreshed() *logger.Log {
+	return &logger.Log{StatusCode: 202, Message: "Session refreshed"}
+}
@@ -1,6 +1,9 @@
 package messages
 
 import (
+	"fmt"
+	"strconv"
+
 	"vhosting/internal/video"
 	"vhosting/pkg/logger"
 )
@@ -60,3 +63,131 @@ func ErrorCannotDeleteVideo(err error) *logger.Log {
 func InfoVideoDeleted() *logger.Log {
 	return &logger.Log{StatusCode: 200, Message: "Video deleted"}
 }
+
+func ErrorCannotProbeVideo(err error) *logger.Log {
+	return &logger.Log{StatusCode: 500, ErrCode: 808, Message: "Cannot probe video. Error: " + err.Error(), ErrLevel: logger.ErrLevelError}
+}
+
+func InfoVideoProbed(meta *video.Metadata) *logger.Log {
+	return &logger.Log{StatusCode: 200, Message: meta}
+}
+
+func ErrorVideoPreviewIsNotReady(state string) *logger.Log {
+	return &logger.Log{StatusCode: 409, ErrCode: 809, Message: "Video preview is not ready. State: " + state, ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorVideoPreviewFileIsNotExist() *logger.Log {
+	return &logger.Log{StatusCode: 404, ErrCode: 810, Message: "Video preview file is not exist", ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorCannotGetVideoPreview(err error) *logger.Log {
+	return &logger.Log{StatusCode: 500, ErrCode: 811, Message: "Cannot get video preview. Error: " + err.Error(), ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorCannotCreateVideoPreviewDir(err error) *logger.Log {
+	return &logger.Log{ErrCode: 812, Message: "Cannot create video preview directory. Error: " + err.Error(), ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorCannotGetVideoPreviews(err error) *logger.Log {
+	return &logger.Log{ErrCode: 813, Message: "Cannot get video previews. Error: " + err.Error(), ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorCannotSetVideoPreview(err error) *logger.Log {
+	return &logger.Log{ErrCode: 814, Message: "Cannot set video preview. Error: " + err.Error(), ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorVideoPreviewFailed(id int, err error) *logger.Log {
+	return &logger.Log{ErrCode: 815, Message: "Video preview failed. Video ID: " + strconv.Itoa(id) + ". Error: " + err.Error(), ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorVideoPreviewWorkerPanic(recovered interface{}) *logger.Log {
+	return &logger.Log{ErrCode: 816, Message: "Video preview worker recovered from panic: " + fmt.Sprint(recovered), ErrLevel: logger.ErrLevelError}
+}
+
+func InfoVideoPreviewWorkersStarted(workers int) *logger.Log {
+	return &logger.Log{Message: "Video preview workers started. Workers: " + strconv.Itoa(workers)}
+}
+
+func InfoVideoPreviewDone(id int) *logger.Log {
+	return &logger.Log{Message: "Video preview done. Video ID: " + strconv.Itoa(id)}
+}
+
+func ErrorTusVersionIsNotSupported() *logger.Log {
+	return &logger.Log{StatusCode: 412, ErrCode: 817, Message: "Tus version is not supported. Supported: 1.0.0", ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorUploadLengthIsInvalid() *logger.Log {
+	return &logger.Log{StatusCode: 400, ErrCode: 818, Message: "Upload length is invalid", ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorUploadIsTooLarge(maxSizeMB int) *logger.Log {
+	return &logger.Log{StatusCode: 413, ErrCode: 819, Message: "Upload is too large. Maximum size, MB: " + strconv.Itoa(maxSizeMB), ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorCannotParseUploadMetadata(err error) *logger.Log {
+	return &logger.Log{StatusCode: 400, ErrCode: 820, Message: "Cannot parse upload metadata. Error: " + err.Error(), ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorUploadInfoIdIsInvalid() *logger.Log {
+	return &logger.Log{StatusCode: 400, ErrCode: 821, Message: "Upload metadata info_id is invalid", ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorCannotCreateUpload(err error) *logger.Log {
+	return &logger.Log{StatusCode: 500, ErrCode: 822, Message: "Cannot create upload. Error: " + err.Error(), ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorCannotGetUpload(err error) *logger.Log {
+	return &logger.Log{StatusCode: 500, ErrCode: 823, Message: "Cannot get upload. Error: " + err.Error(), ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorUploadIsNotExist() *logger.Log {
+	return &logger.Log{StatusCode: 404, ErrCode: 824, Message: "Upload is not exist", ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorUploadContentTypeIsInvalid() *logger.Log {
+	return &logger.Log{StatusCode: 415, ErrCode: 825, Message: "Upload content type is invalid. Expected: application/offset+octet-stream", ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorUploadOffsetIsInvalid() *logger.Log {
+	return &logger.Log{StatusCode: 400, ErrCode: 826, Message: "Upload offset is invalid", ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorUploadOffsetMismatch(offset int64) *logger.Log {
+	return &logger.Log{StatusCode: 409, ErrCode: 827, Message: "Upload offset does not match. Offset: " + strconv.FormatInt(offset, 10), ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorUploadIsLocked() *logger.Log {
+	return &logger.Log{StatusCode: 423, ErrCode: 828, Message: "Upload is being written by another request", ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorCannotWriteUpload(err error) *logger.Log {
+	return &logger.Log{StatusCode: 500, ErrCode: 829, Message: "Cannot write upload. Error: " + err.Error(), ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorCannotDeleteUpload(err error) *logger.Log {
+	return &logger.Log{StatusCode: 500, ErrCode: 830, Message: "Cannot delete upload. Error: " + err.Error(), ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorCannotDeleteExpiredUploads(err error) *logger.Log {
+	return &logger.Log{ErrCode: 831, Message: "Cannot delete expired uploads. Error: " + err.Error(), ErrLevel: logger.ErrLevelError}
+}
+
+func InfoUploadCreated(upl *video.Upload) *logger.Log {
+	return &logger.Log{StatusCode: 201, Message: upl}
+}
+
+func InfoGotUpload(upl *video.Upload) *logger.Log {
+	return &logger.Log{StatusCode: 200, Message: upl}
+}
+
+func InfoUploadWritten(offset int64) *logger.Log {
+	return &logger.Log{StatusCode: 204, Message: "Upload written. Offset: " + strconv.FormatInt(offset, 10)}
+}
+
+func InfoUploadDeleted() *logger.Log {
+	return &logger.Log{StatusCode: 204, Message: "Upload deleted"}
+}
+
+func InfoExpiredUploadsDeleted(count int) *logger.Log {
+	return &logger.Log{Message: "Expired uploads deleted. Count: " + strconv.Itoa(count)}
+}
@@ -1,6 +1,8 @@
 package messages
 
 import (
+	"strconv"
+
 	"github.com/deepch/vdk/av"
 	"vhosting/pkg/logger"
 	"vhosting/pkg/stream"
@@ -105,3 +107,187 @@ func InfoGotAllStreams(streams map[int]*stream.Stream) *logger.Log {
 func ErrorCannotGetAllWorkingStreams(err error) *logger.Log {
 	return &logger.Log{StatusCode: 500, ErrCode: 934, Message: "Cannot get all working streams. Error: " + err.Error(), ErrLevel: logger.ErrLevelError}
 }
+
+func InfoStreamEventsSubscribed() *logger.Log {
+	return &logger.Log{StatusCode: 200, Message: "Subscribed to stream events"}
+}
+
+func InfoStreamEventsUnsubscribed() *logger.Log {
+	return &logger.Log{StatusCode: 200, Message: "Unsubscribed from stream events"}
+}
+
+func ErrorCannotSetCamera(err error) *logger.Log {
+	return &logger.Log{StatusCode: 500, ErrCode: 935, Message: "Cannot set camera. Error: " + err.Error(), ErrLevel: logger.ErrLevelError}
+}
+
+func InfoCameraSet() *logger.Log {
+	return &logger.Log{StatusCode: 200, Message: "Camera set"}
+}
+
+func ErrorCannotCheckCameraExistence(err error) *logger.Log {
+	return &logger.Log{StatusCode: 500, ErrCode: 936, Message: "Cannot check camera existence. Error: " + err.Error(), ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorCameraWithRequestedStreamIDIsNotExist() *logger.Log {
+	return &logger.Log{StatusCode: 400, ErrCode: 937, Message: "Camera with requested stream ID is not exist", ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorCannotGetCamera(err error) *logger.Log {
+	return &logger.Log{StatusCode: 500, ErrCode: 938, Message: "Cannot get camera. Error: " + err.Error(), ErrLevel: logger.ErrLevelError}
+}
+
+func InfoGotCamera(cam *stream.Camera) *logger.Log {
+	return &logger.Log{StatusCode: 200, Message: cam}
+}
+
+func ErrorCannotDeleteCamera(err error) *logger.Log {
+	return &logger.Log{StatusCode: 500, ErrCode: 939, Message: "Cannot delete camera. Error: " + err.Error(), ErrLevel: logger.ErrLevelError}
+}
+
+func InfoCameraDeleted() *logger.Log {
+	return &logger.Log{StatusCode: 200, Message: "Camera deleted"}
+}
+
+func ErrorCannotGetCamerasGeoJSON(err error) *logger.Log {
+	return &logger.Log{StatusCode: 500, ErrCode: 940, Message: "Cannot get cameras GeoJSON. Error: " + err.Error(), ErrLevel: logger.ErrLevelError}
+}
+
+func InfoGotCamerasGeoJSON(collection *stream.GeoJSONFeatureCollection) *logger.Log {
+	return &logger.Log{StatusCode: 200, Message: collection}
+}
+
+func ErrorViewersPerStreamLimitReached(limit int) *logger.Log {
+	return &logger.Log{StatusCode: 429, ErrCode: 941, Message: "Viewers per stream limit reached. Limit: " + strconv.Itoa(limit), ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorStreamsPerUserLimitReached(limit int) *logger.Log {
+	return &logger.Log{StatusCode: 429, ErrCode: 942, Message: "Concurrent streams per user limit reached. Limit: " + strconv.Itoa(limit), ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorStreamsPerGroupLimitReached(limit int) *logger.Log {
+	return &logger.Log{StatusCode: 429, ErrCode: 943, Message: "Concurrent streams per group limit reached. Limit: " + strconv.Itoa(limit), ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorOutboundBitrateBudgetExceeded(budgetKbps int) *logger.Log {
+	return &logger.Log{StatusCode: 503, ErrCode: 944, Message: "Outbound bitrate budget exceeded. Budget: " + strconv.Itoa(budgetKbps) + " kbps", ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorCannotAcquireViewerSlot(err error) *logger.Log {
+	return &logger.Log{StatusCode: 500, ErrCode: 945, Message: "Cannot acquire viewer slot. Error: " + err.Error(), ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorCannotGetViewerGroups(err error) *logger.Log {
+	return &logger.Log{StatusCode: 500, ErrCode: 946, Message: "Cannot get viewer groups. Error: " + err.Error(), ErrLevel: logger.ErrLevelError}
+}
+
+func InfoGotStreamUsage(usage *stream.Usage) *logger.Log {
+	return &logger.Log{StatusCode: 200, Message: usage}
+}
+
+func ErrorCannotSetStreamSource(err error) *logger.Log {
+	return &logger.Log{StatusCode: 500, ErrCode: 947, Message: "Cannot set stream source. Error: " + err.Error(), ErrLevel: logger.ErrLevelError}
+}
+
+func InfoStreamSourceSet() *logger.Log {
+	return &logger.Log{StatusCode: 200, Message: "Stream source set"}
+}
+
+func ErrorStreamSourceURLIsInvalid() *logger.Log {
+	return &logger.Log{StatusCode: 400, ErrCode: 948, Message: "Stream source URL is invalid. Expected rtsp:// or rtsps:// URL with host", ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorStreamSourceTransportIsInvalid() *logger.Log {
+	return &logger.Log{StatusCode: 400, ErrCode: 949, Message: "Stream source transport is invalid. Expected tcp, udp is not supported", ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorCannotCheckStreamSourceExistence(err error) *logger.Log {
+	return &logger.Log{StatusCode: 500, ErrCode: 950, Message: "Cannot check stream source existence. Error: " + err.Error(), ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorStreamSourceWithRequestedStreamIDIsNotExist() *logger.Log {
+	return &logger.Log{StatusCode: 400, ErrCode: 951, Message: "Stream source with requested stream ID is not exist", ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorCannotGetStreamSource(err error) *logger.Log {
+	return &logger.Log{StatusCode: 500, ErrCode: 952, Message: "Cannot get stream source. Error: " + err.Error(), ErrLevel: logger.ErrLevelError}
+}
+
+func InfoGotStreamSource(src *stream.SourceView) *logger.Log {
+	return &logger.Log{StatusCode: 200, Message: src}
+}
+
+func ErrorCannotDeleteStreamSource(err error) *logger.Log {
+	return &logger.Log{StatusCode: 500, ErrCode: 953, Message: "Cannot delete stream source. Error: " + err.Error(), ErrLevel: logger.ErrLevelError}
+}
+
+func InfoStreamSourceDeleted() *logger.Log {
+	return &logger.Log{StatusCode: 200, Message: "Stream source deleted"}
+}
+
+func ErrorCannotGetStreamSources(err error) *logger.Log {
+	return &logger.Log{ErrCode: 954, Message: "Cannot get stream sources. Error: " + err.Error(), ErrLevel: logger.ErrLevelError}
+}
+
+func WarningStreamUDPTransportIsNotSupported(name string) *logger.Log {
+	return &logger.Log{ErrCode: 955, Message: "UDP transport is not supported by RTSP client, TCP is used. Stream: " + name, ErrLevel: logger.ErrLevelWarning}
+}
+
+func ErrorCannotLoadStreamConfig(err error) *logger.Log {
+	return &logger.Log{ErrCode: 956, Message: "Cannot load stream config file. Error: " + err.Error(), ErrLevel: logger.ErrLevelError}
+}
+
+func InfoStreamConfigLoaded(path string) *logger.Log {
+	return &logger.Log{Message: "Stream config file loaded. Path: " + path}
+}
+
+func InfoStreamConfigFileNotFound(path string) *logger.Log {
+	return &logger.Log{Message: "Stream config file not found, streams are taken from database only. Path: " + path}
+}
+
+func InfoStreamAdded(name string) *logger.Log {
+	return &logger.Log{Message: "Stream added. Stream: " + name}
+}
+
+func InfoStreamChanged(name string) *logger.Log {
+	return &logger.Log{Message: "Stream settings changed, worker restarted. Stream: " + name}
+}
+
+func InfoStreamRemoved(name string) *logger.Log {
+	return &logger.Log{Message: "Stream removed. Stream: " + name}
+}
+
+func InfoStreamWorkerStopped(name string) *logger.Log {
+	return &logger.Log{Message: "Stream worker stopped. Stream: " + name}
+}
+
+func ErrorTimelinePeriodIsInvalid() *logger.Log {
+	return &logger.Log{StatusCode: 400, ErrCode: 957, Message: "Timeline period is invalid, \"from\" and \"to\" must be \"2006-01-02 15:04:05\" or RFC3339 and \"from\" must be before \"to\"", ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorTimelinePeriodIsTooLong(maxDays int) *logger.Log {
+	return &logger.Log{StatusCode: 400, ErrCode: 958, Message: "Timeline period is too long. Max days: " + strconv.Itoa(maxDays), ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorCannotGetStreamTimeline(err error) *logger.Log {
+	return &logger.Log{StatusCode: 500, ErrCode: 959, Message: "Cannot get stream timeline. Error: " + err.Error(), ErrLevel: logger.ErrLevelError}
+}
+
+func InfoGotStreamTimeline(timeline *stream.Timeline) *logger.Log {
+	return &logger.Log{StatusCode: 200, Message: timeline}
+}
+
+func ErrorEventsTicketIsInvalid() *logger.Log {
+	return &logger.Log{StatusCode: 403, ErrCode: 960, Message: "Events ticket is invalid, used or expired", ErrLevel: logger.ErrLevelError}
+}
+
+func ErrorCannotCreateEventsTicket(err error) *logger.Log {
+	return &logger.Log{StatusCode: 500, ErrCode: 961, Message: "Cannot create events ticket. Error: " + err.Error(), ErrLevel: logger.ErrLevelError}
+}
+
+func InfoEventsTicketCreated(ticket *stream.EventsTicket) *logger.Log {
+	return &logger.Log{StatusCode: 201, Message: ticket}
+}
+
+func ErrorCannotDecryptStreamSource(name string, err error) *logger.Log {
+	return &logger.Log{ErrCode: 962, Message: "Cannot decrypt stream source, default source is used. Stream: " + name + ". Error: " + err.Error(), ErrLevel: logger.ErrLevelError}
+}
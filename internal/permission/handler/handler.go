@@ -71,7 +71,7 @@ func (h *PermHandler) isPermsGranted_getUserId(ctx *gin.Context, log *logger.Log
 		return false, -1
 	}
 
-	session, err := h.sessUseCase.GetSessionAndDate(headerToken)
+	session, err := h.sessUseCase.GetSessionAndDate(ctx, headerToken)
 	if err != nil {
 		h.logUseCase.Report(ctx, log, msg.ErrorCannotGetSessionAndDate(err))
 		return false, -1
@@ -81,7 +81,7 @@ func (h *PermHandler) isPermsGranted_getUserId(ctx *gin.Context, log *logger.Log
 		return false, -1
 	}
 
-	if timedate.IsDateExpired(session.CreationDate, h.cfg.SessionTTLHours) {
+	if timedate.IsDateExpired(session.RefreshDate, h.cfg.SessionTTLHours) {
 		if err := h.sessUseCase.DeleteSession(headerToken); err != nil {
 			h.logUseCase.Report(ctx, log, msg.ErrorCannotDeleteSession(err))
 			return false, -1
@@ -90,13 +90,7 @@ func (h *PermHandler) isPermsGranted_getUserId(ctx *gin.Context, log *logger.Log
 		return false, -1
 	}
 
-	headerNamepass, err := h.authUseCase.ParseToken(headerToken)
-	if err != nil {
-		h.logUseCase.Report(ctx, log, msg.ErrorCannotParseToken(err))
-		return false, -1
-	}
-
-	gottenUserId, err := h.userUseCase.GetUserId(headerNamepass.Username)
+	gottenUserId, err := h.userUseCase.GetUserId(session.Username)
 	if err != nil {
 		h.logUseCase.Report(ctx, log, msg.ErrorCannotCheckUserExistence(err))
 		return false, -1
@@ -110,11 +104,26 @@ func (h *PermHandler) isPermsGranted_getUserId(ctx *gin.Context, log *logger.Log
 		return false, -1
 	}
 
-	log.SessionOwner = headerNamepass.Username
+	log.SessionOwner = session.Username
+
+	// The API keys are granted the permissions of their scopes which their
+	// users still hold
+	if session.ApiKeyId != 0 {
+		granted, err := h.sessUseCase.IsApiKeyGranted(session, permission)
+		if err != nil {
+			h.logUseCase.Report(ctx, log, msg.ErrorCannotCheckApiKeyScopes(err))
+			return false, -1
+		}
+		if !granted {
+			h.logUseCase.Report(ctx, log, msg.ErrorYouHaveNotEnoughPermissions())
+			return false, -1
+		}
+		return true, gottenUserId
+	}
 
 	isSUorStaff := false
 	hasPersonalPerm := false
-	if isSUorStaff, err = h.userUseCase.IsUserSuperuserOrStaff(headerNamepass.Username); err != nil {
+	if isSUorStaff, err = h.userUseCase.IsUserSuperuserOrStaff(session.Username); err != nil {
 		h.logUseCase.Report(ctx, log, msg.ErrorCannotCheckSuperuserStaffPermissions(err))
 		return false, -1
 	}
@@ -0,0 +1,9 @@
+package retention
+
+const (
+	HoldTableName    = "legal_holds"
+	HoldInfoId       = "info_id"
+	HoldReason       = "reason"
+	HoldUserId       = "user_id"
+	HoldCreationDate = "creation_date"
+)
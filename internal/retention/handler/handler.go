@@ -0,0 +1,213 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"vhosting/internal/info"
+	msg "vhosting/internal/messages"
+	"vhosting/internal/retention"
+	sess "vhosting/internal/session"
+	"vhosting/pkg/auth"
+	"vhosting/pkg/config"
+	"vhosting/pkg/logger"
+	"vhosting/pkg/timedate"
+	"vhosting/pkg/user"
+)
+
+type RetentionHandler struct {
+	cfg         *config.Config
+	useCase     retention.RetentionUseCase
+	logUseCase  logger.LogUseCase
+	authUseCase auth.AuthUseCase
+	sessUseCase sess.SessUseCase
+	userUseCase user.UserUseCase
+	infoUseCase info.InfoUseCase
+}
+
+func NewRetentionHandler(cfg *config.Config, useCase retention.RetentionUseCase,
+	logUseCase logger.LogUseCase, authUseCase auth.AuthUseCase,
+	sessUseCase sess.SessUseCase, userUseCase user.UserUseCase,
+	infoUseCase info.InfoUseCase) *RetentionHandler {
+	return &RetentionHandler{
+		cfg:         cfg,
+		useCase:     useCase,
+		logUseCase:  logUseCase,
+		authUseCase: authUseCase,
+		sessUseCase: sessUseCase,
+		userUseCase: userUseCase,
+		infoUseCase: infoUseCase,
+	}
+}
+
+// GetRetentionReport lists the videos which the retention would delete now.
+func (h *RetentionHandler) GetRetentionReport(ctx *gin.Context) {
+	actPermission := "get_retention_report"
+
+	log := logger.Init(ctx)
+
+	hasPerms, _ := h.isPermsGranted_getUserId(ctx, log, actPermission)
+	if !hasPerms {
+		return
+	}
+
+	report, err := h.useCase.Enforce(true)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotEnforceRetention(err))
+		return
+	}
+
+	h.logUseCase.Report(ctx, log, msg.InfoGotRetentionReport(report))
+}
+
+func (h *RetentionHandler) SetHold(ctx *gin.Context) {
+	actPermission := "set_legal_hold"
+
+	log := logger.Init(ctx)
+
+	hasPerms, userId := h.isPermsGranted_getUserId(ctx, log, actPermission)
+	if !hasPerms {
+		return
+	}
+
+	reqId, err := h.useCase.AtoiRequestedId(ctx)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotConvertRequestedIDToTypeInt(err))
+		return
+	}
+
+	exists, err := h.infoUseCase.IsInfoExists(reqId)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotCheckInfoExistence(err))
+		return
+	}
+	if !exists {
+		h.logUseCase.Report(ctx, log, msg.ErrorInfoWithRequestedIDIsNotExist())
+		return
+	}
+
+	hold, err := h.useCase.BindJSONHold(ctx)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotBindInputData(err))
+		return
+	}
+	hold.InfoId = reqId
+	hold.UserId = userId
+
+	if err := h.useCase.SetHold(hold); err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotSetLegalHold(err))
+		return
+	}
+
+	h.logUseCase.Report(ctx, log, msg.InfoLegalHoldSet())
+}
+
+func (h *RetentionHandler) DeleteHold(ctx *gin.Context) {
+	actPermission := "delete_legal_hold"
+
+	log := logger.Init(ctx)
+
+	hasPerms, _ := h.isPermsGranted_getUserId(ctx, log, actPermission)
+	if !hasPerms {
+		return
+	}
+
+	reqId, err := h.useCase.AtoiRequestedId(ctx)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotConvertRequestedIDToTypeInt(err))
+		return
+	}
+
+	exists, err := h.useCase.IsHoldExists(reqId)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotCheckLegalHoldExistence(err))
+		return
+	}
+	if !exists {
+		h.logUseCase.Report(ctx, log, msg.ErrorLegalHoldWithRequestedInfoIDIsNotExist())
+		return
+	}
+
+	if err := h.useCase.DeleteHold(reqId); err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotDeleteLegalHold(err))
+		return
+	}
+
+	h.logUseCase.Report(ctx, log, msg.InfoLegalHoldDeleted())
+}
+
+func (h *RetentionHandler) isPermsGranted_getUserId(ctx *gin.Context, log *logger.Log, permission string) (bool, int) {
+	headerToken := h.authUseCase.ReadHeader(ctx)
+	if !h.authUseCase.IsTokenExists(headerToken) {
+		h.logUseCase.Report(ctx, log, msg.ErrorYouHaveNotEnoughPermissions())
+		return false, -1
+	}
+
+	session, err := h.sessUseCase.GetSessionAndDate(ctx, headerToken)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotGetSessionAndDate(err))
+		return false, -1
+	}
+	if !h.authUseCase.IsSessionExists(session) {
+		h.logUseCase.Report(ctx, log, msg.ErrorYouHaveNotEnoughPermissions())
+		return false, -1
+	}
+
+	if timedate.IsDateExpired(session.RefreshDate, h.cfg.SessionTTLHours) {
+		if err := h.sessUseCase.DeleteSession(headerToken); err != nil {
+			h.logUseCase.Report(ctx, log, msg.ErrorCannotDeleteSession(err))
+			return false, -1
+		}
+		h.logUseCase.Report(ctx, log, msg.ErrorYouHaveNotEnoughPermissions())
+		return false, -1
+	}
+
+	gottenUserId, err := h.userUseCase.GetUserId(session.Username)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotCheckUserExistence(err))
+		return false, -1
+	}
+	if gottenUserId < 0 {
+		if err := h.sessUseCase.DeleteSession(headerToken); err != nil {
+			h.logUseCase.Report(ctx, log, msg.ErrorCannotDeleteSession(err))
+			return false, -1
+		}
+		h.logUseCase.Report(ctx, log, msg.ErrorUserWithThisUsernameIsNotExist())
+		return false, -1
+	}
+
+	log.SessionOwner = session.Username
+
+	// The API keys are granted the permissions of their scopes which their
+	// users still hold
+	if session.ApiKeyId != 0 {
+		granted, err := h.sessUseCase.IsApiKeyGranted(session, permission)
+		if err != nil {
+			h.logUseCase.Report(ctx, log, msg.ErrorCannotCheckApiKeyScopes(err))
+			return false, -1
+		}
+		if !granted {
+			h.logUseCase.Report(ctx, log, msg.ErrorYouHaveNotEnoughPermissions())
+			return false, -1
+		}
+		return true, gottenUserId
+	}
+
+	isSUorStaff := false
+	hasPersonalPerm := false
+	if isSUorStaff, err = h.userUseCase.IsUserSuperuserOrStaff(session.Username); err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotCheckSuperuserStaffPermissions(err))
+		return false, -1
+	}
+	if !isSUorStaff {
+		if hasPersonalPerm, err = h.userUseCase.IsUserHavePersonalPermission(gottenUserId, permission); err != nil {
+			h.logUseCase.Report(ctx, log, msg.ErrorCannotCheckPersonalPermission(err))
+			return false, -1
+		}
+	}
+
+	if !isSUorStaff && !hasPersonalPerm {
+		h.logUseCase.Report(ctx, log, msg.ErrorYouHaveNotEnoughPermissions())
+		return false, -1
+	}
+
+	return true, gottenUserId
+}
@@ -0,0 +1,24 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"vhosting/internal/info"
+	"vhosting/internal/retention"
+	sess "vhosting/internal/session"
+	"vhosting/pkg/auth"
+	"vhosting/pkg/config"
+	"vhosting/pkg/logger"
+	"vhosting/pkg/user"
+)
+
+func RegisterHTTPEndpoints(router *gin.Engine, cfg *config.Config, uc retention.RetentionUseCase, luc logger.LogUseCase,
+	auc auth.AuthUseCase, suc sess.SessUseCase, uuc user.UserUseCase, iuc info.InfoUseCase) {
+	h := NewRetentionHandler(cfg, uc, luc, auc, suc, uuc, iuc)
+
+	retentionRoute := router.Group("/retention")
+	{
+		retentionRoute.GET("/report", h.GetRetentionReport)
+		retentionRoute.POST("/hold/:id", h.SetHold)
+		retentionRoute.DELETE("/hold/:id", h.DeleteHold)
+	}
+}
@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"fmt"
+
+	"vhosting/internal/constants"
+	"vhosting/internal/info"
+	"vhosting/internal/retention"
+	"vhosting/internal/video"
+	"vhosting/pkg/config"
+	qconsts "vhosting/pkg/constants/query"
+	"vhosting/pkg/db_connect"
+	"vhosting/pkg/timedate"
+)
+
+type RetentionRepository struct {
+	cfg *config.Config
+}
+
+func NewRetentionRepository(cfg *config.Config) *RetentionRepository {
+	return &RetentionRepository{cfg: cfg}
+}
+
+func (r *RetentionRepository) GetExpiredInfos() ([]*retention.ExpiredInfo, error) {
+	r.cfg.DBOName = constants.DBO_WWW_Name
+	dbo := db_connect.CreateOuterDBConnection(r.cfg)
+	defer db_connect.CloseDBConnection(r.cfg, dbo)
+
+	template := qconsts.SELECT_COL_FROM_TBL_WHERE_CND
+	col := fmt.Sprintf("%s, %s, %s", info.Id, info.Stream, info.TimeLife)
+	tbl := info.TableName
+	cnd := fmt.Sprintf("%s < NOW() ORDER BY %s", info.TimeLife, info.Id)
+	query := fmt.Sprintf(template, col, tbl, cnd)
+
+	infos := []*retention.ExpiredInfo{}
+	if err := dbo.Select(&infos, query); err != nil {
+		return nil, err
+	}
+
+	return infos, nil
+}
+
+func (r *RetentionRepository) GetVideosByInfoId(infoId int) ([]*retention.Video, error) {
+	r.cfg.DBOName = constants.DBO_WWW_Name
+	dbo := db_connect.CreateOuterDBConnection(r.cfg)
+	defer db_connect.CloseDBConnection(r.cfg, dbo)
+
+	template := qconsts.SELECT_COL_FROM_TBL_WHERE_CND
+	col := fmt.Sprintf("%s, %s, %s", video.Id, video.File, video.InfoId)
+	tbl := video.TableName
+	cnd := fmt.Sprintf("%s=$1 ORDER BY %s", video.InfoId, video.Id)
+	query := fmt.Sprintf(template, col, tbl, cnd)
+
+	videos := []*retention.Video{}
+	if err := dbo.Select(&videos, query, infoId); err != nil {
+		return nil, err
+	}
+
+	return videos, nil
+}
+
+func (r *RetentionRepository) DeleteVideo(id int) error {
+	r.cfg.DBOName = constants.DBO_WWW_Name
+	dbo := db_connect.CreateOuterDBConnection(r.cfg)
+	defer db_connect.CloseDBConnection(r.cfg, dbo)
+
+	template := qconsts.DELETE_FROM_TBL_WHERE_CND
+	tbl := video.TableName
+	cnd := fmt.Sprintf("%s=$1", video.Id)
+	query := fmt.Sprintf(template, tbl, cnd)
+
+	rows, err := dbo.Query(query, id)
+	if err != nil {
+		return err
+	}
+	rows.Close()
+
+	// The probed metadata and the preview state are kept in the local database
+	db := db_connect.CreateLocalDBConnection(r.cfg)
+	defer db_connect.CloseDBConnection(r.cfg, db)
+
+	for tbl, col := range map[string]string{
+		video.MetaTableName: video.MetaVideoId,
+		video.PrevTableName: video.PrevVideoId,
+	} {
+		cnd := fmt.Sprintf("%s=$1", col)
+		query := fmt.Sprintf(template, tbl, cnd)
+
+		rows, err := db.Query(query, id)
+		if err != nil {
+			return err
+		}
+		rows.Close()
+	}
+
+	return nil
+}
+
+func (r *RetentionRepository) SetHold(hold *retention.Hold) error {
+	db := db_connect.CreateLocalDBConnection(r.cfg)
+	defer db_connect.CloseDBConnection(r.cfg, db)
+
+	hold.CreationDate = timedate.GetTimestamp()
+
+	template := qconsts.UPSERT_INTO_TBL_VALUES_VAL_ON_COL
+	tbl := fmt.Sprintf("%s (%s, %s, %s, %s)", retention.HoldTableName,
+		retention.HoldInfoId, retention.HoldReason, retention.HoldUserId,
+		retention.HoldCreationDate)
+	val := "($1, $2, $3, $4)"
+	col := retention.HoldInfoId
+	set := fmt.Sprintf("%s=$2, %s=$3, %s=$4", retention.HoldReason,
+		retention.HoldUserId, retention.HoldCreationDate)
+	query := fmt.Sprintf(template, tbl, val, col, set)
+
+	rows, err := db.Query(query, hold.InfoId, hold.Reason, hold.UserId,
+		hold.CreationDate)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	return nil
+}
+
+func (r *RetentionRepository) DeleteHold(infoId int) error {
+	db := db_connect.CreateLocalDBConnection(r.cfg)
+	defer db_connect.CloseDBConnection(r.cfg, db)
+
+	template := qconsts.DELETE_FROM_TBL_WHERE_CND
+	tbl := retention.HoldTableName
+	cnd := fmt.Sprintf("%s=$1", retention.HoldInfoId)
+	query := fmt.Sprintf(template, tbl, cnd)
+
+	rows, err := db.Query(query, infoId)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	return nil
+}
+
+func (r *RetentionRepository) IsHoldExists(infoId int) (bool, error) {
+	db := db_connect.CreateLocalDBConnection(r.cfg)
+	defer db_connect.CloseDBConnection(r.cfg, db)
+
+	template := qconsts.SELECT_COL_FROM_TBL_WHERE_CND
+	col := retention.HoldInfoId
+	tbl := retention.HoldTableName
+	cnd := fmt.Sprintf("%s=$1", retention.HoldInfoId)
+	query := fmt.Sprintf(template, col, tbl, cnd)
+
+	rows, err := db.Query(query, infoId)
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	if isRowPresent := rows.Next(); !isRowPresent {
+		return false, nil
+	}
+
+	return true, nil
+}
+
+func (r *RetentionRepository) GetHeldInfoIds() (map[int]bool, error) {
+	db := db_connect.CreateLocalDBConnection(r.cfg)
+	defer db_connect.CloseDBConnection(r.cfg, db)
+
+	template := qconsts.SELECT_COL_FROM_TBL
+	col := retention.HoldInfoId
+	tbl := retention.HoldTableName
+	query := fmt.Sprintf(template, col, tbl)
+
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	held := map[int]bool{}
+	var infoId int
+	for rows.Next() {
+		if err := rows.Scan(&infoId); err != nil {
+			return nil, err
+		}
+		held[infoId] = true
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return held, nil
+}
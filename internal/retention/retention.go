@@ -0,0 +1,74 @@
+package retention
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Report lists the expired infos with the linked videos, which are deleted
+// or would be deleted by a dry run. Held infos are listed but kept.
+type Report struct {
+	DryRun  bool          `json:"dryRun"`
+	Infos   []*InfoReport `json:"infos"`
+	Videos  int           `json:"videos"`
+	Deleted int           `json:"deleted"`
+	Held    int           `json:"held"`
+	Failed  int           `json:"failed"`
+}
+
+type InfoReport struct {
+	InfoId   int      `json:"infoId"`
+	Stream   string   `json:"stream"`
+	TimeLife string   `json:"timeLife"`
+	Held     bool     `json:"held"`
+	Videos   []*Video `json:"videos"`
+}
+
+type Video struct {
+	Id      int    `json:"id"      db:"id"`
+	File    string `json:"file"    db:"file"`
+	InfoId  int    `json:"-"       db:"info_id"`
+	Deleted bool   `json:"deleted" db:"-"`
+	Error   string `json:"error"   db:"-"`
+}
+
+// ExpiredInfo is an info of the outer database with expired "time_life".
+type ExpiredInfo struct {
+	Id       int    `db:"id"`
+	Stream   string `db:"stream"`
+	TimeLife string `db:"time_life"`
+}
+
+// Hold blocks the deletion of the info videos until it is released.
+type Hold struct {
+	InfoId       int    `json:"infoId"       db:"info_id"`
+	Reason       string `json:"reason"       db:"reason"`
+	UserId       int    `json:"userId"       db:"user_id"`
+	CreationDate string `json:"creationDate" db:"creation_date"`
+}
+
+type RetentionCommon interface {
+	SetHold(hold *Hold) error
+	DeleteHold(infoId int) error
+	IsHoldExists(infoId int) (bool, error)
+}
+
+type RetentionUseCase interface {
+	RetentionCommon
+
+	ServeRetention(ctx context.Context)
+	Enforce(dryRun bool) (*Report, error)
+
+	BindJSONHold(ctx *gin.Context) (*Hold, error)
+	AtoiRequestedId(ctx *gin.Context) (int, error)
+}
+
+type RetentionRepository interface {
+	RetentionCommon
+
+	GetExpiredInfos() ([]*ExpiredInfo, error)
+	GetVideosByInfoId(infoId int) ([]*Video, error)
+	DeleteVideo(id int) error
+	GetHeldInfoIds() (map[int]bool, error)
+}
@@ -0,0 +1,195 @@
+package usecase
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	msg "vhosting/internal/messages"
+	"vhosting/internal/retention"
+	"vhosting/pkg/config"
+	"vhosting/pkg/logger"
+	"vhosting/pkg/mediadir"
+)
+
+const logSessionOwner = "retention"
+
+type RetentionUseCase struct {
+	cfg           *config.Config
+	retentionRepo retention.RetentionRepository
+	logUseCase    logger.LogUseCase
+	enforceMu     sync.Mutex
+}
+
+func NewRetentionUseCase(cfg *config.Config, retentionRepo retention.RetentionRepository,
+	logUseCase logger.LogUseCase) *RetentionUseCase {
+	return &RetentionUseCase{
+		cfg:           cfg,
+		retentionRepo: retentionRepo,
+		logUseCase:    logUseCase,
+	}
+}
+
+// ServeRetention deletes the videos of expired infos every check period
+// until the context is cancelled. In the dry run mode the videos are only
+// reported.
+func (u *RetentionUseCase) ServeRetention(ctx context.Context) {
+	if !u.cfg.RetentionEnable {
+		logger.Print(msg.InfoRetentionDisabled())
+		return
+	}
+
+	logger.Print(msg.InfoRetentionStarted(u.cfg.RetentionCheckPeriodMinutes, u.cfg.RetentionDryRun))
+
+	ticker := time.NewTicker(time.Duration(u.cfg.RetentionCheckPeriodMinutes) * time.Minute)
+	defer ticker.Stop()
+	for {
+		u.enforcePeriodically()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (u *RetentionUseCase) enforcePeriodically() {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Print(msg.ErrorRetentionPanic(r))
+		}
+	}()
+
+	report, err := u.Enforce(u.cfg.RetentionDryRun)
+	if err != nil {
+		logger.Print(msg.ErrorCannotEnforceRetention(err))
+		return
+	}
+	logger.Print(msg.InfoRetentionReport(report))
+}
+
+// Enforce finds the expired infos and deletes the media files and rows of
+// their videos, unless the info is held or it is a dry run. Each deletion
+// is logged.
+func (u *RetentionUseCase) Enforce(dryRun bool) (*retention.Report, error) {
+	u.enforceMu.Lock()
+	defer u.enforceMu.Unlock()
+
+	infos, err := u.retentionRepo.GetExpiredInfos()
+	if err != nil {
+		return nil, err
+	}
+	held, err := u.retentionRepo.GetHeldInfoIds()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &retention.Report{DryRun: dryRun, Infos: []*retention.InfoReport{}}
+	for _, nfo := range infos {
+		videos, err := u.retentionRepo.GetVideosByInfoId(nfo.Id)
+		if err != nil {
+			return nil, err
+		}
+		if len(videos) == 0 {
+			continue
+		}
+
+		item := &retention.InfoReport{
+			InfoId:   nfo.Id,
+			Stream:   nfo.Stream,
+			TimeLife: nfo.TimeLife,
+			Held:     held[nfo.Id],
+			Videos:   videos,
+		}
+		report.Infos = append(report.Infos, item)
+		report.Videos += len(videos)
+
+		if item.Held {
+			report.Held += len(videos)
+			continue
+		}
+		if dryRun {
+			continue
+		}
+
+		for _, vid := range videos {
+			if err := u.deleteVideo(vid); err != nil {
+				vid.Error = err.Error()
+				report.Failed++
+				u.log(msg.ErrorCannotDeleteExpiredVideo(nfo.Id, vid.Id, err))
+				continue
+			}
+			vid.Deleted = true
+			report.Deleted++
+			u.log(msg.InfoExpiredVideoDeleted(nfo.Id, vid.Id, vid.File))
+		}
+	}
+
+	return report, nil
+}
+
+func (u *RetentionUseCase) SetHold(hold *retention.Hold) error {
+	return u.retentionRepo.SetHold(hold)
+}
+
+func (u *RetentionUseCase) DeleteHold(infoId int) error {
+	return u.retentionRepo.DeleteHold(infoId)
+}
+
+func (u *RetentionUseCase) IsHoldExists(infoId int) (bool, error) {
+	exists, err := u.retentionRepo.IsHoldExists(infoId)
+	if err != nil {
+		return false, err
+	}
+	return exists, nil
+}
+
+func (u *RetentionUseCase) BindJSONHold(ctx *gin.Context) (*retention.Hold, error) {
+	var hold retention.Hold
+	if err := ctx.BindJSON(&hold); err != nil {
+		return &hold, err
+	}
+	return &hold, nil
+}
+
+func (u *RetentionUseCase) AtoiRequestedId(ctx *gin.Context) (int, error) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		return -1, err
+	}
+	return id, nil
+}
+
+// deleteVideo removes the media file first, so a failed removal keeps the
+// row for the next check. A missing file is not an error.
+func (u *RetentionUseCase) deleteVideo(vid *retention.Video) error {
+	if vid.File != "" {
+		path, err := mediadir.Resolve(u.cfg.MediaDir, vid.File)
+		if err != nil {
+			return err
+		}
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if err := u.retentionRepo.DeleteVideo(vid.Id); err != nil {
+		return err
+	}
+	return os.RemoveAll(filepath.Join(u.cfg.PreviewDir, strconv.Itoa(vid.Id)))
+}
+
+// log writes the message to the logs table the way handlers do.
+func (u *RetentionUseCase) log(messageLog *logger.Log) {
+	log := logger.Init(nil)
+	log.SessionOwner = logSessionOwner
+	logger.Complete(log, messageLog)
+	logger.Finish(log)
+	if err := u.logUseCase.CreateLogRecord(log); err != nil {
+		logger.Print(msg.ErrorCannotDoLogging(err))
+	}
+	logger.Print(log)
+}
@@ -3,6 +3,16 @@ package session
 const (
 	TableName    = "sessions"
 	Id           = "id"
-	Content      = "content"
+	UserId       = "user_id"
+	ClientIP     = "client_ip"
+	UserAgent    = "user_agent"
 	CreationDate = "creation_date"
+	RefreshDate  = "refresh_date"
+	LastSeenDate = "last_seen_date"
+
+	RefTableName    = "refresh_tokens"
+	RefSessionId    = "session_id"
+	RefTokenHash    = "token_hash"
+	RefIsUsed       = "is_used"
+	RefCreationDate = "creation_date"
 )
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"fmt"
+
+	sess "vhosting/internal/session"
+	qconsts "vhosting/pkg/constants/query"
+	"vhosting/pkg/db_connect"
+)
+
+func (r *SessRepository) CreateRefreshToken(sessionId int, tokenHash, timestamp string) error {
+	db := db_connect.CreateLocalDBConnection(r.cfg)
+	defer db_connect.CloseDBConnection(r.cfg, db)
+
+	template := qconsts.INSERT_INTO_TBL_VALUES_VAL
+	tbl := fmt.Sprintf("%s (%s, %s, %s, %s)", sess.RefTableName, sess.RefSessionId, sess.RefTokenHash,
+		sess.RefIsUsed, sess.RefCreationDate)
+	val := "($1, $2, FALSE, $3)"
+	query := fmt.Sprintf(template, tbl, val)
+
+	rows, err := db.Query(query, sessionId, tokenHash, timestamp)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	return nil
+}
+
+// UseRefreshToken marks the unused token as used and returns its session
+// id, zero when there is no such unused token. Concurrent refreshes with
+// the same token get the session only once.
+func (r *SessRepository) UseRefreshToken(tokenHash string) (int, error) {
+	db := db_connect.CreateLocalDBConnection(r.cfg)
+	defer db_connect.CloseDBConnection(r.cfg, db)
+
+	template := qconsts.UPDATE_TBL_SET_VAL_WHERE_CND + " RETURNING %s"
+	tbl := sess.RefTableName
+	val := fmt.Sprintf("%s=TRUE", sess.RefIsUsed)
+	cnd := fmt.Sprintf("%s=$1 AND %s=FALSE", sess.RefTokenHash, sess.RefIsUsed)
+	query := fmt.Sprintf(template, tbl, val, cnd, sess.RefSessionId)
+
+	rows, err := db.Query(query, tokenHash)
+	if err != nil {
+		return -1, err
+	}
+	defer rows.Close()
+
+	sessionId := 0
+	if rows.Next() {
+		if err := rows.Scan(&sessionId); err != nil {
+			return -1, err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return -1, err
+	}
+
+	return sessionId, nil
+}
+
+// GetRefreshTokenSessionId returns the session id of the token whether it
+// is used or not, zero when the token is unknown.
+func (r *SessRepository) GetRefreshTokenSessionId(tokenHash string) (int, error) {
+	db := db_connect.CreateLocalDBConnection(r.cfg)
+	defer db_connect.CloseDBConnection(r.cfg, db)
+
+	template := qconsts.SELECT_COL_FROM_TBL_WHERE_CND
+	col := sess.RefSessionId
+	tbl := sess.RefTableName
+	cnd := fmt.Sprintf("%s=$1", sess.RefTokenHash)
+	query := fmt.Sprintf(template, col, tbl, cnd)
+
+	rows, err := db.Query(query, tokenHash)
+	if err != nil {
+		return -1, err
+	}
+	defer rows.Close()
+
+	sessionId := 0
+	if rows.Next() {
+		if err := rows.Scan(&sessionId); err != nil {
+			return -1, err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return -1, err
+	}
+
+	return sessionId, nil
+}
@@ -7,6 +7,7 @@ import (
 	"vhosting/pkg/config"
 	qconsts "vhosting/pkg/constants/query"
 	"vhosting/pkg/db_connect"
+	"vhosting/pkg/user"
 )
 
 type SessRepository struct {
@@ -17,34 +18,36 @@ func NewSessRepository(cfg *config.Config) *SessRepository {
 	return &SessRepository{cfg: cfg}
 }
 
-func (r *SessRepository) CreateSession(session *sess.Session) error {
+func (r *SessRepository) CreateSession(session *sess.Session) (int, error) {
 	db := db_connect.CreateLocalDBConnection(r.cfg)
 	defer db_connect.CloseDBConnection(r.cfg, db)
 
-	template := qconsts.INSERT_INTO_TBL_VALUES_VAL
-	tbl := fmt.Sprintf("%s (%s, %s)", sess.TableName, sess.Content, sess.CreationDate)
-	val := "($1, $2)"
-	query := fmt.Sprintf(template, tbl, val)
+	template := qconsts.INSERT_INTO_TBL_VALUES_VAL + " RETURNING %s"
+	tbl := fmt.Sprintf("%s (%s, %s, %s, %s, %s, %s)", sess.TableName, sess.UserId, sess.ClientIP,
+		sess.UserAgent, sess.CreationDate, sess.RefreshDate, sess.LastSeenDate)
+	val := "($1, $2, $3, $4, $5, $6)"
+	query := fmt.Sprintf(template, tbl, val, sess.Id)
 
-	_, err := db.Query(query, session.Content, session.CreationDate)
-	if err != nil {
-		return err
+	var id int
+	if err := db.Get(&id, query, session.UserId, session.ClientIP, session.UserAgent,
+		session.CreationDate, session.RefreshDate, session.LastSeenDate); err != nil {
+		return -1, err
 	}
 
-	return nil
+	return id, nil
 }
 
-func (r *SessRepository) GetSessionAndDate(token string) (*sess.Session, error) {
+// GetSession reads the session with the username of its user, the
+// returned session has zero id when it does not exist.
+func (r *SessRepository) GetSession(id int) (*sess.Session, error) {
 	db := db_connect.CreateLocalDBConnection(r.cfg)
 	defer db_connect.CloseDBConnection(r.cfg, db)
 
 	template := qconsts.SELECT_COL_FROM_TBL_WHERE_CND
-	col := fmt.Sprintf("%s, %s", sess.Content, sess.CreationDate)
-	tbl := sess.TableName
-	cnd := fmt.Sprintf("%s='%s'", sess.Content, token)
-	query := fmt.Sprintf(template, col, tbl, cnd)
+	cnd := fmt.Sprintf("s.%s=$1", sess.Id)
+	query := fmt.Sprintf(template, sessionCol(), sessionTbl(), cnd)
 
-	rows, err := db.Query(query)
+	rows, err := db.Query(query, id)
 	if err != nil {
 		return nil, err
 	}
@@ -52,7 +55,8 @@ func (r *SessRepository) GetSessionAndDate(token string) (*sess.Session, error)
 
 	var session sess.Session
 	for rows.Next() {
-		if err := rows.Scan(&session.Content, &session.CreationDate); err != nil {
+		if err := rows.Scan(&session.Id, &session.UserId, &session.Username, &session.ClientIP,
+			&session.UserAgent, &session.CreationDate, &session.RefreshDate, &session.LastSeenDate); err != nil {
 			return nil, err
 		}
 	}
@@ -64,16 +68,87 @@ func (r *SessRepository) GetSessionAndDate(token string) (*sess.Session, error)
 	return &session, nil
 }
 
-func (r *SessRepository) DeleteSession(token string) error {
+// GetUserSessions reads the sessions of the user, the recently seen first.
+func (r *SessRepository) GetUserSessions(userId int) ([]*sess.Session, error) {
+	db := db_connect.CreateLocalDBConnection(r.cfg)
+	defer db_connect.CloseDBConnection(r.cfg, db)
+
+	template := qconsts.SELECT_COL_FROM_TBL_WHERE_CND
+	cnd := fmt.Sprintf("s.%s=$1 ORDER BY s.%s DESC, s.%s DESC", sess.UserId, sess.LastSeenDate, sess.Id)
+	query := fmt.Sprintf(template, sessionCol(), sessionTbl(), cnd)
+
+	rows, err := db.Query(query, userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []*sess.Session{}
+	for rows.Next() {
+		var session sess.Session
+		if err := rows.Scan(&session.Id, &session.UserId, &session.Username, &session.ClientIP,
+			&session.UserAgent, &session.CreationDate, &session.RefreshDate, &session.LastSeenDate); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, &session)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+func (r *SessRepository) UpdateSessionLastSeen(id int, timestamp string) error {
+	db := db_connect.CreateLocalDBConnection(r.cfg)
+	defer db_connect.CloseDBConnection(r.cfg, db)
+
+	template := qconsts.UPDATE_TBL_SET_VAL_WHERE_CND
+	tbl := sess.TableName
+	val := fmt.Sprintf("%s=$1", sess.LastSeenDate)
+	cnd := fmt.Sprintf("%s=$2", sess.Id)
+	query := fmt.Sprintf(template, tbl, val, cnd)
+
+	rows, err := db.Query(query, timestamp, id)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	return nil
+}
+
+// UpdateSessionRefreshDate starts the new refresh lifetime of the session.
+func (r *SessRepository) UpdateSessionRefreshDate(id int, timestamp string) error {
+	db := db_connect.CreateLocalDBConnection(r.cfg)
+	defer db_connect.CloseDBConnection(r.cfg, db)
+
+	template := qconsts.UPDATE_TBL_SET_VAL_WHERE_CND
+	tbl := sess.TableName
+	val := fmt.Sprintf("%s=$1, %s=$1", sess.RefreshDate, sess.LastSeenDate)
+	cnd := fmt.Sprintf("%s=$2", sess.Id)
+	query := fmt.Sprintf(template, tbl, val, cnd)
+
+	rows, err := db.Query(query, timestamp, id)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	return nil
+}
+
+func (r *SessRepository) DeleteSession(id int) error {
 	db := db_connect.CreateLocalDBConnection(r.cfg)
 	defer db_connect.CloseDBConnection(r.cfg, db)
 
 	template := qconsts.DELETE_FROM_TBL_WHERE_CND
 	tbl := sess.TableName
-	cnd := fmt.Sprintf("%s=$1", sess.Content)
+	cnd := fmt.Sprintf("%s=$1", sess.Id)
 	query := fmt.Sprintf(template, tbl, cnd)
 
-	rows, err := db.Query(query, token)
+	rows, err := db.Query(query, id)
 	if err != nil {
 		return err
 	}
@@ -81,3 +156,34 @@ func (r *SessRepository) DeleteSession(token string) error {
 
 	return nil
 }
+
+// DeleteUserSessions deletes all the sessions of the user except the one
+// with exceptId, zero keeps none.
+func (r *SessRepository) DeleteUserSessions(userId, exceptId int) error {
+	db := db_connect.CreateLocalDBConnection(r.cfg)
+	defer db_connect.CloseDBConnection(r.cfg, db)
+
+	template := qconsts.DELETE_FROM_TBL_WHERE_CND
+	tbl := sess.TableName
+	cnd := fmt.Sprintf("%s=$1 AND %s<>$2", sess.UserId, sess.Id)
+	query := fmt.Sprintf(template, tbl, cnd)
+
+	rows, err := db.Query(query, userId, exceptId)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	return nil
+}
+
+// sessionCol and sessionTbl select the sessions with the usernames.
+func sessionCol() string {
+	return fmt.Sprintf("s.%s, s.%s, u.%s, s.%s, s.%s, s.%s, s.%s, s.%s", sess.Id, sess.UserId,
+		user.Username, sess.ClientIP, sess.UserAgent, sess.CreationDate, sess.RefreshDate, sess.LastSeenDate)
+}
+
+func sessionTbl() string {
+	return fmt.Sprintf("%s AS s JOIN %s AS u ON u.%s=s.%s", sess.TableName, user.TableName,
+		user.Id, sess.UserId)
+}
@@ -1,26 +1,61 @@
 package session
 
-import "github.com/gin-gonic/gin"
+import (
+	"errors"
 
+	"github.com/gin-gonic/gin"
+)
+
+// Session is stored on the server, the access token given to the client
+// carries only the session and user ids. The session lives while its
+// refresh token is rotated before it expires. The sessions of the API keys
+// are not stored, they have the key id and scopes instead.
 type Session struct {
-	Id           int    `db:"id"`
-	Content      string `db:"content"`
-	CreationDate string `db:"creationDate"`
+	Id           int    `json:"id"           db:"id"`
+	UserId       int    `json:"userId"       db:"user_id"`
+	Username     string `json:"username"     db:"username"`
+	ClientIP     string `json:"clientIp"     db:"client_ip"`
+	UserAgent    string `json:"userAgent"    db:"user_agent"`
+	CreationDate string `json:"creationDate" db:"creation_date"`
+	RefreshDate  string `json:"refreshDate"  db:"refresh_date"`
+	LastSeenDate string `json:"lastSeenDate" db:"last_seen_date"`
+	IsCurrent    bool   `json:"isCurrent"    db:"-"`
+
+	ApiKeyId int      `json:"-" db:"-"`
+	Scopes   []string `json:"-" db:"-"`
 }
 
-type SessCommon interface {
-	GetSessionAndDate(token string) (*Session, error)
-	DeleteSession(token string) error
+type RefreshRequest struct {
+	RefreshToken string `json:"refreshToken"`
 }
 
-type SessUseCase interface {
-	SessCommon
+var (
+	ErrRefreshTokenInvalid = errors.New("refresh token is invalid")
+	ErrRefreshTokenReused  = errors.New("refresh token is already used")
+	ErrRefreshTokenExpired = errors.New("refresh token is expired")
+)
 
-	CreateSession(ctx *gin.Context, username, token, timestamp string) error
+type SessUseCase interface {
+	CreateSession(ctx *gin.Context, userId int, username, timestamp string) (string, string, error)
+	RefreshSession(refreshToken string) (string, string, error)
+	BindJSONRefreshRequest(ctx *gin.Context) (*RefreshRequest, error)
+	GetSessionAndDate(ctx *gin.Context, token string) (*Session, error)
+	IsApiKeyGranted(session *Session, permission string) (bool, error)
+	GetUserSessions(userId int, token string) ([]*Session, error)
+	DeleteSession(token string) error
+	RevokeUserSession(userId, id int) (bool, error)
+	RevokeUserSessions(userId int, exceptToken string) error
 }
 
 type SessRepository interface {
-	SessCommon
-
-	CreateSession(session *Session) error
+	CreateSession(session *Session) (int, error)
+	GetSession(id int) (*Session, error)
+	GetUserSessions(userId int) ([]*Session, error)
+	UpdateSessionLastSeen(id int, timestamp string) error
+	UpdateSessionRefreshDate(id int, timestamp string) error
+	DeleteSession(id int) error
+	DeleteUserSessions(userId, exceptId int) error
+	CreateRefreshToken(sessionId int, tokenHash, timestamp string) error
+	UseRefreshToken(tokenHash string) (int, error)
+	GetRefreshTokenSessionId(tokenHash string) (int, error)
 }
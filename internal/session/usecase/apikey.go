@@ -0,0 +1,99 @@
+package usecase
+
+import (
+	"net"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	sess "vhosting/internal/session"
+	"vhosting/pkg/hasher"
+	"vhosting/pkg/timedate"
+)
+
+// getApiKeySession turns the API key into a session of its user. The
+// session has zero id when the key does not exist, is expired or is used
+// from an address it is not allowed from.
+func (u *SessUseCase) getApiKeySession(ctx *gin.Context, token string) (*sess.Session, error) {
+	key, err := u.apiKeyRepo.GetApiKeyByHash(hasher.HashToken(token))
+	if err != nil {
+		return nil, err
+	}
+	if key.Id == 0 {
+		return &sess.Session{}, nil
+	}
+	if key.ExpirationDate != "" && timedate.IsDateExpired(key.ExpirationDate, 0) {
+		return &sess.Session{}, nil
+	}
+	clientIP := ctx.ClientIP()
+	if !isIPAllowed(clientIP, key.AllowedIPs) {
+		return &sess.Session{}, nil
+	}
+
+	timestamp := timedate.GetTimestamp()
+	if err := u.apiKeyRepo.UpdateApiKeyLastUsed(key.Id, timestamp, clientIP); err != nil {
+		return nil, err
+	}
+
+	// The key has no refresh lifetime, the refresh date is now so the
+	// session is never expired by it
+	return &sess.Session{
+		Id:           key.Id,
+		UserId:       key.UserId,
+		Username:     key.Username,
+		ClientIP:     clientIP,
+		CreationDate: key.CreationDate,
+		RefreshDate:  time.Now().Format(time.RFC3339Nano),
+		LastSeenDate: timestamp,
+		ApiKeyId:     key.Id,
+		Scopes:       key.Scopes,
+	}, nil
+}
+
+// IsApiKeyGranted tells whether the API key of the session is scoped to the
+// permission and its user still holds it, so the key loses the permissions
+// taken from its user. The sessions of the users are not limited by scopes.
+func (u *SessUseCase) IsApiKeyGranted(session *sess.Session, permission string) (bool, error) {
+	if session.ApiKeyId == 0 {
+		return true, nil
+	}
+	isScoped := false
+	for _, scope := range session.Scopes {
+		if scope == permission {
+			isScoped = true
+			break
+		}
+	}
+	if !isScoped {
+		return false, nil
+	}
+
+	held, err := u.apiKeyRepo.GetHeldScopes(session.UserId, []string{permission})
+	if err != nil {
+		return false, err
+	}
+	return len(held) != 0, nil
+}
+
+// isIPAllowed matches the address against the addresses and CIDR ranges,
+// an empty list allows any address.
+func isIPAllowed(clientIP string, allowedIPs []string) bool {
+	if len(allowedIPs) == 0 {
+		return true
+	}
+	ip := net.ParseIP(clientIP)
+	if ip == nil {
+		return false
+	}
+	for _, allowed := range allowedIPs {
+		if allowedIP := net.ParseIP(allowed); allowedIP != nil {
+			if allowedIP.Equal(ip) {
+				return true
+			}
+			continue
+		}
+		if _, ipNet, err := net.ParseCIDR(allowed); err == nil && ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package usecase
+
+import (
+	"github.com/gin-gonic/gin"
+	sess "vhosting/internal/session"
+	"vhosting/pkg/hasher"
+	"vhosting/pkg/timedate"
+)
+
+// RefreshSession rotates the refresh token: it is used once and replaced
+// together with the access token. A token used a second time means that it
+// leaked, the whole session is revoked then.
+func (u *SessUseCase) RefreshSession(refreshToken string) (string, string, error) {
+	tokenHash := hasher.HashToken(refreshToken)
+	sessionId, err := u.sessRepo.UseRefreshToken(tokenHash)
+	if err != nil {
+		return "", "", err
+	}
+	if sessionId == 0 {
+		reusedId, err := u.sessRepo.GetRefreshTokenSessionId(tokenHash)
+		if err != nil {
+			return "", "", err
+		}
+		if reusedId == 0 {
+			return "", "", sess.ErrRefreshTokenInvalid
+		}
+		if err := u.sessRepo.DeleteSession(reusedId); err != nil {
+			return "", "", err
+		}
+		return "", "", sess.ErrRefreshTokenReused
+	}
+
+	session, err := u.sessRepo.GetSession(sessionId)
+	if err != nil {
+		return "", "", err
+	}
+	if session.Id == 0 {
+		return "", "", sess.ErrRefreshTokenInvalid
+	}
+	if timedate.IsDateExpired(session.RefreshDate, u.cfg.SessionTTLHours) {
+		if err := u.sessRepo.DeleteSession(session.Id); err != nil {
+			return "", "", err
+		}
+		return "", "", sess.ErrRefreshTokenExpired
+	}
+
+	timestamp := timedate.GetTimestamp()
+	token, newRefreshToken, err := u.issueTokens(session, timestamp)
+	if err != nil {
+		return "", "", err
+	}
+	if err := u.sessRepo.UpdateSessionRefreshDate(session.Id, timestamp); err != nil {
+		return "", "", err
+	}
+	return token, newRefreshToken, nil
+}
+
+func (u *SessUseCase) BindJSONRefreshRequest(ctx *gin.Context) (*sess.RefreshRequest, error) {
+	var req sess.RefreshRequest
+	if err := ctx.BindJSON(&req); err != nil {
+		return &req, err
+	}
+	return &req, nil
+}
+
+// issueTokens makes a new access token of the session and stores a new
+// refresh token of its family.
+func (u *SessUseCase) issueTokens(session *sess.Session, timestamp string) (string, string, error) {
+	token, err := hasher.GenerateToken(session.Id, session.UserId, u.cfg.HashingTokenSigningKey,
+		u.cfg.SessionAccessTTLMinutes)
+	if err != nil {
+		return "", "", err
+	}
+
+	refreshToken, err := hasher.GenerateRandomToken()
+	if err != nil {
+		return "", "", err
+	}
+	if err := u.sessRepo.CreateRefreshToken(session.Id, hasher.HashToken(refreshToken), timestamp); err != nil {
+		return "", "", err
+	}
+	return token, refreshToken, nil
+}
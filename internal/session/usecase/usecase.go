@@ -2,37 +2,139 @@ package usecase
 
 import (
 	"github.com/gin-gonic/gin"
+	"vhosting/internal/apikey"
 	sess "vhosting/internal/session"
 	"vhosting/pkg/auth"
+	"vhosting/pkg/config"
+	"vhosting/pkg/hasher"
+	"vhosting/pkg/timedate"
 )
 
+// userAgentMaxLen is the length of the user_agent column.
+const userAgentMaxLen = 255
+
 type SessUseCase struct {
-	sessRepo sess.SessRepository
-	authRepo auth.AuthRepository
+	cfg        *config.Config
+	sessRepo   sess.SessRepository
+	authRepo   auth.AuthRepository
+	apiKeyRepo apikey.ApiKeyRepository
 }
 
-func NewSessUseCase(sessRepo sess.SessRepository,
-	authRepo auth.AuthRepository) *SessUseCase {
+func NewSessUseCase(cfg *config.Config, sessRepo sess.SessRepository,
+	authRepo auth.AuthRepository, apiKeyRepo apikey.ApiKeyRepository) *SessUseCase {
 	return &SessUseCase{
-		sessRepo: sessRepo,
-		authRepo: authRepo,
+		cfg:        cfg,
+		sessRepo:   sessRepo,
+		authRepo:   authRepo,
+		apiKeyRepo: apiKeyRepo,
+	}
+}
+
+// CreateSession stores the session of the signed-in user and returns the
+// access token identifying it and the refresh token renewing it.
+func (u *SessUseCase) CreateSession(ctx *gin.Context, userId int, username, timestamp string) (string, string, error) {
+	userAgent := ctx.Request.UserAgent()
+	if len(userAgent) > userAgentMaxLen {
+		userAgent = userAgent[:userAgentMaxLen]
+	}
+	session := &sess.Session{
+		UserId:       userId,
+		ClientIP:     ctx.ClientIP(),
+		UserAgent:    userAgent,
+		CreationDate: timestamp,
+		RefreshDate:  timestamp,
+		LastSeenDate: timestamp,
+	}
+	id, err := u.sessRepo.CreateSession(session)
+	if err != nil {
+		return "", "", err
+	}
+	session.Id = id
+
+	token, refreshToken, err := u.issueTokens(session, timestamp)
+	if err != nil {
+		u.sessRepo.DeleteSession(id)
+		return "", "", err
+	}
+
+	if err := u.authRepo.UpdateNamepassLastLogin(username, timestamp); err != nil {
+		return "", "", err
 	}
+	return token, refreshToken, nil
 }
 
-func (u *SessUseCase) CreateSession(ctx *gin.Context, username, token, timestamp string) error {
-	var session sess.Session
-	session.Content = token
-	session.CreationDate = timestamp
-	if err := u.sessRepo.CreateSession(&session); err != nil {
-		return err
+// GetSessionAndDate finds the session of the access token or the API key
+// and marks it as seen now. The session has zero id when the token is not
+// valid, expired or the session does not exist anymore.
+func (u *SessUseCase) GetSessionAndDate(ctx *gin.Context, token string) (*sess.Session, error) {
+	if apikey.IsApiKey(token) {
+		return u.getApiKeySession(ctx, token)
+	}
+
+	sessionId, userId, err := hasher.ParseToken(token, u.cfg.HashingTokenSigningKey, true)
+	if err != nil {
+		return &sess.Session{}, nil
+	}
+
+	session, err := u.sessRepo.GetSession(sessionId)
+	if err != nil {
+		return nil, err
 	}
-	return u.authRepo.UpdateNamepassLastLogin(username, session.CreationDate)
+	if session.Id == 0 || session.UserId != userId {
+		return &sess.Session{}, nil
+	}
+
+	session.LastSeenDate = timedate.GetTimestamp()
+	if err := u.sessRepo.UpdateSessionLastSeen(session.Id, session.LastSeenDate); err != nil {
+		return nil, err
+	}
+	return session, nil
 }
 
-func (u *SessUseCase) GetSessionAndDate(token string) (*sess.Session, error) {
-	return u.sessRepo.GetSessionAndDate(token)
+// GetUserSessions returns the sessions of the user, the session of the
+// token is marked as the current one.
+func (u *SessUseCase) GetUserSessions(userId int, token string) ([]*sess.Session, error) {
+	sessions, err := u.sessRepo.GetUserSessions(userId)
+	if err != nil {
+		return nil, err
+	}
+	currentId, _, err := hasher.ParseToken(token, u.cfg.HashingTokenSigningKey, false)
+	if err != nil {
+		return sessions, nil
+	}
+	for _, session := range sessions {
+		session.IsCurrent = session.Id == currentId
+	}
+	return sessions, nil
 }
 
 func (u *SessUseCase) DeleteSession(token string) error {
-	return u.sessRepo.DeleteSession(token)
+	sessionId, _, err := hasher.ParseToken(token, u.cfg.HashingTokenSigningKey, false)
+	if err != nil {
+		return nil
+	}
+	return u.sessRepo.DeleteSession(sessionId)
+}
+
+// RevokeUserSession deletes the session if it belongs to the user and
+// reports whether it was found.
+func (u *SessUseCase) RevokeUserSession(userId, id int) (bool, error) {
+	session, err := u.sessRepo.GetSession(id)
+	if err != nil {
+		return false, err
+	}
+	if session.Id == 0 || session.UserId != userId {
+		return false, nil
+	}
+	return true, u.sessRepo.DeleteSession(id)
+}
+
+// RevokeUserSessions deletes all the sessions of the user except the one
+// of the token, the token may be empty or belong to another user.
+func (u *SessUseCase) RevokeUserSessions(userId int, exceptToken string) error {
+	exceptId, _, err := hasher.ParseToken(exceptToken, u.cfg.HashingTokenSigningKey, false)
+	if err != nil {
+		exceptId = 0
+	}
+	return u.sessRepo.DeleteUserSessions(userId, exceptId)
 }
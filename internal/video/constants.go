@@ -8,4 +8,43 @@ const (
 	CreateDate = "create_date"
 	InfoId     = "info_id"
 	UserId     = "user_id"
+
+	MetaTableName  = "video_metadata"
+	MetaVideoId    = "video_id"
+	MetaDuration   = "duration"
+	MetaContainer  = "container"
+	MetaVideoCodec = "video_codec"
+	MetaAudioCodec = "audio_codec"
+	MetaWidth      = "width"
+	MetaHeight     = "height"
+	MetaFrameRate  = "frame_rate"
+	MetaBitrate    = "bitrate"
+	MetaSize       = "size"
+	MetaProbeError = "probe_error"
+	MetaProbeDate  = "probe_date"
+
+	PrevTableName  = "video_previews"
+	PrevVideoId    = "video_id"
+	PrevState      = "state"
+	PrevError      = "error"
+	PrevUpdateDate = "update_date"
+
+	UplTableName    = "video_uploads"
+	UplId           = "id"
+	UplUserId       = "user_id"
+	UplInfoId       = "info_id"
+	UplUrl          = "url"
+	UplFileName     = "file_name"
+	UplFile         = "file"
+	UplLength       = "length"
+	UplOffset       = "upload_offset"
+	UplVideoId      = "video_id"
+	UplCreationDate = "creation_date"
+	UplUpdateDate   = "update_date"
+)
+
+const (
+	ThumbnailFile    = "thumbnail.jpg"
+	SpritesFile      = "sprites.jpg"
+	SpritesIndexFile = "sprites.vtt"
 )
@@ -2,7 +2,9 @@ package handler
 
 import (
 	"github.com/gin-gonic/gin"
+	"vhosting/internal/info"
 	msg "vhosting/internal/messages"
+	"vhosting/internal/retention"
 	sess "vhosting/internal/session"
 	"vhosting/internal/video"
 	"vhosting/pkg/auth"
@@ -19,18 +21,24 @@ type VideoHandler struct {
 	authUseCase auth.AuthUseCase
 	sessUseCase sess.SessUseCase
 	userUseCase user.UserUseCase
+	infoUseCase info.InfoUseCase
+
+	retentionUseCase retention.RetentionCommon
 }
 
 func NewVideoHandler(cfg *config.Config, useCase video.VideoUseCase,
 	logUseCase logger.LogUseCase, authUseCase auth.AuthUseCase,
-	sessUseCase sess.SessUseCase, userUseCase user.UserUseCase) *VideoHandler {
+	sessUseCase sess.SessUseCase, userUseCase user.UserUseCase,
+	infoUseCase info.InfoUseCase, retentionUseCase retention.RetentionCommon) *VideoHandler {
 	return &VideoHandler{
-		cfg:         cfg,
-		useCase:     useCase,
-		logUseCase:  logUseCase,
-		authUseCase: authUseCase,
-		sessUseCase: sessUseCase,
-		userUseCase: userUseCase,
+		cfg:              cfg,
+		useCase:          useCase,
+		logUseCase:       logUseCase,
+		authUseCase:      authUseCase,
+		sessUseCase:      sessUseCase,
+		userUseCase:      userUseCase,
+		infoUseCase:      infoUseCase,
+		retentionUseCase: retentionUseCase,
 	}
 }
 
@@ -202,6 +210,22 @@ func (h *VideoHandler) DeleteVideo(ctx *gin.Context) {
 		return
 	}
 
+	// The videos of a held info are kept until the hold is released
+	gottenVideo, err := h.useCase.GetVideo(reqId)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotGetVideo(err))
+		return
+	}
+	held, err := h.retentionUseCase.IsHoldExists(gottenVideo.InfoId)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotCheckLegalHoldExistence(err))
+		return
+	}
+	if held {
+		h.logUseCase.Report(ctx, log, msg.ErrorInfoIsUnderLegalHold())
+		return
+	}
+
 	if err := h.useCase.DeleteVideo(reqId); err != nil {
 		h.logUseCase.Report(ctx, log, msg.ErrorCannotDeleteVideo(err))
 		return
@@ -210,6 +234,49 @@ func (h *VideoHandler) DeleteVideo(ctx *gin.Context) {
 	h.logUseCase.Report(ctx, log, msg.InfoVideoDeleted())
 }
 
+// ReprobeVideo refreshes the metadata of the video file.
+func (h *VideoHandler) ReprobeVideo(ctx *gin.Context) {
+	actPermission := "reprobe_video"
+
+	log := logger.Init(ctx)
+
+	hasPerms, _ := h.isPermsGranted_getUserId(ctx, log, actPermission)
+	if !hasPerms {
+		return
+	}
+
+	// Read requested ID, check video existence, get video, probe it
+	reqId, err := h.useCase.AtoiRequestedId(ctx)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotConvertRequestedIDToTypeInt(err))
+		return
+	}
+
+	exists, err := h.useCase.IsVideoExists(reqId)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotCheckVideoExistence(err))
+		return
+	}
+	if !exists {
+		h.logUseCase.Report(ctx, log, msg.ErrorVideoWithRequestedIDIsNotExist())
+		return
+	}
+
+	gottenVideo, err := h.useCase.GetVideo(reqId)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotGetVideo(err))
+		return
+	}
+
+	meta, err := h.useCase.ProbeVideo(gottenVideo)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotProbeVideo(err))
+		return
+	}
+
+	h.logUseCase.Report(ctx, log, msg.InfoVideoProbed(meta))
+}
+
 func (h *VideoHandler) isPermsGranted_getUserId(ctx *gin.Context, log *logger.Log, permission string) (bool, int) {
 	headerToken := h.authUseCase.ReadHeader(ctx)
 	if !h.authUseCase.IsTokenExists(headerToken) {
@@ -217,7 +284,7 @@ func (h *VideoHandler) isPermsGranted_getUserId(ctx *gin.Context, log *logger.Lo
 		return false, -1
 	}
 
-	session, err := h.sessUseCase.GetSessionAndDate(headerToken)
+	session, err := h.sessUseCase.GetSessionAndDate(ctx, headerToken)
 	if err != nil {
 		h.logUseCase.Report(ctx, log, msg.ErrorCannotGetSessionAndDate(err))
 		return false, -1
@@ -227,7 +294,7 @@ func (h *VideoHandler) isPermsGranted_getUserId(ctx *gin.Context, log *logger.Lo
 		return false, -1
 	}
 
-	if timedate.IsDateExpired(session.CreationDate, h.cfg.SessionTTLHours) {
+	if timedate.IsDateExpired(session.RefreshDate, h.cfg.SessionTTLHours) {
 		if err := h.sessUseCase.DeleteSession(headerToken); err != nil {
 			h.logUseCase.Report(ctx, log, msg.ErrorCannotDeleteSession(err))
 			return false, -1
@@ -236,13 +303,7 @@ func (h *VideoHandler) isPermsGranted_getUserId(ctx *gin.Context, log *logger.Lo
 		return false, -1
 	}
 
-	headerNamepass, err := h.authUseCase.ParseToken(headerToken)
-	if err != nil {
-		h.logUseCase.Report(ctx, log, msg.ErrorCannotParseToken(err))
-		return false, -1
-	}
-
-	gottenUserId, err := h.userUseCase.GetUserId(headerNamepass.Username)
+	gottenUserId, err := h.userUseCase.GetUserId(session.Username)
 	if err != nil {
 		h.logUseCase.Report(ctx, log, msg.ErrorCannotCheckUserExistence(err))
 		return false, -1
@@ -256,11 +317,26 @@ func (h *VideoHandler) isPermsGranted_getUserId(ctx *gin.Context, log *logger.Lo
 		return false, -1
 	}
 
-	log.SessionOwner = headerNamepass.Username
+	log.SessionOwner = session.Username
+
+	// The API keys are granted the permissions of their scopes which their
+	// users still hold
+	if session.ApiKeyId != 0 {
+		granted, err := h.sessUseCase.IsApiKeyGranted(session, permission)
+		if err != nil {
+			h.logUseCase.Report(ctx, log, msg.ErrorCannotCheckApiKeyScopes(err))
+			return false, -1
+		}
+		if !granted {
+			h.logUseCase.Report(ctx, log, msg.ErrorYouHaveNotEnoughPermissions())
+			return false, -1
+		}
+		return true, gottenUserId
+	}
 
 	isSUorStaff := false
 	hasPersonalPerm := false
-	if isSUorStaff, err = h.userUseCase.IsUserSuperuserOrStaff(headerNamepass.Username); err != nil {
+	if isSUorStaff, err = h.userUseCase.IsUserSuperuserOrStaff(session.Username); err != nil {
 		h.logUseCase.Report(ctx, log, msg.ErrorCannotCheckSuperuserStaffPermissions(err))
 		return false, -1
 	}
@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"os"
+
+	"github.com/gin-gonic/gin"
+	msg "vhosting/internal/messages"
+	"vhosting/internal/video"
+	"vhosting/pkg/archive"
+	"vhosting/pkg/logger"
+)
+
+func (h *VideoHandler) GetThumbnail(ctx *gin.Context) {
+	h.servePreviewFile(ctx, video.ThumbnailFile, "image/jpeg")
+}
+
+func (h *VideoHandler) GetSprites(ctx *gin.Context) {
+	h.servePreviewFile(ctx, video.SpritesFile, "image/jpeg")
+}
+
+func (h *VideoHandler) GetSpritesIndex(ctx *gin.Context) {
+	h.servePreviewFile(ctx, video.SpritesIndexFile, "text/vtt; charset=utf-8")
+}
+
+// servePreviewFile sends the file made for the video once its preview is
+// done.
+func (h *VideoHandler) servePreviewFile(ctx *gin.Context, name, contentType string) {
+	actPermission := "get_video"
+
+	log := logger.Init(ctx)
+
+	hasPerms, _ := h.isPermsGranted_getUserId(ctx, log, actPermission)
+	if !hasPerms {
+		return
+	}
+
+	// Read requested ID, check video existence, check preview state
+	reqId, err := h.useCase.AtoiRequestedId(ctx)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotConvertRequestedIDToTypeInt(err))
+		return
+	}
+
+	exists, err := h.useCase.IsVideoExists(reqId)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotCheckVideoExistence(err))
+		return
+	}
+	if !exists {
+		h.logUseCase.Report(ctx, log, msg.ErrorVideoWithRequestedIDIsNotExist())
+		return
+	}
+
+	preview, err := h.useCase.GetPreview(reqId)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotGetVideoPreview(err))
+		return
+	}
+	if preview == nil || preview.State != archive.StateDone {
+		state := ""
+		if preview != nil {
+			state = preview.State
+		}
+		h.logUseCase.Report(ctx, log, msg.ErrorVideoPreviewIsNotReady(state))
+		return
+	}
+
+	path := h.useCase.PreviewPath(reqId, name)
+	if _, err := os.Stat(path); err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorVideoPreviewFileIsNotExist())
+		return
+	}
+
+	ctx.Header("Content-Type", contentType)
+	ctx.File(path)
+}
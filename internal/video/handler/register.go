@@ -2,6 +2,8 @@ package handler
 
 import (
 	"github.com/gin-gonic/gin"
+	"vhosting/internal/info"
+	"vhosting/internal/retention"
 	sess "vhosting/internal/session"
 	"vhosting/internal/video"
 	"vhosting/pkg/auth"
@@ -11,8 +13,9 @@ import (
 )
 
 func RegisterHTTPEndpoints(router *gin.Engine, cfg *config.Config, uc video.VideoUseCase, luc logger.LogUseCase,
-	auc auth.AuthUseCase, suc sess.SessUseCase, uuc user.UserUseCase) {
-	h := NewVideoHandler(cfg, uc, luc, auc, suc, uuc)
+	auc auth.AuthUseCase, suc sess.SessUseCase, uuc user.UserUseCase, iuc info.InfoUseCase,
+	ruc retention.RetentionCommon) {
+	h := NewVideoHandler(cfg, uc, luc, auc, suc, uuc, iuc, ruc)
 
 	videoRoute := router.Group("/video")
 	{
@@ -21,5 +24,14 @@ func RegisterHTTPEndpoints(router *gin.Engine, cfg *config.Config, uc video.Vide
 		videoRoute.GET("all", h.GetAllVideos)
 		videoRoute.PATCH(":id", h.PartiallyUpdateVideo)
 		videoRoute.DELETE(":id", h.DeleteVideo)
+		videoRoute.POST(":id/reprobe", h.ReprobeVideo)
+		videoRoute.GET(":id/thumbnail", h.GetThumbnail)
+		videoRoute.GET(":id/sprites.jpg", h.GetSprites)
+		videoRoute.GET(":id/sprites.vtt", h.GetSpritesIndex)
+		videoRoute.OPTIONS("upload", h.OptionsUpload)
+		videoRoute.POST("upload", h.CreateUpload)
+		videoRoute.HEAD("upload/:upload_id", h.HeadUpload)
+		videoRoute.PATCH("upload/:upload_id", h.PatchUpload)
+		videoRoute.DELETE("upload/:upload_id", h.DeleteUpload)
 	}
 }
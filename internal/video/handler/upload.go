@@ -0,0 +1,223 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	msg "vhosting/internal/messages"
+	"vhosting/internal/video"
+	"vhosting/pkg/logger"
+)
+
+const (
+	tusVersion     = "1.0.0"
+	tusExtensions  = "creation,termination,expiration"
+	tusContentType = "application/offset+octet-stream"
+)
+
+// OptionsUpload tells tus clients the supported version and extensions.
+func (h *VideoHandler) OptionsUpload(ctx *gin.Context) {
+	ctx.Header("Tus-Resumable", tusVersion)
+	ctx.Header("Tus-Version", tusVersion)
+	ctx.Header("Tus-Extension", tusExtensions)
+	ctx.Header("Tus-Max-Size", strconv.FormatInt(h.maxUploadSize(), 10))
+	ctx.Status(http.StatusNoContent)
+}
+
+// CreateUpload starts an upload of Upload-Length bytes. Upload-Metadata must
+// have "info_id" of the info the video is created for, "filename" and "url"
+// are optional.
+func (h *VideoHandler) CreateUpload(ctx *gin.Context) {
+	actPermission := "post_video"
+
+	log := logger.Init(ctx)
+
+	if !h.isTusResumable(ctx, log) {
+		return
+	}
+
+	hasPerms, userId := h.isPermsGranted_getUserId(ctx, log, actPermission)
+	if !hasPerms {
+		return
+	}
+
+	// Read upload length and metadata, check info existence
+	length, err := strconv.ParseInt(ctx.GetHeader("Upload-Length"), 10, 64)
+	if err != nil || length <= 0 {
+		h.logUseCase.Report(ctx, log, msg.ErrorUploadLengthIsInvalid())
+		return
+	}
+	if length > h.maxUploadSize() {
+		h.logUseCase.Report(ctx, log, msg.ErrorUploadIsTooLarge(h.cfg.UploadMaxSizeMB))
+		return
+	}
+
+	meta, err := h.useCase.ParseUploadMetadata(ctx.GetHeader("Upload-Metadata"))
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotParseUploadMetadata(err))
+		return
+	}
+
+	infoId, err := strconv.Atoi(meta["info_id"])
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorUploadInfoIdIsInvalid())
+		return
+	}
+
+	exists, err := h.infoUseCase.IsInfoExists(infoId)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotCheckInfoExistence(err))
+		return
+	}
+	if !exists {
+		h.logUseCase.Report(ctx, log, msg.ErrorInfoWithRequestedIDIsNotExist())
+		return
+	}
+
+	upl := &video.Upload{
+		UserId:   userId,
+		InfoId:   infoId,
+		Url:      meta["url"],
+		FileName: meta["filename"],
+		Length:   length,
+	}
+	if upl.Url == "" {
+		upl.Url = upl.FileName
+	}
+
+	if err := h.useCase.CreateUpload(upl); err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotCreateUpload(err))
+		return
+	}
+
+	ctx.Header("Location", "/video/upload/"+upl.Id)
+	h.setUploadExpires(ctx, upl)
+
+	h.logUseCase.Report(ctx, log, msg.InfoUploadCreated(upl))
+}
+
+// HeadUpload returns the offset to resume the upload from.
+func (h *VideoHandler) HeadUpload(ctx *gin.Context) {
+	log := logger.Init(ctx)
+
+	upl, ok := h.getOwnUpload(ctx, log)
+	if !ok {
+		return
+	}
+
+	ctx.Header("Cache-Control", "no-store")
+	ctx.Header("Upload-Offset", strconv.FormatInt(upl.Offset, 10))
+	ctx.Header("Upload-Length", strconv.FormatInt(upl.Length, 10))
+	h.setUploadExpires(ctx, upl)
+
+	h.logUseCase.Report(ctx, log, msg.InfoGotUpload(upl))
+}
+
+// PatchUpload appends the request body at Upload-Offset, which must be the
+// current offset of the upload.
+func (h *VideoHandler) PatchUpload(ctx *gin.Context) {
+	log := logger.Init(ctx)
+
+	upl, ok := h.getOwnUpload(ctx, log)
+	if !ok {
+		return
+	}
+
+	if ctx.GetHeader("Content-Type") != tusContentType {
+		h.logUseCase.Report(ctx, log, msg.ErrorUploadContentTypeIsInvalid())
+		return
+	}
+
+	offset, err := strconv.ParseInt(ctx.GetHeader("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		h.logUseCase.Report(ctx, log, msg.ErrorUploadOffsetIsInvalid())
+		return
+	}
+	if offset != upl.Offset {
+		h.logUseCase.Report(ctx, log, msg.ErrorUploadOffsetMismatch(upl.Offset))
+		return
+	}
+
+	err = h.useCase.WriteUpload(upl, ctx.Request.Body)
+	ctx.Header("Upload-Offset", strconv.FormatInt(upl.Offset, 10))
+	h.setUploadExpires(ctx, upl)
+	switch {
+	case err == nil:
+		h.logUseCase.Report(ctx, log, msg.InfoUploadWritten(upl.Offset))
+	case errors.Is(err, video.ErrUploadIsLocked):
+		h.logUseCase.Report(ctx, log, msg.ErrorUploadIsLocked())
+	case errors.Is(err, video.ErrUploadOffsetMismatch):
+		h.logUseCase.Report(ctx, log, msg.ErrorUploadOffsetMismatch(upl.Offset))
+	default:
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotWriteUpload(err))
+	}
+}
+
+// DeleteUpload terminates the upload. The video of a completed upload is
+// kept.
+func (h *VideoHandler) DeleteUpload(ctx *gin.Context) {
+	log := logger.Init(ctx)
+
+	upl, ok := h.getOwnUpload(ctx, log)
+	if !ok {
+		return
+	}
+
+	if err := h.useCase.DeleteUpload(upl); err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotDeleteUpload(err))
+		return
+	}
+
+	h.logUseCase.Report(ctx, log, msg.InfoUploadDeleted())
+}
+
+// getOwnUpload reads the requested upload, which is available to the user
+// who created it only.
+func (h *VideoHandler) getOwnUpload(ctx *gin.Context, log *logger.Log) (*video.Upload, bool) {
+	actPermission := "post_video"
+
+	if !h.isTusResumable(ctx, log) {
+		return nil, false
+	}
+
+	hasPerms, userId := h.isPermsGranted_getUserId(ctx, log, actPermission)
+	if !hasPerms {
+		return nil, false
+	}
+
+	upl, err := h.useCase.GetUpload(ctx.Param("upload_id"))
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotGetUpload(err))
+		return nil, false
+	}
+	if upl == nil || upl.UserId != userId {
+		h.logUseCase.Report(ctx, log, msg.ErrorUploadIsNotExist())
+		return nil, false
+	}
+
+	return upl, true
+}
+
+// isTusResumable sets the protocol version of the response and checks the
+// version of the request.
+func (h *VideoHandler) isTusResumable(ctx *gin.Context, log *logger.Log) bool {
+	ctx.Header("Tus-Resumable", tusVersion)
+	if ctx.GetHeader("Tus-Resumable") != tusVersion {
+		ctx.Header("Tus-Version", tusVersion)
+		h.logUseCase.Report(ctx, log, msg.ErrorTusVersionIsNotSupported())
+		return false
+	}
+	return true
+}
+
+func (h *VideoHandler) setUploadExpires(ctx *gin.Context, upl *video.Upload) {
+	if expires, ok := h.useCase.UploadExpirationDate(upl); ok {
+		ctx.Header("Upload-Expires", expires.UTC().Format(http.TimeFormat))
+	}
+}
+
+func (h *VideoHandler) maxUploadSize() int64 {
+	return int64(h.cfg.UploadMaxSizeMB) << 20
+}
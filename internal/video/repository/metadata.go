@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/lib/pq"
+	"vhosting/internal/video"
+	qconsts "vhosting/pkg/constants/query"
+	"vhosting/pkg/db_connect"
+)
+
+var metaColumns = fmt.Sprintf("%s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s",
+	video.MetaVideoId, video.MetaDuration, video.MetaContainer,
+	video.MetaVideoCodec, video.MetaAudioCodec, video.MetaWidth,
+	video.MetaHeight, video.MetaFrameRate, video.MetaBitrate, video.MetaSize,
+	video.MetaProbeError, video.MetaProbeDate)
+
+// SetMetadata stores the metadata in the local database as the videos are
+// kept in the outer one.
+func (r *VideoRepository) SetMetadata(meta *video.Metadata) error {
+	db := db_connect.CreateLocalDBConnection(r.cfg)
+	defer db_connect.CloseDBConnection(r.cfg, db)
+
+	template := qconsts.UPSERT_INTO_TBL_VALUES_VAL_ON_COL
+	tbl := fmt.Sprintf("%s (%s)", video.MetaTableName, metaColumns)
+	val := "($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)"
+	col := video.MetaVideoId
+	set := fmt.Sprintf("%s=$2, %s=$3, %s=$4, %s=$5, %s=$6, %s=$7, %s=$8, %s=$9, %s=$10, %s=$11, %s=$12",
+		video.MetaDuration, video.MetaContainer, video.MetaVideoCodec,
+		video.MetaAudioCodec, video.MetaWidth, video.MetaHeight,
+		video.MetaFrameRate, video.MetaBitrate, video.MetaSize,
+		video.MetaProbeError, video.MetaProbeDate)
+	query := fmt.Sprintf(template, tbl, val, col, set)
+
+	rows, err := db.Query(query, meta.VideoId, meta.Duration, meta.Container,
+		meta.VideoCodec, meta.AudioCodec, meta.Width, meta.Height,
+		meta.FrameRate, meta.Bitrate, meta.Size, meta.ProbeError,
+		meta.ProbeDate)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	return nil
+}
+
+// GetMetadata returns nil if the video has not been probed yet.
+func (r *VideoRepository) GetMetadata(videoId int) (*video.Metadata, error) {
+	metas, err := r.GetMetadatas([]int{videoId})
+	if err != nil {
+		return nil, err
+	}
+	return metas[videoId], nil
+}
+
+func (r *VideoRepository) GetMetadatas(videoIds []int) (map[int]*video.Metadata, error) {
+	var metas = map[int]*video.Metadata{}
+	if len(videoIds) == 0 {
+		return metas, nil
+	}
+
+	db := db_connect.CreateLocalDBConnection(r.cfg)
+	defer db_connect.CloseDBConnection(r.cfg, db)
+
+	template := qconsts.SELECT_COL_FROM_TBL_WHERE_CND
+	tbl := video.MetaTableName
+	cnd := fmt.Sprintf("%s = ANY($1)", video.MetaVideoId)
+	query := fmt.Sprintf(template, metaColumns, tbl, cnd)
+
+	rows, err := db.Query(query, pq.Array(videoIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var meta video.Metadata
+		if err := rows.Scan(&meta.VideoId, &meta.Duration, &meta.Container,
+			&meta.VideoCodec, &meta.AudioCodec, &meta.Width, &meta.Height,
+			&meta.FrameRate, &meta.Bitrate, &meta.Size, &meta.ProbeError,
+			&meta.ProbeDate); err != nil {
+			return nil, err
+		}
+		metas[meta.VideoId] = &meta
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return metas, nil
+}
+
+func (r *VideoRepository) DeleteMetadata(videoId int) error {
+	db := db_connect.CreateLocalDBConnection(r.cfg)
+	defer db_connect.CloseDBConnection(r.cfg, db)
+
+	template := qconsts.DELETE_FROM_TBL_WHERE_CND
+	tbl := video.MetaTableName
+	cnd := fmt.Sprintf("%s=$1", video.MetaVideoId)
+	query := fmt.Sprintf(template, tbl, cnd)
+
+	rows, err := db.Query(query, videoId)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	return nil
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/lib/pq"
+	"vhosting/internal/video"
+	qconsts "vhosting/pkg/constants/query"
+	"vhosting/pkg/db_connect"
+	"vhosting/pkg/timedate"
+)
+
+var prevColumns = fmt.Sprintf("%s, %s, %s, %s", video.PrevVideoId,
+	video.PrevState, video.PrevError, video.PrevUpdateDate)
+
+func (r *VideoRepository) SetPreview(preview *video.Preview) error {
+	db := db_connect.CreateLocalDBConnection(r.cfg)
+	defer db_connect.CloseDBConnection(r.cfg, db)
+
+	preview.UpdateDate = timedate.GetTimestamp()
+
+	template := qconsts.UPSERT_INTO_TBL_VALUES_VAL_ON_COL
+	tbl := fmt.Sprintf("%s (%s)", video.PrevTableName, prevColumns)
+	val := "($1, $2, $3, $4)"
+	col := video.PrevVideoId
+	set := fmt.Sprintf("%s=$2, %s=$3, %s=$4", video.PrevState,
+		video.PrevError, video.PrevUpdateDate)
+	query := fmt.Sprintf(template, tbl, val, col, set)
+
+	rows, err := db.Query(query, preview.VideoId, preview.State,
+		preview.Error, preview.UpdateDate)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	return nil
+}
+
+func (r *VideoRepository) GetPreviews(videoIds []int) (map[int]*video.Preview, error) {
+	var previews = map[int]*video.Preview{}
+	if len(videoIds) == 0 {
+		return previews, nil
+	}
+
+	db := db_connect.CreateLocalDBConnection(r.cfg)
+	defer db_connect.CloseDBConnection(r.cfg, db)
+
+	template := qconsts.SELECT_COL_FROM_TBL_WHERE_CND
+	tbl := video.PrevTableName
+	cnd := fmt.Sprintf("%s = ANY($1)", video.PrevVideoId)
+	query := fmt.Sprintf(template, prevColumns, tbl, cnd)
+
+	list := []*video.Preview{}
+	if err := db.Select(&list, query, pq.Array(videoIds)); err != nil {
+		return nil, err
+	}
+	for _, preview := range list {
+		previews[preview.VideoId] = preview
+	}
+
+	return previews, nil
+}
+
+func (r *VideoRepository) GetPreviewsByState(state string) ([]*video.Preview, error) {
+	db := db_connect.CreateLocalDBConnection(r.cfg)
+	defer db_connect.CloseDBConnection(r.cfg, db)
+
+	template := qconsts.SELECT_COL_FROM_TBL_WHERE_CND
+	tbl := video.PrevTableName
+	cnd := fmt.Sprintf("%s=$1 ORDER BY %s", video.PrevState, video.PrevVideoId)
+	query := fmt.Sprintf(template, prevColumns, tbl, cnd)
+
+	previews := []*video.Preview{}
+	if err := db.Select(&previews, query, state); err != nil {
+		return nil, err
+	}
+
+	return previews, nil
+}
+
+func (r *VideoRepository) DeletePreview(videoId int) error {
+	db := db_connect.CreateLocalDBConnection(r.cfg)
+	defer db_connect.CloseDBConnection(r.cfg, db)
+
+	template := qconsts.DELETE_FROM_TBL_WHERE_CND
+	tbl := video.PrevTableName
+	cnd := fmt.Sprintf("%s=$1", video.PrevVideoId)
+	query := fmt.Sprintf(template, tbl, cnd)
+
+	rows, err := db.Query(query, videoId)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	return nil
+}
@@ -24,15 +24,15 @@ func (r *VideoRepository) CreateVideo(vid *video.Video) error {
 	dbo := db_connect.CreateOuterDBConnection(r.cfg)
 	defer db_connect.CloseDBConnection(r.cfg, dbo)
 
-	template := qconsts.INSERT_INTO_TBL_VALUES_VAL
+	template := qconsts.INSERT_INTO_TBL_VALUES_VAL + " RETURNING %s"
 	tbl := fmt.Sprintf("%s (%s, %s, %s, %s, %s)", video.TableName,
 		video.Url, video.File, video.CreateDate, video.InfoId,
 		video.UserId)
-	val := fmt.Sprintf("('%s', '%s', '%s', %d, %d)", vid.Url, vid.File,
-		vid.CreateDate, vid.InfoId, vid.UserId)
-	query := fmt.Sprintf(template, tbl, val)
+	val := "($1, $2, $3, $4, $5)"
+	query := fmt.Sprintf(template, tbl, val, video.Id)
 
-	if _, err := dbo.Query(query); err != nil {
+	if err := dbo.QueryRow(query, vid.Url, vid.File, vid.CreateDate, vid.InfoId,
+		vid.UserId).Scan(&vid.Id); err != nil {
 		return err
 	}
 
@@ -108,13 +108,13 @@ func (r *VideoRepository) PartiallyUpdateVideo(vid *video.Video) error {
 
 	template := qconsts.UPDATE_TBL_SET_VAL_WHERE_CND
 	tbl := video.TableName
-	val := fmt.Sprintf("%s=CASE WHEN '%s' <> '' THEN '%s' ELSE %s END, ", video.Url, vid.Url, vid.Url, video.Url) +
-		fmt.Sprintf("%s=CASE WHEN '%s' <> '' THEN '%s' ELSE %s END, ", video.File, vid.File, vid.File, video.File) +
-		fmt.Sprintf("%s=CASE WHEN %d > -1 THEN %d ELSE %s END", video.InfoId, vid.InfoId, vid.InfoId, video.InfoId)
-	cnd := fmt.Sprintf("%s=%d", video.Id, vid.Id)
+	val := fmt.Sprintf("%s=CASE WHEN $1::text <> '' THEN $1::text ELSE %s END, ", video.Url, video.Url) +
+		fmt.Sprintf("%s=CASE WHEN $2::text <> '' THEN $2::text ELSE %s END, ", video.File, video.File) +
+		fmt.Sprintf("%s=CASE WHEN $3::integer > -1 THEN $3::integer ELSE %s END", video.InfoId, video.InfoId)
+	cnd := fmt.Sprintf("%s=$4", video.Id)
 	query := fmt.Sprintf(template, tbl, val, cnd)
 
-	rows, err := dbo.Query(query)
+	rows, err := dbo.Query(query, vid.Url, vid.File, vid.InfoId, vid.Id)
 	if err != nil {
 		return err
 	}
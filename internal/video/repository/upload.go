@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"vhosting/internal/video"
+	qconsts "vhosting/pkg/constants/query"
+	"vhosting/pkg/db_connect"
+	"vhosting/pkg/timedate"
+)
+
+var uplColumns = fmt.Sprintf("%s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s",
+	video.UplId, video.UplUserId, video.UplInfoId, video.UplUrl,
+	video.UplFileName, video.UplFile, video.UplLength, video.UplOffset,
+	video.UplVideoId, video.UplCreationDate, video.UplUpdateDate)
+
+func (r *VideoRepository) CreateUpload(upl *video.Upload) error {
+	db := db_connect.CreateLocalDBConnection(r.cfg)
+	defer db_connect.CloseDBConnection(r.cfg, db)
+
+	upl.CreationDate = timedate.GetTimestamp()
+	upl.UpdateDate = upl.CreationDate
+
+	template := qconsts.INSERT_INTO_TBL_VALUES_VAL
+	tbl := fmt.Sprintf("%s (%s)", video.UplTableName, uplColumns)
+	val := "($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)"
+	query := fmt.Sprintf(template, tbl, val)
+
+	rows, err := db.Query(query, upl.Id, upl.UserId, upl.InfoId, upl.Url,
+		upl.FileName, upl.File, upl.Length, upl.Offset, upl.VideoId,
+		upl.CreationDate, upl.UpdateDate)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	return nil
+}
+
+// GetUpload returns nil if there is no upload with the ID.
+func (r *VideoRepository) GetUpload(id string) (*video.Upload, error) {
+	db := db_connect.CreateLocalDBConnection(r.cfg)
+	defer db_connect.CloseDBConnection(r.cfg, db)
+
+	template := qconsts.SELECT_COL_FROM_TBL_WHERE_CND
+	tbl := video.UplTableName
+	cnd := fmt.Sprintf("%s=$1", video.UplId)
+	query := fmt.Sprintf(template, uplColumns, tbl, cnd)
+
+	uploads := []*video.Upload{}
+	if err := db.Select(&uploads, query, id); err != nil {
+		return nil, err
+	}
+	if len(uploads) == 0 {
+		return nil, nil
+	}
+
+	return uploads[0], nil
+}
+
+func (r *VideoRepository) UpdateUpload(upl *video.Upload) error {
+	db := db_connect.CreateLocalDBConnection(r.cfg)
+	defer db_connect.CloseDBConnection(r.cfg, db)
+
+	upl.UpdateDate = timedate.GetTimestamp()
+
+	template := qconsts.UPDATE_TBL_SET_VAL_WHERE_CND
+	tbl := video.UplTableName
+	val := fmt.Sprintf("%s=$1, %s=$2, %s=$3", video.UplOffset,
+		video.UplVideoId, video.UplUpdateDate)
+	cnd := fmt.Sprintf("%s=$4", video.UplId)
+	query := fmt.Sprintf(template, tbl, val, cnd)
+
+	rows, err := db.Query(query, upl.Offset, upl.VideoId, upl.UpdateDate, upl.Id)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	return nil
+}
+
+func (r *VideoRepository) DeleteUpload(id string) error {
+	db := db_connect.CreateLocalDBConnection(r.cfg)
+	defer db_connect.CloseDBConnection(r.cfg, db)
+
+	template := qconsts.DELETE_FROM_TBL_WHERE_CND
+	tbl := video.UplTableName
+	cnd := fmt.Sprintf("%s=$1", video.UplId)
+	query := fmt.Sprintf(template, tbl, cnd)
+
+	rows, err := db.Query(query, id)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	return nil
+}
+
+// GetExpiredUploads returns the uploads not completed and not written to
+// since the time.
+func (r *VideoRepository) GetExpiredUploads(before time.Time) ([]*video.Upload, error) {
+	db := db_connect.CreateLocalDBConnection(r.cfg)
+	defer db_connect.CloseDBConnection(r.cfg, db)
+
+	template := qconsts.SELECT_COL_FROM_TBL_WHERE_CND
+	tbl := video.UplTableName
+	cnd := fmt.Sprintf("%s=0 AND %s < $1 ORDER BY %s", video.UplVideoId,
+		video.UplUpdateDate, video.UplUpdateDate)
+	query := fmt.Sprintf(template, uplColumns, tbl, cnd)
+
+	uploads := []*video.Upload{}
+	if err := db.Select(&uploads, query, before); err != nil {
+		return nil, err
+	}
+
+	return uploads, nil
+}
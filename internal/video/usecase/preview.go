@@ -0,0 +1,208 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	msg "vhosting/internal/messages"
+	"vhosting/internal/video"
+	"vhosting/pkg/archive"
+	"vhosting/pkg/logger"
+	"vhosting/pkg/mediadir"
+)
+
+const (
+	thumbnailWidth = 640
+	maxSpriteTiles = 600 // the interval grows for longer videos
+)
+
+// ServePreviews starts the preview workers. Previews left queued or running
+// by a previous run are made again.
+func (u *VideoUseCase) ServePreviews() {
+	if err := os.MkdirAll(u.cfg.PreviewDir, 0777); err != nil {
+		logger.Print(msg.ErrorCannotCreateVideoPreviewDir(err))
+		return
+	}
+
+	for i := 0; i < u.cfg.PreviewWorkers; i++ {
+		go func() {
+			for id := range u.queue {
+				u.processPreview(id)
+			}
+		}()
+	}
+
+	var ids []int
+	for _, state := range []string{archive.StateRunning, archive.StateQueued} {
+		previews, err := u.videoRepo.GetPreviewsByState(state)
+		if err != nil {
+			logger.Print(msg.ErrorCannotGetVideoPreviews(err))
+			continue
+		}
+		for _, preview := range previews {
+			ids = append(ids, preview.VideoId)
+		}
+	}
+	go func() {
+		for _, id := range ids {
+			u.queue <- id
+		}
+	}()
+
+	logger.Print(msg.InfoVideoPreviewWorkersStarted(u.cfg.PreviewWorkers))
+}
+
+// GetPreview returns nil if the preview has never been queued.
+func (u *VideoUseCase) GetPreview(videoId int) (*video.Preview, error) {
+	previews, err := u.videoRepo.GetPreviews([]int{videoId})
+	if err != nil {
+		return nil, err
+	}
+	return previews[videoId], nil
+}
+
+func (u *VideoUseCase) PreviewPath(videoId int, name string) string {
+	return filepath.Join(u.previewDir(videoId), name)
+}
+
+// queuePreview marks the preview queued and waits for a free place in the
+// queue in background, so the caller is never blocked.
+func (u *VideoUseCase) queuePreview(videoId int) {
+	preview := &video.Preview{VideoId: videoId, State: archive.StateQueued}
+	if err := u.videoRepo.SetPreview(preview); err != nil {
+		logger.Print(msg.ErrorCannotSetVideoPreview(err))
+		return
+	}
+	go func() {
+		u.queue <- videoId
+	}()
+}
+
+func (u *VideoUseCase) processPreview(videoId int) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Print(msg.ErrorVideoPreviewWorkerPanic(r))
+		}
+	}()
+
+	preview := &video.Preview{VideoId: videoId, State: archive.StateRunning}
+	if err := u.videoRepo.SetPreview(preview); err != nil {
+		logger.Print(msg.ErrorCannotSetVideoPreview(err))
+		return
+	}
+
+	err := u.makePreview(videoId)
+	if err != nil {
+		preview.State = archive.StateFailed
+		preview.Error = err.Error()
+		logger.Print(msg.ErrorVideoPreviewFailed(videoId, err))
+	} else {
+		preview.State = archive.StateDone
+		logger.Print(msg.InfoVideoPreviewDone(videoId))
+	}
+
+	if err := u.videoRepo.SetPreview(preview); err != nil {
+		logger.Print(msg.ErrorCannotSetVideoPreview(err))
+	}
+}
+
+// makePreview probes the video file first, the duration and the picture
+// size are needed to lay out the sprites.
+func (u *VideoUseCase) makePreview(videoId int) error {
+	vid, err := u.videoRepo.GetVideo(videoId)
+	if err != nil {
+		return err
+	}
+	meta, err := u.ProbeVideo(vid)
+	if err != nil {
+		return err
+	}
+	if meta.VideoCodec == "" || meta.Duration <= 0 {
+		return errors.New("no video stream or unknown duration of the file")
+	}
+	inputPath, err := mediadir.Resolve(u.cfg.MediaDir, vid.File)
+	if err != nil {
+		return err
+	}
+
+	dir := u.previewDir(videoId)
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	duration := time.Duration(meta.Duration * float64(time.Second))
+
+	// The very first frames are often black
+	at := duration / 10
+	if at > 10*time.Second {
+		at = 10 * time.Second
+	}
+	if err := u.archiveUseCase.MakeThumbnail(ctx, inputPath,
+		filepath.Join(dir, video.ThumbnailFile), at, thumbnailWidth); err != nil {
+		return err
+	}
+
+	interval := time.Duration(u.cfg.PreviewIntervalSeconds) * time.Second
+	if limit := duration / maxSpriteTiles; interval < limit {
+		interval = limit.Round(time.Second) + time.Second
+	}
+	tiles := int(math.Ceil(float64(duration) / float64(interval)))
+	if tiles < 1 {
+		tiles = 1
+	}
+	columns := u.cfg.PreviewColumns
+	if tiles < columns {
+		columns = tiles
+	}
+	rows := (tiles + columns - 1) / columns
+
+	tileWidth := u.cfg.PreviewTileWidth
+	tileHeight := tileWidth * 9 / 16
+	if meta.Width > 0 && meta.Height > 0 {
+		tileHeight = int(math.Round(float64(tileWidth*meta.Height)/float64(meta.Width)/2)) * 2
+	}
+
+	if err := u.archiveUseCase.MakeSprites(ctx, inputPath, filepath.Join(dir, video.SpritesFile),
+		interval, tileWidth, tileHeight, columns, rows); err != nil {
+		return err
+	}
+
+	index := spritesIndex(duration, interval, tiles, columns, tileWidth, tileHeight)
+	return os.WriteFile(filepath.Join(dir, video.SpritesIndexFile), []byte(index), 0666)
+}
+
+func (u *VideoUseCase) previewDir(videoId int) string {
+	return filepath.Join(u.cfg.PreviewDir, strconv.Itoa(videoId))
+}
+
+// spritesIndex makes the WebVTT cues pointing to the tiles of the sprite
+// sheet, which is served next to the index.
+func spritesIndex(duration, interval time.Duration, tiles, columns, tileWidth, tileHeight int) string {
+	var b strings.Builder
+	b.WriteString("WEBVTT\n")
+	for i := 0; i < tiles; i++ {
+		start := time.Duration(i) * interval
+		end := start + interval
+		if end > duration {
+			end = duration
+		}
+		x := i % columns * tileWidth
+		y := i / columns * tileHeight
+		fmt.Fprintf(&b, "\n%s --> %s\n%s#xywh=%d,%d,%d,%d\n", vttTimestamp(start), vttTimestamp(end),
+			video.SpritesFile, x, y, tileWidth, tileHeight)
+	}
+	return b.String()
+}
+
+func vttTimestamp(d time.Duration) string {
+	ms := d.Milliseconds()
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", ms/3600000, ms/60000%60, ms/1000%60, ms%1000)
+}
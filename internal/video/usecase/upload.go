@@ -0,0 +1,250 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	msg "vhosting/internal/messages"
+	"vhosting/internal/video"
+	"vhosting/pkg/logger"
+	"vhosting/pkg/mediadir"
+	"vhosting/pkg/timedate"
+)
+
+const (
+	uploadCleanupPeriod = time.Hour
+	uploadPartSuffix    = ".part"
+)
+
+// ServeUploads deletes the uploads not completed within the expiration time
+// together with their partial files.
+func (u *VideoUseCase) ServeUploads() {
+	go func() {
+		for {
+			u.deleteExpiredUploads()
+			time.Sleep(uploadCleanupPeriod)
+		}
+	}()
+}
+
+// CreateUpload assigns the ID and the file path in the upload directory. The
+// extension of the client file name is kept, the name itself is not.
+func (u *VideoUseCase) CreateUpload(upl *video.Upload) error {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return err
+	}
+	upl.Id = hex.EncodeToString(id)
+	upl.File = filepath.ToSlash(filepath.Join(u.cfg.UploadDir, upl.Id+uploadExtension(upl.FileName)))
+	upl.Offset = 0
+
+	dir, err := mediadir.Resolve(u.cfg.MediaDir, u.cfg.UploadDir)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return err
+	}
+
+	return u.videoRepo.CreateUpload(upl)
+}
+
+// GetUpload returns nil if there is no upload with the ID.
+func (u *VideoUseCase) GetUpload(id string) (*video.Upload, error) {
+	return u.videoRepo.GetUpload(id)
+}
+
+// WriteUpload appends the body to the partial file from the upload offset.
+// The offset is saved even if the body is broken, so the client resumes
+// from the bytes received. The video is created when the file is complete.
+func (u *VideoUseCase) WriteUpload(upl *video.Upload, body io.Reader) error {
+	u.uploadMutex.Lock()
+	if u.uploading[upl.Id] {
+		u.uploadMutex.Unlock()
+		return video.ErrUploadIsLocked
+	}
+	u.uploading[upl.Id] = true
+	u.uploadMutex.Unlock()
+
+	defer func() {
+		u.uploadMutex.Lock()
+		delete(u.uploading, upl.Id)
+		u.uploadMutex.Unlock()
+	}()
+
+	// Another request may have written to the upload since it was read
+	saved, err := u.videoRepo.GetUpload(upl.Id)
+	if err != nil {
+		return err
+	}
+	if saved == nil || saved.Offset != upl.Offset {
+		return video.ErrUploadOffsetMismatch
+	}
+
+	if upl.Offset < upl.Length {
+		if err := u.writeUploadPart(upl, body); err != nil {
+			return err
+		}
+	}
+	if upl.Offset == upl.Length && upl.VideoId == 0 {
+		return u.completeUpload(upl)
+	}
+	return nil
+}
+
+// DeleteUpload deletes the partial file of the upload, the file of a
+// completed upload belongs to its video.
+func (u *VideoUseCase) DeleteUpload(upl *video.Upload) error {
+	if upl.VideoId == 0 {
+		path, err := u.uploadPartPath(upl)
+		if err != nil {
+			return err
+		}
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return u.videoRepo.DeleteUpload(upl.Id)
+}
+
+// UploadExpirationDate returns the time the upload is deleted at unless it
+// is written to, completed uploads do not expire.
+func (u *VideoUseCase) UploadExpirationDate(upl *video.Upload) (time.Time, bool) {
+	if upl.VideoId != 0 {
+		return time.Time{}, false
+	}
+	updated, err := time.Parse(time.RFC3339Nano, upl.UpdateDate)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return updated.Add(time.Duration(u.cfg.UploadExpirationHours) * time.Hour), true
+}
+
+// ParseUploadMetadata reads the Upload-Metadata header of tus, comma
+// separated keys with base64 encoded values. A value may be omitted.
+func (u *VideoUseCase) ParseUploadMetadata(header string) (map[string]string, error) {
+	meta := map[string]string{}
+	for _, pair := range strings.Split(header, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		key, encoded, _ := strings.Cut(pair, " ")
+		value, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+		if err != nil {
+			return nil, errors.New("cannot decode value of key " + key)
+		}
+		meta[key] = string(value)
+	}
+	return meta, nil
+}
+
+func (u *VideoUseCase) writeUploadPart(upl *video.Upload, body io.Reader) error {
+	path, err := u.uploadPartPath(upl)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE, 0666)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	// The bytes after the saved offset were never confirmed to the client
+	if err := file.Truncate(upl.Offset); err != nil {
+		return err
+	}
+	if _, err := file.Seek(upl.Offset, io.SeekStart); err != nil {
+		return err
+	}
+
+	written, copyErr := io.Copy(file, io.LimitReader(body, upl.Length-upl.Offset))
+	if err := file.Sync(); err != nil {
+		return err
+	}
+
+	upl.Offset += written
+	if err := u.videoRepo.UpdateUpload(upl); err != nil {
+		return err
+	}
+
+	return copyErr
+}
+
+// completeUpload moves the file in place and creates the video. It is
+// called again by the next request if the video could not be created.
+func (u *VideoUseCase) completeUpload(upl *video.Upload) error {
+	partPath, err := u.uploadPartPath(upl)
+	if err != nil {
+		return err
+	}
+	path, err := mediadir.Resolve(u.cfg.MediaDir, upl.File)
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(partPath, path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	vid := &video.Video{
+		Url:        upl.Url,
+		File:       upl.File,
+		CreateDate: timedate.GetTimestamp(),
+		InfoId:     upl.InfoId,
+		UserId:     upl.UserId,
+	}
+	if err := u.CreateVideo(vid); err != nil {
+		return err
+	}
+
+	upl.VideoId = vid.Id
+	return u.videoRepo.UpdateUpload(upl)
+}
+
+func (u *VideoUseCase) deleteExpiredUploads() {
+	before := time.Now().Add(-time.Duration(u.cfg.UploadExpirationHours) * time.Hour)
+	uploads, err := u.videoRepo.GetExpiredUploads(before)
+	if err != nil {
+		logger.Print(msg.ErrorCannotDeleteExpiredUploads(err))
+		return
+	}
+
+	deleted := 0
+	for _, upl := range uploads {
+		if err := u.DeleteUpload(upl); err != nil {
+			logger.Print(msg.ErrorCannotDeleteExpiredUploads(err))
+			continue
+		}
+		deleted++
+	}
+	if deleted > 0 {
+		logger.Print(msg.InfoExpiredUploadsDeleted(deleted))
+	}
+}
+
+func (u *VideoUseCase) uploadPartPath(upl *video.Upload) (string, error) {
+	return mediadir.Resolve(u.cfg.MediaDir, upl.File+uploadPartSuffix)
+}
+
+// uploadExtension returns the lower case extension of the file name if it is
+// a plain one, like ".mp4".
+func uploadExtension(fileName string) string {
+	ext := strings.ToLower(filepath.Ext(fileName))
+	if len(ext) < 2 || len(ext) > 10 {
+		return ""
+	}
+	for _, c := range ext[1:] {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') {
+			return ""
+		}
+	}
+	return ext
+}
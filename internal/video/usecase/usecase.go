@@ -1,41 +1,143 @@
 package usecase
 
 import (
+	"context"
+	"os"
 	"strconv"
+	"sync"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"vhosting/internal/video"
+	"vhosting/pkg/archive"
+	"vhosting/pkg/config"
+	"vhosting/pkg/mediadir"
+	"vhosting/pkg/timedate"
 	"vhosting/pkg/user"
 )
 
+const (
+	probeTimeout = time.Minute
+	queueSize    = 100
+)
+
 type VideoUseCase struct {
-	videoRepo video.VideoRepository
+	cfg            *config.Config
+	videoRepo      video.VideoRepository
+	archiveUseCase archive.ArchiveUseCase
+	queue          chan int
+
+	uploadMutex sync.Mutex
+	uploading   map[string]bool // uploads being written
 }
 
-func NewVideoUseCase(videoRepo video.VideoRepository) *VideoUseCase {
+func NewVideoUseCase(cfg *config.Config, videoRepo video.VideoRepository,
+	archiveUseCase archive.ArchiveUseCase) *VideoUseCase {
 	return &VideoUseCase{
-		videoRepo: videoRepo,
+		cfg:            cfg,
+		videoRepo:      videoRepo,
+		archiveUseCase: archiveUseCase,
+		queue:          make(chan int, queueSize),
+		uploading:      map[string]bool{},
 	}
 }
 
+// CreateVideo does not wait for the file to be probed and previewed, the
+// metadata and the preview state appear with the video when they are made.
 func (u *VideoUseCase) CreateVideo(nfo *video.Video) error {
-	return u.videoRepo.CreateVideo(nfo)
+	if err := u.videoRepo.CreateVideo(nfo); err != nil {
+		return err
+	}
+	u.queuePreview(nfo.Id)
+	return nil
 }
 
 func (u *VideoUseCase) GetVideo(id int) (*video.Video, error) {
-	return u.videoRepo.GetVideo(id)
+	vid, err := u.videoRepo.GetVideo(id)
+	if err != nil {
+		return nil, err
+	}
+	if vid.Metadata, err = u.videoRepo.GetMetadata(id); err != nil {
+		return nil, err
+	}
+	if vid.Preview, err = u.GetPreview(id); err != nil {
+		return nil, err
+	}
+	return vid, nil
 }
 
 func (u *VideoUseCase) GetAllVideos(urlparams *user.Pagin) (map[int]*video.Video, error) {
-	return u.videoRepo.GetAllVideos(urlparams)
+	videos, err := u.videoRepo.GetAllVideos(urlparams)
+	if err != nil || videos == nil {
+		return videos, err
+	}
+
+	ids := make([]int, 0, len(videos))
+	for id := range videos {
+		ids = append(ids, id)
+	}
+	metas, err := u.videoRepo.GetMetadatas(ids)
+	if err != nil {
+		return nil, err
+	}
+	previews, err := u.videoRepo.GetPreviews(ids)
+	if err != nil {
+		return nil, err
+	}
+	for id, vid := range videos {
+		vid.Metadata = metas[id]
+		vid.Preview = previews[id]
+	}
+
+	return videos, nil
 }
 
+// PartiallyUpdateVideo probes and previews the file again if it was changed.
 func (u *VideoUseCase) PartiallyUpdateVideo(nfo *video.Video) error {
-	return u.videoRepo.PartiallyUpdateVideo(nfo)
+	if err := u.videoRepo.PartiallyUpdateVideo(nfo); err != nil {
+		return err
+	}
+	if nfo.File != "" {
+		u.queuePreview(nfo.Id)
+	}
+	return nil
 }
 
 func (u *VideoUseCase) DeleteVideo(id int) error {
-	return u.videoRepo.DeleteVideo(id)
+	if err := u.videoRepo.DeleteVideo(id); err != nil {
+		return err
+	}
+	if err := u.videoRepo.DeleteMetadata(id); err != nil {
+		return err
+	}
+	if err := u.videoRepo.DeletePreview(id); err != nil {
+		return err
+	}
+	return os.RemoveAll(u.previewDir(id))
+}
+
+// ProbeVideo reads the metadata of the video file in the media directory
+// and stores it. A failed probe is stored too, with its error.
+func (u *VideoUseCase) ProbeVideo(vid *video.Video) (*video.Metadata, error) {
+	meta := &video.Metadata{VideoId: vid.Id, ProbeDate: timedate.GetTimestamp()}
+
+	var media *archive.Media
+	path, err := mediadir.Resolve(u.cfg.MediaDir, vid.File)
+	if err == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+		media, err = u.archiveUseCase.ProbeMedia(ctx, path)
+		cancel()
+	}
+	if err != nil {
+		meta.ProbeError = err.Error()
+	} else {
+		meta.Media = *media
+	}
+
+	if err := u.videoRepo.SetMetadata(meta); err != nil {
+		return nil, err
+	}
+	return meta, err
 }
 
 func (u *VideoUseCase) BindJSONVideo(ctx *gin.Context) (*video.Video, error) {
@@ -68,3 +170,8 @@ func (u *VideoUseCase) AtoiRequestedId(ctx *gin.Context) (int, error) {
 	}
 	return id, nil
 }
+
+// VideoPath returns the path of the video file in the media directory.
+func (u *VideoUseCase) VideoPath(vid *video.Video) (string, error) {
+	return mediadir.Resolve(u.cfg.MediaDir, vid.File)
+}
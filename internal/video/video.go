@@ -1,7 +1,12 @@
 package video
 
 import (
+	"errors"
+	"io"
+	"time"
+
 	"github.com/gin-gonic/gin"
+	"vhosting/pkg/archive"
 	"vhosting/pkg/user"
 )
 
@@ -12,6 +17,47 @@ type Video struct {
 	CreateDate string `json:"createDate" db:"create_date"`
 	InfoId     int    `json:"infoId"     db:"info_id"`
 	UserId     int    `json:"userId"     db:"user_id"`
+
+	Metadata *Metadata `json:"metadata" db:"-"`
+	Preview  *Preview  `json:"preview"  db:"-"`
+}
+
+// Metadata is probed from the video file, ProbeError is set when the last
+// probe failed.
+type Metadata struct {
+	VideoId int `json:"-" db:"video_id"`
+	archive.Media
+	ProbeError string `json:"probeError" db:"probe_error"`
+	ProbeDate  string `json:"probeDate"  db:"probe_date"`
+}
+
+// Preview tracks making the thumbnail and the sprites of the video file.
+type Preview struct {
+	VideoId    int    `json:"-"          db:"video_id"`
+	State      string `json:"state"      db:"state"`
+	Error      string `json:"error"      db:"error"`
+	UpdateDate string `json:"updateDate" db:"update_date"`
+}
+
+var (
+	ErrUploadIsLocked       = errors.New("upload is being written by another request")
+	ErrUploadOffsetMismatch = errors.New("upload offset does not match")
+)
+
+// Upload is a resumable upload of a video file to the media directory. The
+// video is created when Offset reaches Length.
+type Upload struct {
+	Id           string `json:"id"           db:"id"`
+	UserId       int    `json:"userId"       db:"user_id"`
+	InfoId       int    `json:"infoId"       db:"info_id"`
+	Url          string `json:"url"          db:"url"`
+	FileName     string `json:"fileName"     db:"file_name"` // name on the client
+	File         string `json:"file"         db:"file"`      // path in the media directory
+	Length       int64  `json:"length"       db:"length"`
+	Offset       int64  `json:"offset"       db:"upload_offset"`
+	VideoId      int    `json:"videoId"      db:"video_id"` // 0 until completed
+	CreationDate string `json:"creationDate" db:"creation_date"`
+	UpdateDate   string `json:"updateDate"   db:"update_date"`
 }
 
 type VideoCommon interface {
@@ -27,11 +73,40 @@ type VideoCommon interface {
 type VideoUseCase interface {
 	VideoCommon
 
+	ProbeVideo(vid *Video) (*Metadata, error)
+	VideoPath(vid *Video) (string, error)
+	ServePreviews()
+	GetPreview(videoId int) (*Preview, error)
+	PreviewPath(videoId int, name string) string
 	BindJSONVideo(ctx *gin.Context) (*Video, error)
 	IsRequiredEmpty(url, filename string) bool
 	AtoiRequestedId(ctx *gin.Context) (int, error)
+
+	ServeUploads()
+	CreateUpload(upl *Upload) error
+	GetUpload(id string) (*Upload, error)
+	WriteUpload(upl *Upload, body io.Reader) error
+	DeleteUpload(upl *Upload) error
+	UploadExpirationDate(upl *Upload) (time.Time, bool)
+	ParseUploadMetadata(header string) (map[string]string, error)
 }
 
 type VideoRepository interface {
 	VideoCommon
+
+	SetMetadata(meta *Metadata) error
+	GetMetadata(videoId int) (*Metadata, error)
+	GetMetadatas(videoIds []int) (map[int]*Metadata, error)
+	DeleteMetadata(videoId int) error
+
+	SetPreview(preview *Preview) error
+	GetPreviews(videoIds []int) (map[int]*Preview, error)
+	GetPreviewsByState(state string) ([]*Preview, error)
+	DeletePreview(videoId int) error
+
+	CreateUpload(upl *Upload) error
+	GetUpload(id string) (*Upload, error)
+	UpdateUpload(upl *Upload) error
+	DeleteUpload(id string) error
+	GetExpiredUploads(before time.Time) ([]*Upload, error)
 }
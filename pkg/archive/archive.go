@@ -0,0 +1,88 @@
+package archive
+
+import (
+	"context"
+	"time"
+)
+
+// Request is a row of the "RequestVideoArchive" table in the outer database.
+type Request struct {
+	Id             int    `db:"ID"`
+	CodeMP         string `db:"codeMP"`
+	StartDatetime  string `db:"startDatetime"`
+	DurationRecord int    `db:"durationRecord"` // minutes
+}
+
+// Job tracks the processing of an archive request in the local database.
+type Job struct {
+	Id         int    `json:"id"         db:"id"`
+	RequestId  int    `json:"requestId"  db:"request_id"`
+	State      string `json:"state"      db:"state"`
+	Attempts   int    `json:"attempts"   db:"attempts"`
+	LastError  string `json:"lastError"  db:"last_error"`
+	OutputPath string `json:"outputPath" db:"output_path"`
+	UpdateDate string `json:"updateDate" db:"update_date"`
+}
+
+// Record is a recorded video segment of the "VideoRecord" table in the outer
+// database. RecordTime is the wall clock time of the segment start.
+type Record struct {
+	Path       string
+	RecordTime time.Time
+}
+
+// Overlay is burned into the picture. Text may contain {time}, which is
+// replaced by the wall clock time of the frame.
+type Overlay struct {
+	Text     string
+	Position string
+	FontSize int
+	LogoPath string
+}
+
+// Media is the technical metadata probed from a video file. Duration is in
+// seconds, Bitrate in bits per second and Size in bytes.
+type Media struct {
+	Duration   float64 `json:"duration"   db:"duration"`
+	Container  string  `json:"container"  db:"container"`
+	VideoCodec string  `json:"videoCodec" db:"video_codec"`
+	AudioCodec string  `json:"audioCodec" db:"audio_codec"`
+	Width      int     `json:"width"      db:"width"`
+	Height     int     `json:"height"     db:"height"`
+	FrameRate  float64 `json:"frameRate"  db:"frame_rate"`
+	Bitrate    int64   `json:"bitrate"    db:"bitrate"`
+	Size       int64   `json:"size"       db:"size"`
+}
+
+type ArchiveCommon interface {
+	GetVideoPaths(pathStream, startDatetime string, durationMinutes int) ([]string, error)
+	GetVideoRecords(pathStream, startDatetime string, durationMinutes int) ([]*Record, error)
+}
+
+type ArchiveUseCase interface {
+	ArchiveCommon
+
+	RunWorker(ctx context.Context)
+	ConcatVideo(ctx context.Context, paths []string, listPath, outputPath string,
+		onProgress func(time.Duration)) error
+	TrimVideo(ctx context.Context, inputPath, outputPath string, start, end time.Duration,
+		onProgress func(time.Duration)) error
+	ProbeDuration(ctx context.Context, path string) (time.Duration, error)
+	ProbeMedia(ctx context.Context, path string) (*Media, error)
+	MakeThumbnail(ctx context.Context, inputPath, outputPath string, at time.Duration, width int) error
+	MakeSprites(ctx context.Context, inputPath, outputPath string, interval time.Duration,
+		tileWidth, tileHeight, columns, rows int) error
+	BurnOverlay(ctx context.Context, inputPath, outputPath string, overlay *Overlay, startTime time.Time,
+		onProgress func(time.Duration)) error
+}
+
+type ArchiveRepository interface {
+	ArchiveCommon
+
+	GetRequestsByStatus(status int) ([]*Request, error)
+	SetRecordStatus(requestId, status int) error
+
+	GetJob(requestId int) (*Job, error)
+	GetJobsByState(state string) ([]*Job, error)
+	SetJob(job *Job) error
+}
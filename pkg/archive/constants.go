@@ -0,0 +1,43 @@
+package archive
+
+const (
+	ReqTableName      = "\"RequestVideoArchive\""
+	ReqId             = "\"ID\""
+	ReqCodeMP         = "\"codeMP\""
+	ReqStartDatetime  = "\"startDatetime\""
+	ReqDurationRecord = "\"durationRecord\""
+	ReqRecordStatus   = "\"recordStatus\""
+
+	JobTableName  = "archive_jobs"
+	JobId         = "id"
+	JobRequestId  = "request_id"
+	JobState      = "state"
+	JobAttempts   = "attempts"
+	JobLastError  = "last_error"
+	JobOutputPath = "output_path"
+	JobUpdateDate = "update_date"
+)
+
+// Values of "recordStatus" written back to "RequestVideoArchive"
+const (
+	RecordStatusRequested = 1
+	RecordStatusRunning   = 2
+	RecordStatusDone      = 3
+	RecordStatusFailed    = 4
+)
+
+// Overlay positions, the logo is placed into the opposite corner of the
+// same side.
+const (
+	PositionTopLeft     = "top-left"
+	PositionTopRight    = "top-right"
+	PositionBottomLeft  = "bottom-left"
+	PositionBottomRight = "bottom-right"
+)
+
+const (
+	StateQueued  = "queued"
+	StateRunning = "running"
+	StateDone    = "done"
+	StateFailed  = "failed"
+)
@@ -0,0 +1,216 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"vhosting/internal/constants"
+	"vhosting/pkg/archive"
+	"vhosting/pkg/config"
+	qconsts "vhosting/pkg/constants/query"
+	"vhosting/pkg/db_connect"
+	"vhosting/pkg/timedate"
+)
+
+var jobColumns = fmt.Sprintf("%s, %s, %s, %s, %s, %s", archive.JobRequestId,
+	archive.JobState, archive.JobAttempts, archive.JobLastError,
+	archive.JobOutputPath, archive.JobUpdateDate)
+
+type ArchiveRepository struct {
+	cfg *config.Config
+}
+
+func NewArchiveRepository(cfg *config.Config) *ArchiveRepository {
+	return &ArchiveRepository{cfg: cfg}
+}
+
+func (r *ArchiveRepository) GetRequestsByStatus(status int) ([]*archive.Request, error) {
+	r.cfg.DBOName = constants.DBO_L3_Name
+	dbo := db_connect.CreateOuterDBConnection(r.cfg)
+	defer db_connect.CloseDBConnection(r.cfg, dbo)
+
+	template := qconsts.SELECT_COL_FROM_TBL_WHERE_CND
+	col := fmt.Sprintf("%s, %s, %s, %s", archive.ReqId, archive.ReqCodeMP,
+		archive.ReqStartDatetime, archive.ReqDurationRecord)
+	tbl := archive.ReqTableName
+	cnd := fmt.Sprintf("%s=$1", archive.ReqRecordStatus)
+	query := fmt.Sprintf(template, col, tbl, cnd)
+
+	rows, err := dbo.Query(query, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	requests := []*archive.Request{}
+	for rows.Next() {
+		var req archive.Request
+		if err := rows.Scan(&req.Id, &req.CodeMP, &req.StartDatetime,
+			&req.DurationRecord); err != nil {
+			return nil, err
+		}
+		requests = append(requests, &req)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return requests, nil
+}
+
+func (r *ArchiveRepository) SetRecordStatus(requestId, status int) error {
+	r.cfg.DBOName = constants.DBO_L3_Name
+	dbo := db_connect.CreateOuterDBConnection(r.cfg)
+	defer db_connect.CloseDBConnection(r.cfg, dbo)
+
+	template := qconsts.UPDATE_TBL_SET_VAL_WHERE_CND
+	tbl := archive.ReqTableName
+	val := fmt.Sprintf("%s=$1", archive.ReqRecordStatus)
+	cnd := fmt.Sprintf("%s=$2", archive.ReqId)
+	query := fmt.Sprintf(template, tbl, val, cnd)
+
+	rows, err := dbo.Query(query, status, requestId)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	return nil
+}
+
+func (r *ArchiveRepository) GetVideoPaths(pathStream, startDatetime string, durationMinutes int) ([]string, error) {
+	records, err := r.GetVideoRecords(pathStream, startDatetime, durationMinutes)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := []string{}
+	for _, rec := range records {
+		paths = append(paths, rec.Path)
+	}
+
+	return paths, nil
+}
+
+// GetVideoRecords returns the segments ordered by the start time. The
+// "recordTime" wall clock is read in the local time zone.
+func (r *ArchiveRepository) GetVideoRecords(pathStream, startDatetime string, durationMinutes int) ([]*archive.Record, error) {
+	r.cfg.DBOName = constants.DBO_L3_Name
+	dbo := db_connect.CreateOuterDBConnection(r.cfg)
+	defer db_connect.CloseDBConnection(r.cfg, dbo)
+
+	query := qconsts.SELECT_VIDEO_PATH_BETWEEN
+
+	rows, err := dbo.Query(query, pathStream, startDatetime, durationMinutes)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	records := []*archive.Record{}
+	for rows.Next() {
+		var rec archive.Record
+		var recordTime time.Time
+		if err := rows.Scan(&rec.Path, &recordTime); err != nil {
+			return nil, err
+		}
+		rec.RecordTime = time.Date(recordTime.Year(), recordTime.Month(), recordTime.Day(),
+			recordTime.Hour(), recordTime.Minute(), recordTime.Second(),
+			recordTime.Nanosecond(), time.Local)
+		records = append(records, &rec)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+func (r *ArchiveRepository) GetJob(requestId int) (*archive.Job, error) {
+	db := db_connect.CreateLocalDBConnection(r.cfg)
+	defer db_connect.CloseDBConnection(r.cfg, db)
+
+	template := qconsts.SELECT_COL_FROM_TBL_WHERE_CND
+	col := fmt.Sprintf("%s, %s", archive.JobId, jobColumns)
+	tbl := archive.JobTableName
+	cnd := fmt.Sprintf("%s=$1", archive.JobRequestId)
+	query := fmt.Sprintf(template, col, tbl, cnd)
+
+	rows, err := db.Query(query, requestId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, rows.Err()
+	}
+
+	var job archive.Job
+	if err := rows.Scan(&job.Id, &job.RequestId, &job.State, &job.Attempts,
+		&job.LastError, &job.OutputPath, &job.UpdateDate); err != nil {
+		return nil, err
+	}
+
+	return &job, nil
+}
+
+func (r *ArchiveRepository) GetJobsByState(state string) ([]*archive.Job, error) {
+	db := db_connect.CreateLocalDBConnection(r.cfg)
+	defer db_connect.CloseDBConnection(r.cfg, db)
+
+	template := qconsts.SELECT_COL_FROM_TBL_WHERE_CND
+	col := fmt.Sprintf("%s, %s", archive.JobId, jobColumns)
+	tbl := archive.JobTableName
+	cnd := fmt.Sprintf("%s=$1", archive.JobState)
+	query := fmt.Sprintf(template, col, tbl, cnd)
+
+	rows, err := db.Query(query, state)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	jobs := []*archive.Job{}
+	for rows.Next() {
+		var job archive.Job
+		if err := rows.Scan(&job.Id, &job.RequestId, &job.State, &job.Attempts,
+			&job.LastError, &job.OutputPath, &job.UpdateDate); err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, &job)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return jobs, nil
+}
+
+func (r *ArchiveRepository) SetJob(job *archive.Job) error {
+	db := db_connect.CreateLocalDBConnection(r.cfg)
+	defer db_connect.CloseDBConnection(r.cfg, db)
+
+	job.UpdateDate = timedate.GetTimestamp()
+
+	template := qconsts.UPSERT_INTO_TBL_VALUES_VAL_ON_COL
+	tbl := fmt.Sprintf("%s (%s)", archive.JobTableName, jobColumns)
+	val := "($1, $2, $3, $4, $5, $6)"
+	col := archive.JobRequestId
+	set := fmt.Sprintf("%s=$2, %s=$3, %s=$4, %s=$5, %s=$6", archive.JobState,
+		archive.JobAttempts, archive.JobLastError, archive.JobOutputPath,
+		archive.JobUpdateDate)
+	query := fmt.Sprintf(template, tbl, val, col, set)
+
+	rows, err := db.Query(query, job.RequestId, job.State, job.Attempts,
+		job.LastError, job.OutputPath, job.UpdateDate)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	return nil
+}
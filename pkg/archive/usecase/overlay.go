@@ -0,0 +1,74 @@
+package usecase
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"vhosting/pkg/archive"
+)
+
+const overlayMargin = "10"
+
+// BurnOverlay re-encodes the input with the overlay text and logo. The
+// frame time is counted from startTime, the wall clock time of the first
+// frame.
+func (u *ArchiveUseCase) BurnOverlay(ctx context.Context, inputPath, outputPath string, overlay *archive.Overlay,
+	startTime time.Time, onProgress func(time.Duration)) error {
+	// drawtext reads the text from a file to avoid escaping it for the
+	// filter graph, "%{...}" is still expanded there.
+	name := strings.TrimSuffix(filepath.Base(outputPath), filepath.Ext(outputPath))
+	textPath := filepath.Join(u.cfg.ArchiveTmpDir, name+"_overlay.txt")
+	if err := os.WriteFile(textPath, []byte(overlayText(overlay.Text, startTime)), 0666); err != nil {
+		return err
+	}
+	defer os.Remove(textPath)
+
+	textX, textY, logoX, logoY := overlayCoordinates(overlay.Position)
+	drawtext := "drawtext=textfile='" + textPath + "'" +
+		":fontsize=" + strconv.Itoa(overlay.FontSize) +
+		":fontcolor=white:box=1:boxcolor=black@0.5:boxborderw=5" +
+		":x=" + textX + ":y=" + textY
+	if u.cfg.ExportFontFile != "" {
+		drawtext += ":fontfile='" + u.cfg.ExportFontFile + "'"
+	}
+
+	args := []string{"-y", "-i", inputPath}
+	if overlay.LogoPath != "" {
+		args = append(args, "-i", overlay.LogoPath, "-filter_complex",
+			"[0:v]"+drawtext+"[t];[t][1:v]overlay=x="+logoX+":y="+logoY+"[v]", "-map", "[v]")
+	} else {
+		args = append(args, "-vf", drawtext, "-map", "0:v:0")
+	}
+	args = append(args, "-map", "0:a?", "-c:v", "libx264", "-preset", "veryfast", "-crf", "18",
+		"-pix_fmt", "yuv420p", "-c:a", "copy", outputPath)
+
+	return runFFmpeg(ctx, args, onProgress)
+}
+
+// overlayText escapes the text for drawtext expansion and replaces {time}
+// by the frame time function.
+func overlayText(text string, startTime time.Time) string {
+	escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`).Replace(text)
+	offset := strconv.FormatFloat(float64(startTime.UnixMilli())/1000, 'f', 3, 64)
+	frameTime := "%{pts:localtime:" + offset + `:%Y-%m-%d %H\:%M\:%S}`
+	return strings.ReplaceAll(escaped, "{time}", frameTime)
+}
+
+func overlayCoordinates(position string) (textX, textY, logoX, logoY string) {
+	left, right := overlayMargin, "W-w-"+overlayMargin
+	top, bottom := overlayMargin, "H-h-"+overlayMargin
+
+	switch position {
+	case archive.PositionTopLeft:
+		return left, top, right, top
+	case archive.PositionTopRight:
+		return "w-tw-" + overlayMargin, top, left, top
+	case archive.PositionBottomRight:
+		return "w-tw-" + overlayMargin, "h-th-" + overlayMargin, left, bottom
+	}
+	return left, "h-th-" + overlayMargin, right, bottom
+}
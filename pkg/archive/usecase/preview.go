@@ -0,0 +1,28 @@
+package usecase
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+// MakeThumbnail saves the frame at the given time scaled to the width.
+func (u *ArchiveUseCase) MakeThumbnail(ctx context.Context, inputPath, outputPath string,
+	at time.Duration, width int) error {
+	args := []string{"-y", "-ss", formatSeconds(at), "-i", inputPath,
+		"-frames:v", "1", "-vf", "scale=" + strconv.Itoa(width) + ":-2",
+		"-q:v", "3", outputPath}
+	return runFFmpeg(ctx, args, nil)
+}
+
+// MakeSprites tiles a frame of every interval into one image of the given
+// grid, tiles are scaled to the exact size for the index to address them.
+func (u *ArchiveUseCase) MakeSprites(ctx context.Context, inputPath, outputPath string,
+	interval time.Duration, tileWidth, tileHeight, columns, rows int) error {
+	filter := "fps=1/" + formatSeconds(interval) +
+		",scale=" + strconv.Itoa(tileWidth) + ":" + strconv.Itoa(tileHeight) +
+		",tile=" + strconv.Itoa(columns) + "x" + strconv.Itoa(rows)
+	args := []string{"-y", "-i", inputPath, "-vf", filter,
+		"-frames:v", "1", "-q:v", "5", outputPath}
+	return runFFmpeg(ctx, args, nil)
+}
@@ -0,0 +1,98 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"vhosting/pkg/archive"
+)
+
+type probeOutput struct {
+	Streams []struct {
+		CodecType    string `json:"codec_type"`
+		CodecName    string `json:"codec_name"`
+		Width        int    `json:"width"`
+		Height       int    `json:"height"`
+		AvgFrameRate string `json:"avg_frame_rate"`
+		RFrameRate   string `json:"r_frame_rate"`
+	} `json:"streams"`
+	Format struct {
+		FormatName string `json:"format_name"`
+		Duration   string `json:"duration"`
+		BitRate    string `json:"bit_rate"`
+		Size       string `json:"size"`
+	} `json:"format"`
+}
+
+// ProbeMedia reads the container and the first video and audio streams of
+// the file. Values ffprobe cannot tell are left zero.
+func (u *ArchiveUseCase) ProbeMedia(ctx context.Context, path string) (*archive.Media, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := runFFprobe(ctx, "-show_entries",
+		"format=format_name,duration,bit_rate,size:stream=codec_type,codec_name,width,height,avg_frame_rate,r_frame_rate",
+		"-of", "json", path)
+	if err != nil {
+		return nil, err
+	}
+
+	var probe probeOutput
+	if err := json.Unmarshal([]byte(out), &probe); err != nil {
+		return nil, err
+	}
+
+	media := &archive.Media{
+		Container: probe.Format.FormatName,
+		Size:      info.Size(),
+	}
+	if d, err := strconv.ParseFloat(probe.Format.Duration, 64); err == nil {
+		media.Duration = math.Round(d*1000) / 1000
+	}
+	if b, err := strconv.ParseInt(probe.Format.BitRate, 10, 64); err == nil {
+		media.Bitrate = b
+	}
+
+	for _, stream := range probe.Streams {
+		switch {
+		case stream.CodecType == "video" && media.VideoCodec == "":
+			media.VideoCodec = stream.CodecName
+			media.Width = stream.Width
+			media.Height = stream.Height
+			if media.FrameRate = parseFrameRate(stream.AvgFrameRate); media.FrameRate == 0 {
+				media.FrameRate = parseFrameRate(stream.RFrameRate)
+			}
+		case stream.CodecType == "audio" && media.AudioCodec == "":
+			media.AudioCodec = stream.CodecName
+		}
+	}
+	if media.VideoCodec == "" && media.AudioCodec == "" {
+		return nil, errors.New("no video or audio stream in " + path)
+	}
+
+	return media, nil
+}
+
+// parseFrameRate reads the "30000/1001" form, "0/0" means unknown.
+func parseFrameRate(rate string) float64 {
+	num, den, ok := strings.Cut(rate, "/")
+	if !ok {
+		return 0
+	}
+	n, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return 0
+	}
+	d, err := strconv.ParseFloat(den, 64)
+	if err != nil || d == 0 {
+		return 0
+	}
+	return math.Round(n/d*1000) / 1000
+}
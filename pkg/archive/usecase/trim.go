@@ -0,0 +1,251 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// trimPart is a range of the input which is copied or re-encoded.
+type trimPart struct {
+	start, end time.Duration
+	copy       bool
+}
+
+// videoParams are used to re-encode the parts the same way the camera did,
+// so the parts can be joined without re-encoding.
+type videoParams struct {
+	codec     string
+	profile   string
+	pixFmt    string
+	timescale string
+}
+
+// TrimVideo cuts [start, end) of the input into the output. Only the parts
+// before the first and after the last keyframe of the range are re-encoded,
+// the keyframe-aligned middle is copied as is.
+func (u *ArchiveUseCase) TrimVideo(ctx context.Context, inputPath, outputPath string, start, end time.Duration,
+	onProgress func(time.Duration)) error {
+	if end <= start {
+		return errors.New("trim range is empty")
+	}
+
+	params, err := probeVideoParams(ctx, inputPath)
+	if err != nil {
+		return err
+	}
+	keyframes, err := probeKeyframes(ctx, inputPath, start, end)
+	if err != nil {
+		return err
+	}
+	parts := planTrimParts(start, end, keyframes, params.isSplittable())
+
+	if len(parts) == 1 {
+		return runFFmpeg(ctx, trimArgs(inputPath, outputPath, parts[0], params), onProgress)
+	}
+
+	name := strings.TrimSuffix(filepath.Base(outputPath), filepath.Ext(outputPath))
+	partPaths := make([]string, 0, len(parts))
+	defer func() {
+		for _, path := range partPaths {
+			os.Remove(path)
+		}
+	}()
+
+	var done time.Duration
+	for i, part := range parts {
+		// MPEG-TS keeps the parameter sets of every part in band
+		path := filepath.Join(u.cfg.ArchiveTmpDir, fmt.Sprintf("%s_part%d.ts", name, i))
+		partPaths = append(partPaths, path)
+
+		var partProgress func(time.Duration)
+		if onProgress != nil {
+			offset := done
+			partProgress = func(d time.Duration) { onProgress(offset + d) }
+		}
+		if err := runFFmpeg(ctx, trimArgs(inputPath, path, part, params), partProgress); err != nil {
+			return err
+		}
+		done += part.end - part.start
+	}
+
+	listPath := filepath.Join(u.cfg.ArchiveTmpDir, name+"_parts.txt")
+	return u.ConcatVideo(ctx, partPaths, listPath, outputPath, nil)
+}
+
+// ProbeDuration returns the container duration of the video.
+func (u *ArchiveUseCase) ProbeDuration(ctx context.Context, path string) (time.Duration, error) {
+	out, err := runFFprobe(ctx, "-show_entries", "format=duration", "-of", "csv=p=0", path)
+	if err != nil {
+		return 0, err
+	}
+	return parseSeconds(strings.TrimSpace(out))
+}
+
+// planTrimParts splits the range by the first and the last keyframe inside
+// it. The range is re-encoded entirely if there is no whole GOP in it or the
+// re-encoded parts cannot be joined with the copied one.
+func planTrimParts(start, end time.Duration, keyframes []time.Duration, splittable bool) []trimPart {
+	first, last := time.Duration(-1), time.Duration(-1)
+	for _, k := range keyframes {
+		if k < start || k > end {
+			continue
+		}
+		if first < 0 {
+			first = k
+		}
+		last = k
+	}
+
+	if !splittable || first < 0 || first == last {
+		return []trimPart{{start: start, end: end}}
+	}
+
+	parts := []trimPart{}
+	if first > start {
+		parts = append(parts, trimPart{start: start, end: first})
+	}
+	parts = append(parts, trimPart{start: first, end: last, copy: true})
+	if end > last {
+		parts = append(parts, trimPart{start: last, end: end})
+	}
+	return parts
+}
+
+func trimArgs(inputPath, outputPath string, part trimPart, params *videoParams) []string {
+	args := []string{"-y", "-ss", formatSeconds(part.start), "-i", inputPath,
+		"-t", formatSeconds(part.end - part.start), "-map", "0:v:0", "-map", "0:a?"}
+
+	if part.copy {
+		args = append(args, "-c", "copy", "-avoid_negative_ts", "make_zero")
+	} else {
+		args = append(args, "-c:v", params.encoder(), "-preset", "veryfast", "-crf", "18",
+			"-c:a", "copy")
+		if params.isSplittable() && params.pixFmt != "" {
+			args = append(args, "-pix_fmt", params.pixFmt)
+		}
+		if profile := params.encoderProfile(); profile != "" {
+			args = append(args, "-profile:v", profile)
+		}
+	}
+	if params.timescale != "" && filepath.Ext(outputPath) == ".mp4" {
+		args = append(args, "-video_track_timescale", params.timescale)
+	}
+
+	return append(args, outputPath)
+}
+
+func (p *videoParams) isSplittable() bool {
+	return p.codec == "h264" || p.codec == "hevc"
+}
+
+// encoder keeps the camera codec, other codecs are converted to H.264.
+func (p *videoParams) encoder() string {
+	if p.codec == "hevc" {
+		return "libx265"
+	}
+	return "libx264"
+}
+
+// encoderProfile maps ffprobe profile names, e.g. "Constrained Baseline",
+// to the encoder ones.
+func (p *videoParams) encoderProfile() string {
+	profile := strings.ToLower(p.profile)
+	switch {
+	case p.codec == "h264" && strings.HasSuffix(profile, "baseline"):
+		return "baseline"
+	case p.codec == "h264" && (profile == "main" || profile == "high"):
+		return profile
+	case p.codec == "hevc" && profile == "main":
+		return profile
+	}
+	return ""
+}
+
+func probeVideoParams(ctx context.Context, path string) (*videoParams, error) {
+	out, err := runFFprobe(ctx, "-select_streams", "v:0",
+		"-show_entries", "stream=codec_name,profile,pix_fmt,time_base", "-of", "default=nw=1", path)
+	if err != nil {
+		return nil, err
+	}
+
+	params := &videoParams{}
+	for _, line := range strings.Split(out, "\n") {
+		key, val, _ := strings.Cut(strings.TrimSpace(line), "=")
+		switch key {
+		case "codec_name":
+			params.codec = val
+		case "profile":
+			params.profile = val
+		case "pix_fmt":
+			params.pixFmt = val
+		case "time_base":
+			if _, den, ok := strings.Cut(val, "/"); ok {
+				params.timescale = den
+			}
+		}
+	}
+	if params.codec == "" {
+		return nil, errors.New("no video stream in " + path)
+	}
+
+	return params, nil
+}
+
+// probeKeyframes returns the keyframe times of the first video stream
+// around [start, end].
+func probeKeyframes(ctx context.Context, path string, start, end time.Duration) ([]time.Duration, error) {
+	out, err := runFFprobe(ctx, "-select_streams", "v:0",
+		"-read_intervals", formatSeconds(start)+"%"+formatSeconds(end),
+		"-show_entries", "packet=pts_time,flags", "-of", "csv=p=0", path)
+	if err != nil {
+		return nil, err
+	}
+
+	keyframes := []time.Duration{}
+	for _, line := range strings.Split(out, "\n") {
+		ptsTime, flags, ok := strings.Cut(strings.TrimSpace(line), ",")
+		if !ok || !strings.Contains(flags, "K") {
+			continue
+		}
+		if k, err := parseSeconds(ptsTime); err == nil {
+			keyframes = append(keyframes, k)
+		}
+	}
+
+	return keyframes, nil
+}
+
+func runFFprobe(ctx context.Context, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "ffprobe", append([]string{"-v", "error"}, args...)...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		tail := stderr.Bytes()
+		if len(tail) > ffmpegErrorTailBytes {
+			tail = tail[len(tail)-ffmpegErrorTailBytes:]
+		}
+		return "", fmt.Errorf("ffprobe: %v: %s", err, bytes.TrimSpace(tail))
+	}
+	return stdout.String(), nil
+}
+
+func formatSeconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', 6, 64)
+}
+
+func parseSeconds(s string) (time.Duration, error) {
+	sec, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(sec * float64(time.Second)), nil
+}
@@ -0,0 +1,319 @@
+package usecase
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	msg "vhosting/internal/messages"
+	"vhosting/pkg/archive"
+	"vhosting/pkg/config"
+	"vhosting/pkg/delivery"
+	"vhosting/pkg/logger"
+)
+
+const ffmpegErrorTailBytes = 300
+
+type ArchiveUseCase struct {
+	cfg             *config.Config
+	archiveRepo     archive.ArchiveRepository
+	deliveryUseCase delivery.DeliveryUseCase
+	inFlightMu      sync.Mutex
+	inFlight        map[int]bool
+	deliveries      sync.WaitGroup
+}
+
+func NewArchiveUseCase(cfg *config.Config, archiveRepo archive.ArchiveRepository,
+	deliveryUseCase delivery.DeliveryUseCase) *ArchiveUseCase {
+	return &ArchiveUseCase{
+		cfg:             cfg,
+		archiveRepo:     archiveRepo,
+		deliveryUseCase: deliveryUseCase,
+		inFlight:        map[int]bool{},
+	}
+}
+
+// RunWorker polls requested archives and concatenates them by a bounded
+// pool of ffmpeg jobs until the context is cancelled. Failed jobs are
+// requested again until the attempts are over. Finished videos are sent to
+// the delivery targets.
+func (u *ArchiveUseCase) RunWorker(ctx context.Context) {
+	for _, dir := range []string{u.cfg.ArchiveTmpDir, u.cfg.ArchiveOutputDir} {
+		if err := os.MkdirAll(dir, 0777); err != nil {
+			logger.Print(msg.ErrorCannotCreateArchiveDir(err))
+			return
+		}
+	}
+
+	u.requeueInterruptedJobs()
+
+	u.deliveries.Add(1)
+	go func() {
+		defer u.deliveries.Done()
+		u.deliveryUseCase.ResumeDeliveries(ctx, delivery.SourceArchive)
+	}()
+
+	queue := make(chan *archive.Request, u.cfg.ArchiveWorkers)
+	var wg sync.WaitGroup
+	for i := 0; i < u.cfg.ArchiveWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for req := range queue {
+				u.processJob(ctx, req)
+			}
+		}()
+	}
+
+	logger.Print(msg.InfoArchiveWorkerStarted(u.cfg.ArchiveWorkers, u.cfg.ArchivePollIntervalSeconds))
+
+	ticker := time.NewTicker(time.Duration(u.cfg.ArchivePollIntervalSeconds) * time.Second)
+	defer ticker.Stop()
+	for {
+		u.poll(ctx, queue)
+		select {
+		case <-ctx.Done():
+			close(queue)
+			wg.Wait()
+			u.deliveries.Wait()
+			logger.Print(msg.InfoArchiveWorkerStopped())
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (u *ArchiveUseCase) GetVideoPaths(pathStream, startDatetime string, durationMinutes int) ([]string, error) {
+	return u.archiveRepo.GetVideoPaths(pathStream, startDatetime, durationMinutes)
+}
+
+func (u *ArchiveUseCase) GetVideoRecords(pathStream, startDatetime string, durationMinutes int) ([]*archive.Record, error) {
+	return u.archiveRepo.GetVideoRecords(pathStream, startDatetime, durationMinutes)
+}
+
+// ConcatVideo writes the paths into the list file and joins the videos into
+// the output file without re-encoding. The list file is removed afterwards.
+// onProgress, if set, receives the duration of the already written output.
+func (u *ArchiveUseCase) ConcatVideo(ctx context.Context, paths []string, listPath, outputPath string,
+	onProgress func(time.Duration)) error {
+	if len(paths) == 0 {
+		return errors.New("no video records in requested period")
+	}
+
+	var list bytes.Buffer
+	for _, path := range paths {
+		list.WriteString("file '" + path + "'\n")
+	}
+	if err := os.WriteFile(listPath, list.Bytes(), 0666); err != nil {
+		return err
+	}
+	defer os.Remove(listPath)
+
+	return runFFmpeg(ctx, []string{"-y", "-f", "concat", "-safe", "0", "-i", listPath,
+		"-c", "copy", outputPath}, onProgress)
+}
+
+// runFFmpeg runs ffmpeg with the args, the output file must be the last one.
+// The tail of stderr is added to the error.
+func runFFmpeg(ctx context.Context, args []string, onProgress func(time.Duration)) error {
+	if onProgress != nil {
+		last := len(args) - 1
+		args = append(args[:last:last], "-progress", "pipe:1", "-nostats", args[last])
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "ffmpeg", args...)
+	cmd.Stderr = &stderr
+	var stdout io.ReadCloser
+	if onProgress != nil {
+		var err error
+		if stdout, err = cmd.StdoutPipe(); err != nil {
+			return err
+		}
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	if stdout != nil {
+		readProgress(stdout, onProgress)
+	}
+	if err := cmd.Wait(); err != nil {
+		tail := stderr.Bytes()
+		if len(tail) > ffmpegErrorTailBytes {
+			tail = tail[len(tail)-ffmpegErrorTailBytes:]
+		}
+		return fmt.Errorf("ffmpeg: %v: %s", err, bytes.TrimSpace(tail))
+	}
+
+	return nil
+}
+
+// readProgress parses "out_time_us=" lines of the ffmpeg progress output.
+func readProgress(r io.Reader, onProgress func(time.Duration)) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		val := strings.TrimPrefix(scanner.Text(), "out_time_us=")
+		if val == scanner.Text() {
+			continue
+		}
+		if us, err := strconv.ParseInt(val, 10, 64); err == nil && us >= 0 {
+			onProgress(time.Duration(us) * time.Microsecond)
+		}
+	}
+}
+
+// poll queues the requested archives which are not processed at the moment.
+// A full queue is left for the next poll.
+func (u *ArchiveUseCase) poll(ctx context.Context, queue chan<- *archive.Request) {
+	defer u.recoverPanic()
+
+	requests, err := u.archiveRepo.GetRequestsByStatus(archive.RecordStatusRequested)
+	if err != nil {
+		logger.Print(msg.ErrorCannotGetRequestedArchives(err))
+		return
+	}
+
+	for _, req := range requests {
+		if ctx.Err() != nil || !u.markInFlight(req.Id) {
+			continue
+		}
+		select {
+		case queue <- req:
+			logger.Print(msg.InfoArchiveJobQueued(req.Id))
+		default:
+			u.unmarkInFlight(req.Id)
+		}
+	}
+}
+
+func (u *ArchiveUseCase) processJob(ctx context.Context, req *archive.Request) {
+	defer u.unmarkInFlight(req.Id)
+	defer u.recoverPanic()
+
+	job, err := u.archiveRepo.GetJob(req.Id)
+	if err != nil {
+		logger.Print(msg.ErrorCannotGetArchiveJob(err))
+		return
+	}
+	// Request made again after the final state starts from scratch
+	if job == nil || job.State == archive.StateDone || job.State == archive.StateFailed {
+		job = &archive.Job{RequestId: req.Id}
+	}
+
+	job.State = archive.StateRunning
+	job.Attempts++
+	if !u.saveJob(job, archive.RecordStatusRunning) {
+		return
+	}
+	logger.Print(msg.InfoArchiveJobStarted(req.Id, job.Attempts))
+
+	outputPath := filepath.Join(u.cfg.ArchiveOutputDir, fmt.Sprintf("%d_%s.mp4", req.Id, req.CodeMP))
+	listPath := filepath.Join(u.cfg.ArchiveTmpDir, fmt.Sprintf("%d_%s.txt", req.Id, req.CodeMP))
+
+	paths, err := u.GetVideoPaths(req.CodeMP, req.StartDatetime, req.DurationRecord)
+	if err == nil {
+		err = u.ConcatVideo(ctx, paths, listPath, outputPath, nil)
+	}
+
+	switch {
+	case err == nil:
+		job.State = archive.StateDone
+		job.LastError = ""
+		job.OutputPath = outputPath
+		if u.saveJob(job, archive.RecordStatusDone) {
+			logger.Print(msg.InfoArchiveJobDone(req.Id, outputPath))
+			u.deliver(ctx, req.Id, outputPath)
+		}
+	case ctx.Err() != nil:
+		// Shutdown interrupted the job, it is not counted as an attempt
+		job.State = archive.StateQueued
+		job.Attempts--
+		u.saveJob(job, archive.RecordStatusRequested)
+	case job.Attempts < u.cfg.ArchiveMaxAttempts:
+		job.State = archive.StateQueued
+		job.LastError = err.Error()
+		logger.Print(msg.WarningArchiveJobAttemptFailed(req.Id, job.Attempts, err))
+		u.saveJob(job, archive.RecordStatusRequested)
+	default:
+		job.State = archive.StateFailed
+		job.LastError = err.Error()
+		logger.Print(msg.ErrorArchiveJobFailed(req.Id, err))
+		u.saveJob(job, archive.RecordStatusFailed)
+	}
+}
+
+// deliver sends the video in background, the worker waits for unfinished
+// deliveries on stop.
+func (u *ArchiveUseCase) deliver(ctx context.Context, requestId int, outputPath string) {
+	u.deliveries.Add(1)
+	go func() {
+		defer u.deliveries.Done()
+		u.deliveryUseCase.Deliver(ctx, &delivery.File{
+			Source:   delivery.SourceArchive,
+			SourceId: requestId,
+			Path:     outputPath,
+		})
+	}()
+}
+
+// requeueInterruptedJobs requests again the jobs left running by a stopped
+// worker.
+func (u *ArchiveUseCase) requeueInterruptedJobs() {
+	defer u.recoverPanic()
+
+	jobs, err := u.archiveRepo.GetJobsByState(archive.StateRunning)
+	if err != nil {
+		logger.Print(msg.ErrorCannotGetArchiveJob(err))
+		return
+	}
+	for _, job := range jobs {
+		job.State = archive.StateQueued
+		u.saveJob(job, archive.RecordStatusRequested)
+	}
+}
+
+func (u *ArchiveUseCase) saveJob(job *archive.Job, recordStatus int) bool {
+	if err := u.archiveRepo.SetJob(job); err != nil {
+		logger.Print(msg.ErrorCannotSetArchiveJob(err))
+		return false
+	}
+	if err := u.archiveRepo.SetRecordStatus(job.RequestId, recordStatus); err != nil {
+		logger.Print(msg.ErrorCannotSetArchiveRecordStatus(err))
+		return false
+	}
+	return true
+}
+
+func (u *ArchiveUseCase) markInFlight(requestId int) bool {
+	u.inFlightMu.Lock()
+	defer u.inFlightMu.Unlock()
+	if u.inFlight[requestId] {
+		return false
+	}
+	u.inFlight[requestId] = true
+	return true
+}
+
+func (u *ArchiveUseCase) unmarkInFlight(requestId int) {
+	u.inFlightMu.Lock()
+	defer u.inFlightMu.Unlock()
+	delete(u.inFlight, requestId)
+}
+
+// recoverPanic keeps the worker running when a poll or a job panics,
+// e.g. on a lost database connection.
+func (u *ArchiveUseCase) recoverPanic() {
+	if r := recover(); r != nil {
+		logger.Print(msg.ErrorArchiveWorkerPanic(r))
+	}
+}
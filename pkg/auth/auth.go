@@ -14,7 +14,7 @@ type Namepass struct {
 type AuthCommon interface {
 	GetNamepass(namepass *Namepass) error
 	UpdateUserPassword(namepass *Namepass) error
-	IsUsernameAndPasswordExists(username, passwordHash string) (bool, error)
+	GetPasswordHash(username string) (string, error)
 }
 
 type AuthUseCase interface {
@@ -26,8 +26,9 @@ type AuthUseCase interface {
 	IsRequiredEmpty(namepass *Namepass) bool
 	IsSessionExists(session *sess.Session) bool
 	BindJSONNamepass(ctx *gin.Context) (*Namepass, error)
-	GenerateToken(namepass *Namepass) (string, error)
-	ParseToken(token string) (*Namepass, error)
+	BindJSONCredentials(ctx *gin.Context) (*Namepass, error)
+	IsPasswordMatched(password, passwordHash string) (bool, bool)
+	UpgradePasswordHash(namepass *Namepass) error
 }
 
 type AuthRepository interface {
@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"errors"
+
 	"github.com/gin-gonic/gin"
 	msg "vhosting/internal/messages"
 	sess "vhosting/internal/session"
@@ -42,7 +44,7 @@ func (h *AuthHandler) SignIn(ctx *gin.Context) {
 		}
 	}
 
-	inputNamepass, err := h.useCase.BindJSONNamepass(ctx)
+	inputNamepass, err := h.useCase.BindJSONCredentials(ctx)
 	if err != nil {
 		h.logUseCase.Report(ctx, log, msg.ErrorCannotBindInputData(err))
 		return
@@ -53,47 +55,84 @@ func (h *AuthHandler) SignIn(ctx *gin.Context) {
 		return
 	}
 
-	exists, err := h.useCase.IsUsernameAndPasswordExists(inputNamepass.Username, inputNamepass.PasswordHash)
+	passwordHash, err := h.useCase.GetPasswordHash(inputNamepass.Username)
 	if err != nil {
 		h.logUseCase.Report(ctx, log, msg.ErrorCannotCheckUserExistence(err))
 		return
 	}
-	if !exists {
+	matched, outdated := h.useCase.IsPasswordMatched(inputNamepass.PasswordHash, passwordHash)
+	if !matched {
 		h.logUseCase.Report(ctx, log, msg.ErrorUserWithEnteredUsernameOrPasswordIsNotExist())
 		return
 	}
 
 	log.SessionOwner = inputNamepass.Username
 
-	newToken, err := h.useCase.GenerateToken(inputNamepass)
+	// Hashes made by older versions are replaced while the password is known.
+	if outdated {
+		if err := h.useCase.UpgradePasswordHash(inputNamepass); err != nil {
+			h.logUseCase.Report(ctx, log, msg.ErrorCannotUpdateUserPassword(err))
+			return
+		}
+	}
+
+	userId, err := h.userUseCase.GetUserId(inputNamepass.Username)
 	if err != nil {
-		h.logUseCase.Report(ctx, log, msg.ErrorCannotGenerateToken(err))
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotCheckUserExistence(err))
 		return
 	}
 
-	if err := h.sessUseCase.CreateSession(ctx, inputNamepass.Username, newToken, log.CreationDate); err != nil {
+	newToken, refreshToken, err := h.sessUseCase.CreateSession(ctx, userId, inputNamepass.Username, log.CreationDate)
+	if err != nil {
 		h.logUseCase.Report(ctx, log, msg.ErrorCannotCreateSession(err))
 		return
 	}
 
-	h.logUseCase.ReportWithToken(ctx, log, msg.InfoYouHaveSuccessfullySignedIn(), newToken)
+	h.logUseCase.ReportWithToken(ctx, log, msg.InfoYouHaveSuccessfullySignedIn(), newToken, refreshToken)
 }
 
-func (h *AuthHandler) ChangePassword(ctx *gin.Context) {
+func (h *AuthHandler) Refresh(ctx *gin.Context) {
 	log := logger.Init(ctx)
 
-	session, err := h.getValidSessionAndDeleteSession(ctx, log)
+	req, err := h.sessUseCase.BindJSONRefreshRequest(ctx)
 	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotBindInputData(err))
 		return
 	}
-	if session == nil {
-		h.logUseCase.Report(ctx, log, msg.ErrorYouMustBeSignedInForChangingPassword())
+	if req.RefreshToken == "" {
+		h.logUseCase.Report(ctx, log, msg.ErrorRefreshTokenCannotBeEmpty())
 		return
 	}
 
-	sessionNamepass, err := h.useCase.ParseToken(session.Content)
+	newToken, refreshToken, err := h.sessUseCase.RefreshSession(req.RefreshToken)
+	switch {
+	case err == nil:
+	case errors.Is(err, sess.ErrRefreshTokenInvalid):
+		h.logUseCase.Report(ctx, log, msg.ErrorRefreshTokenIsInvalid())
+		return
+	case errors.Is(err, sess.ErrRefreshTokenReused):
+		h.logUseCase.Report(ctx, log, msg.ErrorRefreshTokenIsReused())
+		return
+	case errors.Is(err, sess.ErrRefreshTokenExpired):
+		h.logUseCase.Report(ctx, log, msg.ErrorRefreshTokenIsExpired())
+		return
+	default:
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotRefreshSession(err))
+		return
+	}
+
+	h.logUseCase.ReportWithToken(ctx, log, msg.InfoSessionRefreshed(), newToken, refreshToken)
+}
+
+func (h *AuthHandler) ChangePassword(ctx *gin.Context) {
+	log := logger.Init(ctx)
+
+	session, err := h.getValidSessionAndDeleteSession(ctx, log)
 	if err != nil {
-		h.logUseCase.Report(ctx, log, msg.ErrorCannotParseToken(err))
+		return
+	}
+	if session == nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorYouMustBeSignedInForChangingPassword())
 		return
 	}
 
@@ -108,7 +147,7 @@ func (h *AuthHandler) ChangePassword(ctx *gin.Context) {
 		return
 	}
 
-	inputNamepass.Username = sessionNamepass.Username
+	inputNamepass.Username = session.Username
 
 	exists, err := h.userUseCase.IsUserExists(inputNamepass.Username)
 	if err != nil {
@@ -120,13 +159,18 @@ func (h *AuthHandler) ChangePassword(ctx *gin.Context) {
 		return
 	}
 
-	log.SessionOwner = sessionNamepass.Username
+	log.SessionOwner = session.Username
 
 	if err := h.useCase.UpdateUserPassword(inputNamepass); err != nil {
 		h.logUseCase.Report(ctx, log, msg.ErrorCannotUpdateUserPassword(err))
 		return
 	}
 
+	if err := h.sessUseCase.RevokeUserSessions(session.UserId, h.useCase.ReadHeader(ctx)); err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotRevokeSessions(err))
+		return
+	}
+
 	h.logUseCase.Report(ctx, log, msg.InfoYouHaveSuccessfullyChangedPassword())
 }
 
@@ -142,13 +186,7 @@ func (h *AuthHandler) SignOut(ctx *gin.Context) {
 		return
 	}
 
-	sessionNamepass, err := h.useCase.ParseToken(session.Content)
-	if err != nil {
-		h.logUseCase.Report(ctx, log, msg.ErrorCannotParseToken(err))
-		return
-	}
-
-	exists, err := h.userUseCase.IsUserExists(sessionNamepass.Username)
+	exists, err := h.userUseCase.IsUserExists(session.Username)
 	if err != nil {
 		h.logUseCase.Report(ctx, log, msg.ErrorCannotCheckUserExistence(err))
 		return
@@ -158,23 +196,131 @@ func (h *AuthHandler) SignOut(ctx *gin.Context) {
 		return
 	}
 
-	log.SessionOwner = sessionNamepass.Username
+	log.SessionOwner = session.Username
 
 	h.logUseCase.Report(ctx, log, msg.InfoYouHaveSuccessfullySignedOut())
 }
 
+func (h *AuthHandler) SignOutAll(ctx *gin.Context) {
+	log := logger.Init(ctx)
+
+	session, err := h.getValidSession(ctx, log)
+	if err != nil {
+		return
+	}
+	if session == nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorYouMustBeSignedInForSigningOut())
+		return
+	}
+
+	log.SessionOwner = session.Username
+
+	if err := h.sessUseCase.RevokeUserSessions(session.UserId, ""); err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotRevokeSessions(err))
+		return
+	}
+
+	h.logUseCase.Report(ctx, log, msg.InfoYouHaveSuccessfullySignedOutEverywhere())
+}
+
+func (h *AuthHandler) GetSessions(ctx *gin.Context) {
+	log := logger.Init(ctx)
+
+	session, err := h.getValidSession(ctx, log)
+	if err != nil {
+		return
+	}
+	if session == nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorYouMustBeSignedInForManagingSessions())
+		return
+	}
+
+	log.SessionOwner = session.Username
+
+	sessions, err := h.sessUseCase.GetUserSessions(session.UserId, h.useCase.ReadHeader(ctx))
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotGetSessions(err))
+		return
+	}
+
+	h.logUseCase.Report(ctx, log, msg.InfoGotSessions(sessions))
+}
+
+func (h *AuthHandler) DeleteSession(ctx *gin.Context) {
+	log := logger.Init(ctx)
+
+	session, err := h.getValidSession(ctx, log)
+	if err != nil {
+		return
+	}
+	if session == nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorYouMustBeSignedInForManagingSessions())
+		return
+	}
+
+	log.SessionOwner = session.Username
+
+	reqId, err := h.userUseCase.AtoiRequestedId(ctx)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotConvertRequestedIDToTypeInt(err))
+		return
+	}
+
+	found, err := h.sessUseCase.RevokeUserSession(session.UserId, reqId)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotDeleteSession(err))
+		return
+	}
+	if !found {
+		h.logUseCase.Report(ctx, log, msg.ErrorSessionWithRequestedIDIsNotExist())
+		return
+	}
+
+	h.logUseCase.Report(ctx, log, msg.InfoSessionRevoked())
+}
+
+// getValidSession returns the session of the request or nil when there is
+// no valid one, the expired session is deleted.
+func (h *AuthHandler) getValidSession(ctx *gin.Context, log *logger.Log) (*sess.Session, error) {
+	headerToken := h.useCase.ReadHeader(ctx)
+	if !h.useCase.IsTokenExists(headerToken) {
+		return nil, nil
+	}
+
+	session, err := h.sessUseCase.GetSessionAndDate(ctx, headerToken)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotGetSessionAndDate(err))
+		return nil, err
+	}
+	// The API keys are not signed in, they have no session to manage
+	if !h.useCase.IsSessionExists(session) || session.ApiKeyId != 0 {
+		return nil, nil
+	}
+
+	if timedate.IsDateExpired(session.RefreshDate, h.cfg.SessionTTLHours) {
+		if err := h.sessUseCase.DeleteSession(headerToken); err != nil {
+			h.logUseCase.Report(ctx, log, msg.ErrorCannotDeleteSession(err))
+			return nil, err
+		}
+		return nil, nil
+	}
+
+	return session, nil
+}
+
 func (h *AuthHandler) getValidSessionAndDeleteSession(ctx *gin.Context, log *logger.Log) (*sess.Session, error) {
 	headerToken := h.useCase.ReadHeader(ctx)
 	if !h.useCase.IsTokenExists(headerToken) {
 		return nil, nil
 	}
 
-	session, err := h.sessUseCase.GetSessionAndDate(headerToken)
+	session, err := h.sessUseCase.GetSessionAndDate(ctx, headerToken)
 	if err != nil {
 		h.logUseCase.Report(ctx, log, msg.ErrorCannotGetSessionAndDate(err))
 		return nil, err
 	}
-	if !h.useCase.IsSessionExists(session) {
+	// The API keys are not signed in, they have no session to manage
+	if !h.useCase.IsSessionExists(session) || session.ApiKeyId != 0 {
 		return nil, nil
 	}
 
@@ -183,7 +329,7 @@ func (h *AuthHandler) getValidSessionAndDeleteSession(ctx *gin.Context, log *log
 		return nil, err
 	}
 
-	if timedate.IsDateExpired(session.CreationDate, h.cfg.SessionTTLHours) {
+	if timedate.IsDateExpired(session.RefreshDate, h.cfg.SessionTTLHours) {
 		return nil, nil
 	}
 
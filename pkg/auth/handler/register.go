@@ -16,7 +16,11 @@ func RegisterHTTPEndpoints(router *gin.Engine, cfg *config.Config, uc auth.AuthU
 	authRoute := router.Group("/auth")
 	{
 		authRoute.POST("/signin", h.SignIn)
+		authRoute.POST("/refresh", h.Refresh)
 		authRoute.POST("/change_password", h.ChangePassword)
 		authRoute.GET("/signout", h.SignOut)
+		authRoute.POST("/signout_all", h.SignOutAll)
+		authRoute.GET("/sessions", h.GetSessions)
+		authRoute.DELETE("/sessions/:id", h.DeleteSession)
 	}
 }
@@ -55,28 +55,34 @@ func (r *AuthRepository) UpdateUserPassword(namepass *auth.Namepass) error {
 	return nil
 }
 
-func (r *AuthRepository) IsUsernameAndPasswordExists(usename, passwordHash string) (bool, error) {
+func (r *AuthRepository) GetPasswordHash(username string) (string, error) {
 	db := db_connect.CreateLocalDBConnection(r.cfg)
 	defer db_connect.CloseDBConnection(r.cfg, db)
 
 	template := qconsts.SELECT_COL_FROM_TBL_WHERE_CND
-	col := user.Id
+	col := user.PasswordHash
 	tbl := user.TableName
-	cnd := fmt.Sprintf("%s=$1 AND %s=$2", user.Username, user.PasswordHash)
+	cnd := fmt.Sprintf("%s=$1", user.Username)
 	query := fmt.Sprintf(template, col, tbl, cnd)
 
-	rows, err := db.Query(query, usename, passwordHash)
+	rows, err := db.Query(query, username)
 	if err != nil {
-		return false, err
+		return "", err
 	}
 	defer rows.Close()
 
-	isRowPresent := rows.Next()
-	if !isRowPresent {
-		return false, nil
+	var passwordHash string
+	if rows.Next() {
+		if err := rows.Scan(&passwordHash); err != nil {
+			return "", err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return "", err
 	}
 
-	return true, nil
+	return passwordHash, nil
 }
 
 func (r *AuthRepository) UpdateNamepassLastLogin(username, timestamp string) error {
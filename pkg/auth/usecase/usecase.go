@@ -1,6 +1,8 @@
 package usecase
 
 import (
+	"errors"
+
 	"github.com/gin-gonic/gin"
 	sess "vhosting/internal/session"
 	"vhosting/pkg/auth"
@@ -36,7 +38,7 @@ func (u *AuthUseCase) IsRequiredEmpty(namepass *auth.Namepass) bool {
 }
 
 func (u *AuthUseCase) IsSessionExists(session *sess.Session) bool {
-	if session.Content != "" {
+	if session.Id != 0 {
 		return true
 	}
 	return false
@@ -57,12 +59,24 @@ func (u *AuthUseCase) UpdateUserPassword(namepass *auth.Namepass) error {
 	return u.authRepo.UpdateUserPassword(namepass)
 }
 
-func (u *AuthUseCase) IsUsernameAndPasswordExists(username, passwordHash string) (bool, error) {
-	exists, err := u.authRepo.IsUsernameAndPasswordExists(username, passwordHash)
-	if err != nil {
-		return false, err
+func (u *AuthUseCase) GetPasswordHash(username string) (string, error) {
+	return u.authRepo.GetPasswordHash(username)
+}
+
+// IsPasswordMatched checks the entered password against the stored hash
+// and reports whether the hash is outdated and must be upgraded.
+func (u *AuthUseCase) IsPasswordMatched(password, passwordHash string) (bool, bool) {
+	return hasher.VerifyPasswordHash(password, u.cfg.HashingPasswordSalt, passwordHash)
+}
+
+// UpgradePasswordHash stores the password entered on sign-in hashed in
+// the current format.
+func (u *AuthUseCase) UpgradePasswordHash(namepass *auth.Namepass) error {
+	passwordHash := hasher.GeneratePasswordHash(namepass.PasswordHash)
+	if passwordHash == "" {
+		return errors.New("Cannot generate password hash.")
 	}
-	return exists, nil
+	return u.authRepo.UpdateUserPassword(&auth.Namepass{Username: namepass.Username, PasswordHash: passwordHash})
 }
 
 func (u *AuthUseCase) ReadHeader(ctx *gin.Context) string {
@@ -75,20 +89,17 @@ func (u *AuthUseCase) BindJSONNamepass(ctx *gin.Context) (*auth.Namepass, error)
 		return &namepass, err
 	}
 	if namepass.PasswordHash != "" {
-		namepass.PasswordHash = hasher.GeneratePasswordHash(namepass.PasswordHash, u.cfg.HashingPasswordSalt)
+		namepass.PasswordHash = hasher.GeneratePasswordHash(namepass.PasswordHash)
 	}
 	return &namepass, nil
 }
 
-func (u *AuthUseCase) GenerateToken(namepass *auth.Namepass) (string, error) {
-	namepass.PasswordHash = hasher.GeneratePasswordHash(namepass.PasswordHash, u.cfg.HashingPasswordSalt)
-	token, err := hasher.GenerateToken(namepass, u.cfg.HashingTokenSigningKey, u.cfg.SessionTTLHours)
-	if err != nil {
-		return "", err
+// BindJSONCredentials reads the username and password entered on sign-in,
+// the password is kept as is for checking it against the stored hash.
+func (u *AuthUseCase) BindJSONCredentials(ctx *gin.Context) (*auth.Namepass, error) {
+	var namepass auth.Namepass
+	if err := ctx.BindJSON(&namepass); err != nil {
+		return &namepass, err
 	}
-	return token, nil
-}
-
-func (u *AuthUseCase) ParseToken(token string) (*auth.Namepass, error) {
-	return hasher.ParseToken(token, u.cfg.HashingTokenSigningKey)
+	return &namepass, nil
 }
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"errors"
 	"os"
 	"strconv"
 	"strings"
@@ -12,6 +13,12 @@ import (
 )
 
 type Config struct {
+	ArchiveMaxAttempts         int
+	ArchiveOutputDir           string
+	ArchivePollIntervalSeconds int
+	ArchiveTmpDir              string
+	ArchiveWorkers             int
+
 	DBConnectionLatencyMilliseconds int
 	DBConnectionShowStatus          bool
 	DBConnectionTimeoutSeconds      int
@@ -36,11 +43,48 @@ type Config struct {
 
 	DBOPassword string
 
-	HashingPasswordSalt    string
-	HashingTokenSigningKey string
+	DeliveryMaxAttempts       int
+	DeliveryRetryDelaySeconds int
+	DeliveryTargets           []DeliveryTarget
+
+	DownloadBundleMaxItems      int
+	DownloadGlobalRateKbps      int
+	DownloadLinkTTLMinutes      int
+	DownloadMaxConcurrent       int
+	DownloadMaxQueued           int
+	DownloadQueueTimeoutSeconds int
+	DownloadUserRateKbps        int
+
+	ExportFontFile           string
+	ExportLogoDir            string
+	ExportMaxDurationMinutes int
+	ExportOutputDir          string
+	ExportWorkers            int
+
+	HashingCredentialsKey     string
+	HashingDownloadSigningKey string
+	HashingPasswordSalt       string
+	HashingTokenSigningKey    string
+
+	MediaDir string
 
 	PaginationGetLimitDefault int
 
+	PreviewColumns         int
+	PreviewDir             string
+	PreviewIntervalSeconds int
+	PreviewTileWidth       int
+	PreviewWorkers         int
+
+	QuotaMaxStreamsPerGroup        int
+	QuotaMaxStreamsPerUser         int
+	QuotaMaxViewersPerStream       int
+	QuotaOutboundBitrateBudgetKbps int
+
+	RetentionCheckPeriodMinutes int
+	RetentionDryRun             bool
+	RetentionEnable             bool
+
 	ServerDebugEnable         bool
 	ServerMaxHeaderBytes      int
 	ServerHost                string
@@ -48,8 +92,11 @@ type Config struct {
 	ServerReadTimeoutSeconds  int
 	ServerWriteTimeoutSeconds int
 
-	SessionTTLHours int
+	SessionAccessTTLMinutes int
+	SessionTTLHours         int // lifetime of a refresh token
 
+	StreamConfigCheckPeriodSeconds   int
+	StreamConfigFile                 string
 	StreamICEServersMutex            sync.RWMutex
 	StreamICEServers                 []string
 	StreamLink                       string
@@ -58,6 +105,14 @@ type Config struct {
 	StreamSnapshotsEnable            bool
 	StreamStreamsUpdatePeriodSeconds int
 
+	StreamTimelineGapToleranceSeconds int
+	StreamTimelineMaxDays             int
+	StreamTimelineSegmentSeconds      int
+
+	UploadDir             string
+	UploadExpirationHours int
+	UploadMaxSizeMB       int
+
 	ServerIP string
 }
 
@@ -255,6 +310,21 @@ func LoadConfig(path string) (*Config, error) {
 		cfg.DBOUsername = os.Getenv(param)
 	}
 
+	// The credentials key has no default, a key known from the sources
+	// would not protect the stored credentials
+	param = "HASHING_CREDENTIALS_KEY"
+	if os.Getenv(param) == "" {
+		return nil, errors.New("cvar " + param + " is not set")
+	}
+	cfg.HashingCredentialsKey = os.Getenv(param)
+
+	// A signing key known from the sources would let anyone forge links
+	param = "HASHING_DOWNLOAD_SIGNING_KEY"
+	if os.Getenv(param) == "" {
+		return nil, errors.New("cvar " + param + " is not set")
+	}
+	cfg.HashingDownloadSigningKey = os.Getenv(param)
+
 	param = "HASHING_PASSWORD_SALT"
 	if os.Getenv(param) == "" {
 		defaultVal := "SdD2Sdf@dFhSe#r"
@@ -309,6 +379,158 @@ func LoadConfig(path string) (*Config, error) {
 		cfg.ServerWriteTimeoutSeconds = val
 	}
 
+	param = "archive.maxAttempts"
+	if val := viper.GetInt(param); val == 0 {
+		defaultVal := 3
+		cfg.ArchiveMaxAttempts = defaultVal
+		logger.Print(msg.WarningCannotConvertCvar(param, defaultVal))
+	} else {
+		cfg.ArchiveMaxAttempts = val
+	}
+
+	param = "archive.outputDir"
+	if val := viper.GetString(param); val == "" {
+		defaultVal := "./media/archive"
+		cfg.ArchiveOutputDir = defaultVal
+		logger.Print(msg.WarningCannotConvertCvar(param, defaultVal))
+	} else {
+		cfg.ArchiveOutputDir = val
+	}
+
+	param = "archive.pollIntervalSeconds"
+	if val := viper.GetInt(param); val == 0 {
+		defaultVal := 60
+		cfg.ArchivePollIntervalSeconds = defaultVal
+		logger.Print(msg.WarningCannotConvertCvar(param, defaultVal))
+	} else {
+		cfg.ArchivePollIntervalSeconds = val
+	}
+
+	param = "archive.tmpDir"
+	if val := viper.GetString(param); val == "" {
+		defaultVal := "./tmp"
+		cfg.ArchiveTmpDir = defaultVal
+		logger.Print(msg.WarningCannotConvertCvar(param, defaultVal))
+	} else {
+		cfg.ArchiveTmpDir = val
+	}
+
+	param = "archive.workers"
+	if val := viper.GetInt(param); val == 0 {
+		defaultVal := 2
+		cfg.ArchiveWorkers = defaultVal
+		logger.Print(msg.WarningCannotConvertCvar(param, defaultVal))
+	} else {
+		cfg.ArchiveWorkers = val
+	}
+
+	param = "delivery.maxAttempts"
+	if val := viper.GetInt(param); val == 0 {
+		defaultVal := 5
+		cfg.DeliveryMaxAttempts = defaultVal
+		logger.Print(msg.WarningCannotConvertCvar(param, defaultVal))
+	} else {
+		cfg.DeliveryMaxAttempts = val
+	}
+
+	param = "delivery.retryDelaySeconds"
+	if val := viper.GetInt(param); val == 0 {
+		defaultVal := 30
+		cfg.DeliveryRetryDelaySeconds = defaultVal
+		logger.Print(msg.WarningCannotConvertCvar(param, defaultVal))
+	} else {
+		cfg.DeliveryRetryDelaySeconds = val
+	}
+
+	if err := viper.UnmarshalKey("delivery.targets", &cfg.DeliveryTargets); err != nil {
+		return nil, err
+	}
+	for i := range cfg.DeliveryTargets {
+		cfg.DeliveryTargets[i].expandEnv()
+	}
+
+	param = "download.bundleMaxItems"
+	if val := viper.GetInt(param); val == 0 {
+		defaultVal := 50
+		cfg.DownloadBundleMaxItems = defaultVal
+		logger.Print(msg.WarningCannotConvertCvar(param, defaultVal))
+	} else {
+		cfg.DownloadBundleMaxItems = val
+	}
+
+	param = "download.linkTTLMinutes"
+	if val := viper.GetInt(param); val == 0 {
+		defaultVal := 60
+		cfg.DownloadLinkTTLMinutes = defaultVal
+		logger.Print(msg.WarningCannotConvertCvar(param, defaultVal))
+	} else {
+		cfg.DownloadLinkTTLMinutes = val
+	}
+
+	// Zero value of a download limit means no limit, zero queue length
+	// means the requests over the limit are rejected right away
+	cfg.DownloadGlobalRateKbps = viper.GetInt("download.globalRateKbps")
+	cfg.DownloadMaxConcurrent = viper.GetInt("download.maxConcurrent")
+	cfg.DownloadMaxQueued = viper.GetInt("download.maxQueued")
+	cfg.DownloadUserRateKbps = viper.GetInt("download.userRateKbps")
+
+	param = "download.queueTimeoutSeconds"
+	if val := viper.GetInt(param); val == 0 {
+		defaultVal := 30
+		cfg.DownloadQueueTimeoutSeconds = defaultVal
+		logger.Print(msg.WarningCannotConvertCvar(param, defaultVal))
+	} else {
+		cfg.DownloadQueueTimeoutSeconds = val
+	}
+
+	// Empty font file means the default font of fontconfig
+	cfg.ExportFontFile = viper.GetString("export.fontFile")
+
+	param = "export.logoDir"
+	if val := viper.GetString(param); val == "" {
+		defaultVal := "./media/logos"
+		cfg.ExportLogoDir = defaultVal
+		logger.Print(msg.WarningCannotConvertCvar(param, defaultVal))
+	} else {
+		cfg.ExportLogoDir = val
+	}
+
+	param = "export.maxDurationMinutes"
+	if val := viper.GetInt(param); val == 0 {
+		defaultVal := 180
+		cfg.ExportMaxDurationMinutes = defaultVal
+		logger.Print(msg.WarningCannotConvertCvar(param, defaultVal))
+	} else {
+		cfg.ExportMaxDurationMinutes = val
+	}
+
+	param = "export.outputDir"
+	if val := viper.GetString(param); val == "" {
+		defaultVal := "./media/export"
+		cfg.ExportOutputDir = defaultVal
+		logger.Print(msg.WarningCannotConvertCvar(param, defaultVal))
+	} else {
+		cfg.ExportOutputDir = val
+	}
+
+	param = "export.workers"
+	if val := viper.GetInt(param); val == 0 {
+		defaultVal := 2
+		cfg.ExportWorkers = defaultVal
+		logger.Print(msg.WarningCannotConvertCvar(param, defaultVal))
+	} else {
+		cfg.ExportWorkers = val
+	}
+
+	param = "media.dir"
+	if val := viper.GetString(param); val == "" {
+		defaultVal := "./media"
+		cfg.MediaDir = defaultVal
+		logger.Print(msg.WarningCannotConvertCvar(param, defaultVal))
+	} else {
+		cfg.MediaDir = val
+	}
+
 	param = "pagination.getLimitDefault"
 	if val := viper.GetInt(param); val == 0 {
 		defaultVal := 30
@@ -318,6 +540,69 @@ func LoadConfig(path string) (*Config, error) {
 		cfg.PaginationGetLimitDefault = val
 	}
 
+	param = "preview.columns"
+	if val := viper.GetInt(param); val == 0 {
+		defaultVal := 10
+		cfg.PreviewColumns = defaultVal
+		logger.Print(msg.WarningCannotConvertCvar(param, defaultVal))
+	} else {
+		cfg.PreviewColumns = val
+	}
+
+	param = "preview.dir"
+	if val := viper.GetString(param); val == "" {
+		defaultVal := "./media/previews"
+		cfg.PreviewDir = defaultVal
+		logger.Print(msg.WarningCannotConvertCvar(param, defaultVal))
+	} else {
+		cfg.PreviewDir = val
+	}
+
+	param = "preview.intervalSeconds"
+	if val := viper.GetInt(param); val == 0 {
+		defaultVal := 10
+		cfg.PreviewIntervalSeconds = defaultVal
+		logger.Print(msg.WarningCannotConvertCvar(param, defaultVal))
+	} else {
+		cfg.PreviewIntervalSeconds = val
+	}
+
+	param = "preview.tileWidth"
+	if val := viper.GetInt(param); val == 0 {
+		defaultVal := 160
+		cfg.PreviewTileWidth = defaultVal
+		logger.Print(msg.WarningCannotConvertCvar(param, defaultVal))
+	} else {
+		cfg.PreviewTileWidth = val
+	}
+
+	param = "preview.workers"
+	if val := viper.GetInt(param); val == 0 {
+		defaultVal := 1
+		cfg.PreviewWorkers = defaultVal
+		logger.Print(msg.WarningCannotConvertCvar(param, defaultVal))
+	} else {
+		cfg.PreviewWorkers = val
+	}
+
+	// Zero value of any quota means no limit
+	cfg.QuotaMaxStreamsPerGroup = viper.GetInt("quota.maxStreamsPerGroup")
+	cfg.QuotaMaxStreamsPerUser = viper.GetInt("quota.maxStreamsPerUser")
+	cfg.QuotaMaxViewersPerStream = viper.GetInt("quota.maxViewersPerStream")
+	cfg.QuotaOutboundBitrateBudgetKbps = viper.GetInt("quota.outboundBitrateBudgetKbps")
+
+	param = "retention.checkPeriodMinutes"
+	if val := viper.GetInt(param); val == 0 {
+		defaultVal := 60
+		cfg.RetentionCheckPeriodMinutes = defaultVal
+		logger.Print(msg.WarningCannotConvertCvar(param, defaultVal))
+	} else {
+		cfg.RetentionCheckPeriodMinutes = val
+	}
+
+	cfg.RetentionDryRun = viper.GetBool("retention.dryRun")
+	cfg.RetentionEnable = viper.GetBool("retention.enable")
+
 	cfg.ServerDebugEnable = viper.GetBool("server.debugEnable")
 
 	param = "server.maxHeaderBytes"
@@ -329,6 +614,15 @@ func LoadConfig(path string) (*Config, error) {
 		cfg.ServerMaxHeaderBytes = val
 	}
 
+	param = "session.accessTTLMinutes"
+	if val := viper.GetInt(param); val == 0 {
+		defaultVal := 15
+		cfg.SessionAccessTTLMinutes = defaultVal
+		logger.Print(msg.WarningCannotConvertCvar(param, defaultVal))
+	} else {
+		cfg.SessionAccessTTLMinutes = val
+	}
+
 	param = "session.ttlHours"
 	if val := viper.GetInt(param); val == 0 {
 		defaultVal := 168 // 7 days
@@ -338,6 +632,24 @@ func LoadConfig(path string) (*Config, error) {
 		cfg.SessionTTLHours = val
 	}
 
+	param = "stream.configCheckPeriodSeconds"
+	if val := viper.GetInt(param); val == 0 {
+		defaultVal := 5
+		cfg.StreamConfigCheckPeriodSeconds = defaultVal
+		logger.Print(msg.WarningCannotConvertCvar(param, defaultVal))
+	} else {
+		cfg.StreamConfigCheckPeriodSeconds = val
+	}
+
+	param = "stream.configFile"
+	if val := viper.GetString(param); val == "" {
+		defaultVal := "./configs/streams.json"
+		cfg.StreamConfigFile = defaultVal
+		logger.Print(msg.WarningCannotConvertCvar(param, defaultVal))
+	} else {
+		cfg.StreamConfigFile = val
+	}
+
 	param = "stream.iceServers"
 	if val := viper.GetStringSlice(param); len(val) == 0 {
 		defaultICEServer := "stun:stun.l.google.com:19302"
@@ -369,5 +681,53 @@ func LoadConfig(path string) (*Config, error) {
 		cfg.StreamStreamsUpdatePeriodSeconds = val
 	}
 
+	// 0 - segments must follow each other exactly.
+	cfg.StreamTimelineGapToleranceSeconds = viper.GetInt("stream.timelineGapToleranceSeconds")
+
+	param = "stream.timelineMaxDays"
+	if val := viper.GetInt(param); val == 0 {
+		defaultVal := 31
+		cfg.StreamTimelineMaxDays = defaultVal
+		logger.Print(msg.WarningCannotConvertCvar(param, defaultVal))
+	} else {
+		cfg.StreamTimelineMaxDays = val
+	}
+
+	param = "stream.timelineSegmentSeconds"
+	if val := viper.GetInt(param); val == 0 {
+		defaultVal := 60
+		cfg.StreamTimelineSegmentSeconds = defaultVal
+		logger.Print(msg.WarningCannotConvertCvar(param, defaultVal))
+	} else {
+		cfg.StreamTimelineSegmentSeconds = val
+	}
+
+	param = "upload.dir"
+	if val := viper.GetString(param); val == "" {
+		defaultVal := "uploads"
+		cfg.UploadDir = defaultVal
+		logger.Print(msg.WarningCannotConvertCvar(param, defaultVal))
+	} else {
+		cfg.UploadDir = val
+	}
+
+	param = "upload.expirationHours"
+	if val := viper.GetInt(param); val == 0 {
+		defaultVal := 24
+		cfg.UploadExpirationHours = defaultVal
+		logger.Print(msg.WarningCannotConvertCvar(param, defaultVal))
+	} else {
+		cfg.UploadExpirationHours = val
+	}
+
+	param = "upload.maxSizeMB"
+	if val := viper.GetInt(param); val == 0 {
+		defaultVal := 4096
+		cfg.UploadMaxSizeMB = defaultVal
+		logger.Print(msg.WarningCannotConvertCvar(param, defaultVal))
+	} else {
+		cfg.UploadMaxSizeMB = val
+	}
+
 	return &cfg, nil
 }
@@ -0,0 +1,49 @@
+package config
+
+import "os"
+
+// DeliveryTarget is an item of "delivery.targets" in the config file.
+// Secrets may be set as ${ENV_VARIABLE} to keep them in the .env file.
+type DeliveryTarget struct {
+	Name        string
+	Type        string // "http", "dir" or "s3"
+	MaxAttempts int    // delivery.maxAttempts if not set
+
+	// http: multipart POST of the file
+	URL            string
+	FileField      string
+	Fields         []DeliveryParam
+	Headers        []DeliveryParam
+	TimeoutSeconds int
+
+	// dir: copy to a local or mounted NFS directory
+	Dir string
+
+	// s3: PUT to an S3-compatible bucket
+	Endpoint  string
+	Region    string
+	Bucket    string
+	Prefix    string
+	AccessKey string
+	SecretKey string
+}
+
+// DeliveryParam is a form field or a header. A list is used instead of a map
+// because the config loader lowercases map keys.
+type DeliveryParam struct {
+	Name  string
+	Value string
+}
+
+func (t *DeliveryTarget) expandEnv() {
+	t.URL = os.ExpandEnv(t.URL)
+	for i := range t.Fields {
+		t.Fields[i].Value = os.ExpandEnv(t.Fields[i].Value)
+	}
+	for i := range t.Headers {
+		t.Headers[i].Value = os.ExpandEnv(t.Headers[i].Value)
+	}
+	t.Endpoint = os.ExpandEnv(t.Endpoint)
+	t.AccessKey = os.ExpandEnv(t.AccessKey)
+	t.SecretKey = os.ExpandEnv(t.SecretKey)
+}
@@ -28,12 +28,14 @@ type Server struct {
 }
 
 type Stream struct {
-	URL          string `json:"url"`
-	Status       bool   `json:"status"`
-	OnDemand     bool   `json:"onDemand"`
-	DisableAudio bool   `json:"disableAudio"`
-	Debug        bool   `json:"debug"`
-	RunLock      bool   `json:"-"`
+	URL          string        `json:"url"`
+	Transport    string        `json:"transport"`
+	Status       bool          `json:"status"`
+	OnDemand     bool          `json:"onDemand"`
+	DisableAudio bool          `json:"disableAudio"`
+	Debug        bool          `json:"debug"`
+	RunLock      bool          `json:"-"`
+	Stop         chan struct{} `json:"-"`
 	Codecs       []av.CodecData
 	ClientList   map[string]Viewer
 	Working      bool
@@ -0,0 +1,24 @@
+package config_stream
+
+import (
+	"encoding/json"
+	"os"
+)
+
+// LoadConfig reads the server settings and streams from the JSON file.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, err
+	}
+	if cfg.Streams == nil {
+		cfg.Streams = map[string]Stream{}
+	}
+
+	return &cfg, nil
+}
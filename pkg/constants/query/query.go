@@ -2,6 +2,7 @@ package query_consts
 
 const (
 	INSERT_INTO_TBL_VALUES_VAL        = " INSERT INTO %s VALUES %s ON CONFLICT DO NOTHING"
+	UPSERT_INTO_TBL_VALUES_VAL_ON_COL = " INSERT INTO %s VALUES %s ON CONFLICT (%s) DO UPDATE SET %s"
 	SELECT_COL_FROM_TBL_WHERE_CND     = " SELECT %s FROM %s WHERE %s"
 	SELECT_COL_FROM_TBL               = " SELECT %s FROM %s"
 	UPDATE_TBL_SET_VAL_WHERE_CND      = " UPDATE %s SET %s WHERE %s"
@@ -29,26 +30,27 @@ const (
 	`
 
 	SELECT_VIDEO_PATH_BETWEEN = `
-	SELECT CONCAT("pathRecord", '/', "fileName")
+	SELECT CONCAT("pathRecord", '/', "fileName"), "recordTime"
 	FROM "VideoRecord"
-	WHERE "pathStream"='%s' 
+	WHERE "pathStream"=$1 
 	AND "recordTime" BETWEEN 
-		(
+		COALESCE((
 			SELECT "recordTime"
 			FROM "VideoRecord"
-			WHERE "pathStream"='%s'
-			AND "recordTime" <= '%s'::timestamp
+			WHERE "pathStream"=$1
+			AND "recordTime" <= $2::timestamp
 			ORDER BY "recordTime"  DESC
 			LIMIT 1
-		) 
+		), $2::timestamp) 
 			AND 
-		(
+		COALESCE((
 			SELECT "recordTime" 
 			FROM "VideoRecord"
-			WHERE "pathStream"='%s' 
-			AND "recordTime" >= '%s'::timestamp + (interval '%dm')
+			WHERE "pathStream"=$1 
+			AND "recordTime" >= $2::timestamp + make_interval(mins => $3::integer)
 			ORDER BY "recordTime"  ASC
 			LIMIT 1
-		)
+		), $2::timestamp + make_interval(mins => $3::integer))
+	ORDER BY "recordTime"
 	`
 )
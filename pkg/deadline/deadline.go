@@ -0,0 +1,48 @@
+// Package deadline lets the handlers sending long responses move the write
+// deadline of the server, which otherwise cuts every response off when the
+// write timeout passes since the request was read.
+package deadline
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"time"
+)
+
+type connKey struct{}
+
+// SaveConn is the ConnContext of the server, it keeps the connection of the
+// request for Extend.
+func SaveConn(ctx context.Context, conn net.Conn) context.Context {
+	return context.WithValue(ctx, connKey{}, conn)
+}
+
+// Extend moves the write deadline of the request connection the timeout
+// from now. It does nothing when the server has no write timeout.
+func Extend(r *http.Request, timeout time.Duration) error {
+	conn, ok := r.Context().Value(connKey{}).(net.Conn)
+	if !ok || timeout <= 0 {
+		return nil
+	}
+	return conn.SetWriteDeadline(time.Now().Add(timeout))
+}
+
+// responseWriter extends the write deadline before every write, so the
+// timeout limits a stalled write instead of the whole response.
+type responseWriter struct {
+	http.ResponseWriter
+	r       *http.Request
+	timeout time.Duration
+}
+
+func NewResponseWriter(w http.ResponseWriter, r *http.Request, timeout time.Duration) http.ResponseWriter {
+	return &responseWriter{ResponseWriter: w, r: r, timeout: timeout}
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if err := Extend(w.r, w.timeout); err != nil {
+		return 0, err
+	}
+	return w.ResponseWriter.Write(b)
+}
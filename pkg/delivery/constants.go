@@ -0,0 +1,32 @@
+package delivery
+
+const (
+	TableName  = "deliveries"
+	Id         = "id"
+	Source     = "source"
+	SourceId   = "source_id"
+	Target     = "target"
+	State      = "state"
+	Attempts   = "attempts"
+	LastError  = "last_error"
+	FilePath   = "file_path"
+	UpdateDate = "update_date"
+)
+
+const (
+	SourceArchive = "archive"
+	SourceExport  = "export"
+)
+
+const (
+	TargetTypeHTTP = "http"
+	TargetTypeDir  = "dir"
+	TargetTypeS3   = "s3"
+)
+
+const (
+	StateQueued  = "queued"
+	StateRunning = "running"
+	StateDone    = "done"
+	StateFailed  = "failed"
+)
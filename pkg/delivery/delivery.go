@@ -0,0 +1,39 @@
+package delivery
+
+import "context"
+
+// File is a finished video to be delivered to every configured target.
+type File struct {
+	Source   string
+	SourceId int
+	Path     string
+}
+
+type Delivery struct {
+	Id         int    `json:"-"          db:"id"`
+	Source     string `json:"-"          db:"source"`
+	SourceId   int    `json:"-"          db:"source_id"`
+	Target     string `json:"target"     db:"target"`
+	State      string `json:"state"      db:"state"`
+	Attempts   int    `json:"attempts"   db:"attempts"`
+	LastError  string `json:"lastError"  db:"last_error"`
+	FilePath   string `json:"-"          db:"file_path"`
+	UpdateDate string `json:"updateDate" db:"update_date"`
+}
+
+// Sender puts a file to a single target.
+type Sender interface {
+	Send(ctx context.Context, file *File) error
+}
+
+type DeliveryUseCase interface {
+	Deliver(ctx context.Context, file *File)
+	ResumeDeliveries(ctx context.Context, source string)
+	GetDeliveries(source string, sourceId int) ([]*Delivery, error)
+}
+
+type DeliveryRepository interface {
+	SetDelivery(d *Delivery) error
+	GetDeliveries(source string, sourceId int) ([]*Delivery, error)
+	GetDeliveriesByState(source, state string) ([]*Delivery, error)
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"fmt"
+
+	"vhosting/pkg/config"
+	qconsts "vhosting/pkg/constants/query"
+	"vhosting/pkg/db_connect"
+	"vhosting/pkg/delivery"
+	"vhosting/pkg/timedate"
+)
+
+var columns = fmt.Sprintf("%s, %s, %s, %s, %s, %s, %s, %s", delivery.Source,
+	delivery.SourceId, delivery.Target, delivery.State, delivery.Attempts,
+	delivery.LastError, delivery.FilePath, delivery.UpdateDate)
+
+type DeliveryRepository struct {
+	cfg *config.Config
+}
+
+func NewDeliveryRepository(cfg *config.Config) *DeliveryRepository {
+	return &DeliveryRepository{cfg: cfg}
+}
+
+func (r *DeliveryRepository) SetDelivery(d *delivery.Delivery) error {
+	db := db_connect.CreateLocalDBConnection(r.cfg)
+	defer db_connect.CloseDBConnection(r.cfg, db)
+
+	d.UpdateDate = timedate.GetTimestamp()
+
+	template := qconsts.UPSERT_INTO_TBL_VALUES_VAL_ON_COL
+	tbl := fmt.Sprintf("%s (%s)", delivery.TableName, columns)
+	val := "($1, $2, $3, $4, $5, $6, $7, $8)"
+	col := fmt.Sprintf("%s, %s, %s", delivery.Source, delivery.SourceId, delivery.Target)
+	set := fmt.Sprintf("%s=$4, %s=$5, %s=$6, %s=$7, %s=$8", delivery.State,
+		delivery.Attempts, delivery.LastError, delivery.FilePath, delivery.UpdateDate)
+	query := fmt.Sprintf(template, tbl, val, col, set)
+
+	rows, err := db.Query(query, d.Source, d.SourceId, d.Target, d.State,
+		d.Attempts, d.LastError, d.FilePath, d.UpdateDate)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	return nil
+}
+
+func (r *DeliveryRepository) GetDeliveries(source string, sourceId int) ([]*delivery.Delivery, error) {
+	db := db_connect.CreateLocalDBConnection(r.cfg)
+	defer db_connect.CloseDBConnection(r.cfg, db)
+
+	template := qconsts.SELECT_COL_FROM_TBL_WHERE_CND
+	col := fmt.Sprintf("%s, %s", delivery.Id, columns)
+	tbl := delivery.TableName
+	cnd := fmt.Sprintf("%s=$1 AND %s=$2 ORDER BY %s", delivery.Source,
+		delivery.SourceId, delivery.Target)
+	query := fmt.Sprintf(template, col, tbl, cnd)
+
+	deliveries := []*delivery.Delivery{}
+	if err := db.Select(&deliveries, query, source, sourceId); err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+func (r *DeliveryRepository) GetDeliveriesByState(source, state string) ([]*delivery.Delivery, error) {
+	db := db_connect.CreateLocalDBConnection(r.cfg)
+	defer db_connect.CloseDBConnection(r.cfg, db)
+
+	template := qconsts.SELECT_COL_FROM_TBL_WHERE_CND
+	col := fmt.Sprintf("%s, %s", delivery.Id, columns)
+	tbl := delivery.TableName
+	cnd := fmt.Sprintf("%s=$1 AND %s=$2", delivery.Source, delivery.State)
+	query := fmt.Sprintf(template, col, tbl, cnd)
+
+	deliveries := []*delivery.Delivery{}
+	if err := db.Select(&deliveries, query, source, state); err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
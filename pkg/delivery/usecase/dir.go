@@ -0,0 +1,70 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+
+	"vhosting/pkg/config"
+	"vhosting/pkg/delivery"
+)
+
+// dirSender copies files to a local or mounted NFS directory.
+type dirSender struct {
+	dir string
+}
+
+func newDirSender(t config.DeliveryTarget) (*dirSender, error) {
+	if t.Dir == "" {
+		return nil, errors.New("dir is empty")
+	}
+	return &dirSender{dir: t.Dir}, nil
+}
+
+// Send writes a temporary file and renames it, so readers of the directory
+// never see a partial video.
+func (s *dirSender) Send(ctx context.Context, file *delivery.File) error {
+	if err := os.MkdirAll(s.dir, 0777); err != nil {
+		return err
+	}
+
+	src, err := os.Open(file.Path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dstPath := filepath.Join(s.dir, filepath.Base(file.Path))
+	tmpPath := dstPath + ".part"
+	dst, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(dst, &ctxReader{ctx: ctx, r: src}); err != nil {
+		dst.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	return os.Rename(tmpPath, dstPath)
+}
+
+// ctxReader stops a long copy when the context is cancelled.
+type ctxReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r *ctxReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
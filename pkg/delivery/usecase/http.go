@@ -0,0 +1,111 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"vhosting/pkg/config"
+	"vhosting/pkg/delivery"
+)
+
+const responseTailBytes = 300
+
+// httpSender posts files as multipart forms.
+type httpSender struct {
+	url       string
+	fileField string
+	fields    []config.DeliveryParam
+	headers   []config.DeliveryParam
+	client    *http.Client
+}
+
+func newHTTPSender(t config.DeliveryTarget) (*httpSender, error) {
+	u, err := url.Parse(t.URL)
+	if err != nil {
+		return nil, err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, errors.New("url is invalid")
+	}
+
+	fileField := t.FileField
+	if fileField == "" {
+		fileField = "file"
+	}
+
+	return &httpSender{
+		url:       t.URL,
+		fileField: fileField,
+		fields:    t.Fields,
+		headers:   t.Headers,
+		client:    &http.Client{Timeout: time.Duration(t.TimeoutSeconds) * time.Second},
+	}, nil
+}
+
+// Send streams the form to the target. Field values may contain {source},
+// {sourceId} and {fileName} placeholders.
+func (s *httpSender) Send(ctx context.Context, file *delivery.File) error {
+	f, err := os.Open(file.Path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	replacer := strings.NewReplacer("{source}", file.Source,
+		"{sourceId}", strconv.Itoa(file.SourceId),
+		"{fileName}", filepath.Base(file.Path))
+
+	body, writer := io.Pipe()
+	form := multipart.NewWriter(writer)
+	go func() {
+		for _, field := range s.fields {
+			if err := form.WriteField(field.Name, replacer.Replace(field.Value)); err != nil {
+				writer.CloseWithError(err)
+				return
+			}
+		}
+		part, err := form.CreateFormFile(s.fileField, filepath.Base(file.Path))
+		if err != nil {
+			writer.CloseWithError(err)
+			return
+		}
+		if _, err := io.Copy(part, f); err != nil {
+			writer.CloseWithError(err)
+			return
+		}
+		writer.CloseWithError(form.Close())
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, body)
+	if err != nil {
+		body.Close()
+		return err
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	for _, header := range s.headers {
+		req.Header.Set(header.Name, header.Value)
+	}
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		tail, _ := io.ReadAll(io.LimitReader(res.Body, responseTailBytes))
+		return fmt.Errorf("unexpected status %s: %s", res.Status, tail)
+	}
+
+	return nil
+}
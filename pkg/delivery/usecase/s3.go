@@ -0,0 +1,163 @@
+package usecase
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"vhosting/pkg/config"
+	"vhosting/pkg/delivery"
+)
+
+const (
+	s3DefaultRegion = "us-east-1"
+	s3DateLayout    = "20060102"
+	s3TimeLayout    = "20060102T150405Z"
+)
+
+// s3Sender puts files to an S3-compatible bucket (AWS, MinIO) by path-style
+// URLs signed with AWS Signature Version 4.
+type s3Sender struct {
+	endpoint  *url.URL
+	region    string
+	bucket    string
+	prefix    string
+	accessKey string
+	secretKey string
+	client    *http.Client
+}
+
+func newS3Sender(t config.DeliveryTarget) (*s3Sender, error) {
+	endpoint, err := url.Parse(t.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return nil, errors.New("endpoint is invalid")
+	}
+	if t.Bucket == "" || t.AccessKey == "" || t.SecretKey == "" {
+		return nil, errors.New("bucket, accessKey and secretKey are required")
+	}
+
+	region := t.Region
+	if region == "" {
+		region = s3DefaultRegion
+	}
+
+	return &s3Sender{
+		endpoint:  endpoint,
+		region:    region,
+		bucket:    t.Bucket,
+		prefix:    strings.Trim(t.Prefix, "/"),
+		accessKey: t.AccessKey,
+		secretKey: t.SecretKey,
+		client:    &http.Client{Timeout: time.Duration(t.TimeoutSeconds) * time.Second},
+	}, nil
+}
+
+func (s *s3Sender) Send(ctx context.Context, file *delivery.File) error {
+	f, err := os.Open(file.Path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	// The payload hash is a part of the signature, so the file is read twice
+	hash := sha256.New()
+	size, err := io.Copy(hash, f)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	payloadHash := hex.EncodeToString(hash.Sum(nil))
+
+	key := path.Join(s.prefix, filepath.Base(file.Path))
+	objectURL := *s.endpoint
+	objectURL.Path = "/" + path.Join(strings.Trim(s.endpoint.Path, "/"), s.bucket, key)
+	objectURL.RawPath = s3EscapePath(objectURL.Path)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, objectURL.String(), f)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", "video/mp4")
+	s.sign(req, payloadHash, time.Now().UTC())
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		tail, _ := io.ReadAll(io.LimitReader(res.Body, responseTailBytes))
+		return fmt.Errorf("unexpected status %s: %s", res.Status, tail)
+	}
+
+	return nil
+}
+
+// sign adds the Authorization header, see
+// https://docs.aws.amazon.com/general/latest/gr/sigv4-create-canonical-request.html
+func (s *s3Sender) sign(req *http.Request, payloadHash string, now time.Time) {
+	amzDate := now.Format(s3TimeLayout)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	signedHeaders := "content-type;host;x-amz-content-sha256;x-amz-date"
+	canonicalHeaders := "content-type:" + req.Header.Get("Content-Type") + "\n" +
+		"host:" + req.URL.Host + "\n" +
+		"x-amz-content-sha256:" + payloadHash + "\n" +
+		"x-amz-date:" + amzDate + "\n"
+	canonicalRequest := strings.Join([]string{req.Method, s3EscapePath(req.URL.Path),
+		"", canonicalHeaders, signedHeaders, payloadHash}, "\n")
+
+	scope := now.Format(s3DateLayout) + "/" + s.region + "/s3/aws4_request"
+	canonicalHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" +
+		hex.EncodeToString(canonicalHash[:])
+
+	signingKey := hmacSHA256([]byte("AWS4"+s.secretKey), now.Format(s3DateLayout))
+	for _, part := range []string{s.region, "s3", "aws4_request"} {
+		signingKey = hmacSHA256(signingKey, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+s.accessKey+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// s3EscapePath escapes everything but unreserved characters and slashes.
+func s3EscapePath(p string) string {
+	var b strings.Builder
+	for i := 0; i < len(p); i++ {
+		c := p[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' ||
+			c == '-' || c == '_' || c == '.' || c == '~' || c == '/' {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
@@ -0,0 +1,198 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	msg "vhosting/internal/messages"
+	"vhosting/pkg/config"
+	"vhosting/pkg/delivery"
+	"vhosting/pkg/logger"
+)
+
+type target struct {
+	name        string
+	maxAttempts int
+	sender      delivery.Sender
+}
+
+type DeliveryUseCase struct {
+	cfg          *config.Config
+	deliveryRepo delivery.DeliveryRepository
+	targets      []*target
+}
+
+// NewDeliveryUseCase creates senders for "delivery.targets" of the config,
+// invalid targets are reported and skipped.
+func NewDeliveryUseCase(cfg *config.Config, deliveryRepo delivery.DeliveryRepository) *DeliveryUseCase {
+	u := &DeliveryUseCase{
+		cfg:          cfg,
+		deliveryRepo: deliveryRepo,
+	}
+
+	for _, t := range cfg.DeliveryTargets {
+		sender, err := newSender(t)
+		if err != nil {
+			logger.Print(msg.ErrorDeliveryTargetIsInvalid(t.Name, err))
+			continue
+		}
+		maxAttempts := t.MaxAttempts
+		if maxAttempts <= 0 {
+			maxAttempts = cfg.DeliveryMaxAttempts
+		}
+		u.targets = append(u.targets, &target{name: t.Name, maxAttempts: maxAttempts, sender: sender})
+	}
+
+	return u
+}
+
+func newSender(t config.DeliveryTarget) (delivery.Sender, error) {
+	if t.Name == "" {
+		return nil, errors.New("name is empty")
+	}
+
+	switch t.Type {
+	case delivery.TargetTypeHTTP:
+		return newHTTPSender(t)
+	case delivery.TargetTypeDir:
+		return newDirSender(t)
+	case delivery.TargetTypeS3:
+		return newS3Sender(t)
+	}
+
+	return nil, errors.New("unknown type \"" + t.Type + "\"")
+}
+
+// Deliver sends the file to all targets at once and returns when every
+// delivery is done, failed or interrupted by the context.
+func (u *DeliveryUseCase) Deliver(ctx context.Context, file *delivery.File) {
+	var wg sync.WaitGroup
+	for _, t := range u.targets {
+		d := &delivery.Delivery{
+			Source:   file.Source,
+			SourceId: file.SourceId,
+			Target:   t.name,
+			State:    delivery.StateQueued,
+			FilePath: file.Path,
+		}
+		wg.Add(1)
+		go func(t *target) {
+			defer wg.Done()
+			u.deliverTo(ctx, t, d)
+		}(t)
+	}
+	wg.Wait()
+}
+
+// ResumeDeliveries continues the deliveries of the source left unfinished
+// by a stopped process. Targets removed from the config are skipped.
+func (u *DeliveryUseCase) ResumeDeliveries(ctx context.Context, source string) {
+	var wg sync.WaitGroup
+	for _, state := range []string{delivery.StateRunning, delivery.StateQueued} {
+		deliveries, err := u.deliveryRepo.GetDeliveriesByState(source, state)
+		if err != nil {
+			logger.Print(msg.ErrorCannotGetDeliveries(err))
+			continue
+		}
+		for _, d := range deliveries {
+			t := u.getTarget(d.Target)
+			if t == nil {
+				continue
+			}
+			wg.Add(1)
+			go func(d *delivery.Delivery) {
+				defer wg.Done()
+				u.deliverTo(ctx, t, d)
+			}(d)
+		}
+	}
+	wg.Wait()
+}
+
+func (u *DeliveryUseCase) GetDeliveries(source string, sourceId int) ([]*delivery.Delivery, error) {
+	deliveries, err := u.deliveryRepo.GetDeliveries(source, sourceId)
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// deliverTo sends the file until success or the attempts are over, the
+// delay between attempts grows with every failed one.
+func (u *DeliveryUseCase) deliverTo(ctx context.Context, t *target, d *delivery.Delivery) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Print(msg.ErrorDeliveryWorkerPanic(r))
+		}
+	}()
+
+	file := &delivery.File{Source: d.Source, SourceId: d.SourceId, Path: d.FilePath}
+
+	for d.Attempts < t.maxAttempts {
+		d.State = delivery.StateRunning
+		d.Attempts++
+		if !u.saveDelivery(d) {
+			return
+		}
+
+		err := t.sender.Send(ctx, file)
+		switch {
+		case err == nil:
+			d.State = delivery.StateDone
+			d.LastError = ""
+			if u.saveDelivery(d) {
+				logger.Print(msg.InfoDeliveryDone(d.Source, d.SourceId, d.Target))
+			}
+			return
+		case ctx.Err() != nil:
+			// Shutdown interrupted the delivery, it is not counted as an attempt
+			d.State = delivery.StateQueued
+			d.Attempts--
+			u.saveDelivery(d)
+			return
+		case d.Attempts < t.maxAttempts:
+			d.State = delivery.StateQueued
+			d.LastError = err.Error()
+			logger.Print(msg.WarningDeliveryAttemptFailed(d.Source, d.SourceId, d.Target, d.Attempts, err))
+			if !u.saveDelivery(d) {
+				return
+			}
+		default:
+			d.State = delivery.StateFailed
+			d.LastError = err.Error()
+			logger.Print(msg.ErrorDeliveryFailed(d.Source, d.SourceId, d.Target, err))
+			u.saveDelivery(d)
+			return
+		}
+
+		delay := time.Duration(u.cfg.DeliveryRetryDelaySeconds*d.Attempts) * time.Second
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+	}
+
+	// The target attempts were lowered in the config
+	d.State = delivery.StateFailed
+	u.saveDelivery(d)
+}
+
+func (u *DeliveryUseCase) getTarget(name string) *target {
+	for _, t := range u.targets {
+		if t.name == name {
+			return t
+		}
+	}
+	return nil
+}
+
+func (u *DeliveryUseCase) saveDelivery(d *delivery.Delivery) bool {
+	if err := u.deliveryRepo.SetDelivery(d); err != nil {
+		logger.Print(msg.ErrorCannotSetDelivery(err))
+		return false
+	}
+	return true
+}
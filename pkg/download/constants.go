@@ -0,0 +1,7 @@
+package download
+
+const (
+	RevTableName  = "download_revocations"
+	RevUserId     = "user_id"
+	RevRevokeDate = "revoke_date"
+)
@@ -1,10 +1,69 @@
 package download
 
+import (
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"vhosting/pkg/mediadir"
+)
+
 type Download struct {
-	DownloadLink string `json:"downloadLink"`
+	DownloadLink   string `json:"downloadLink"`
+	ExpirationDate string `json:"expirationDate"`
+}
+
+type BundleRequest struct {
+	VideoIds  []int `json:"videoIds"`
+	ExportIds []int `json:"exportIds"`
+}
+
+// BundleItem is a file of the ZIP bundle. Size and SHA256 are counted while
+// the file is written to the bundle.
+type BundleItem struct {
+	Type     string      `json:"type"` // "video" or "export"
+	Id       int         `json:"id"`
+	Name     string      `json:"name"` // path in the bundle
+	Size     int64       `json:"size"`
+	SHA256   string      `json:"sha256"`
+	Metadata interface{} `json:"metadata"` // the video or the export
+	Path     string      `json:"-"`
+	Sent     int64       `json:"-"` // bytes of the file written to the client
 }
 
+// BundleManifest is written to the bundle after the files.
+type BundleManifest struct {
+	CreationDate string        `json:"creationDate"`
+	CreatedBy    string        `json:"createdBy"`
+	Items        []*BundleItem `json:"items"`
+}
+
+var (
+	ErrInvalidPath  = mediadir.ErrPathIsOutOfDir
+	ErrLinkInvalid  = errors.New("download link signature is invalid")
+	ErrLinkExpired  = errors.New("download link is expired")
+	ErrLinkRevoked  = errors.New("download link is revoked")
+	ErrQueueIsFull  = errors.New("download queue is full")
+	ErrQueueTimeout = errors.New("download queue wait timed out")
+)
+
 type DownloadUseCase interface {
 	IsValidExtension(file_name string) bool
-	CreateDownloadLink(local_file_path string) *Download
+	CreateDownloadLink(local_file_path string, userId int) *Download
+	VerifyDownloadLink(local_file_path string, urlparams url.Values) (int, error)
+	RevokeDownloadLinks(userId int) error
+	MediaPath(fileDir, fileName string) (string, error)
+	AcquireDownload(ctx context.Context, userId int, w io.Writer) (io.Writer, func(), error)
+	BindJSONBundleRequest(ctx *gin.Context) (*BundleRequest, error)
+	IsBundleEmpty(req *BundleRequest) bool
+	IsBundleTooLarge(req *BundleRequest) bool
+	WriteBundle(w io.Writer, items []*BundleItem, createdBy string) error
+}
+
+type DownloadRepository interface {
+	SetRevokeDate(userId int, date time.Time) error
+	GetRevokeDate(userId int) (time.Time, error)
 }
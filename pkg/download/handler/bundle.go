@@ -0,0 +1,230 @@
+package handler
+
+import (
+	"mime"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"vhosting/internal/audit"
+	msg "vhosting/internal/messages"
+	"vhosting/internal/video"
+	"vhosting/pkg/archive"
+	"vhosting/pkg/deadline"
+	"vhosting/pkg/download"
+	"vhosting/pkg/export"
+	"vhosting/pkg/logger"
+	"vhosting/pkg/timedate"
+)
+
+// DownloadBundle streams the requested videos and exports as a ZIP archive.
+// Every item is checked before the first byte is sent, errors after that
+// can only be logged.
+func (h *DownloadHandler) DownloadBundle(ctx *gin.Context) {
+	actPermission := "download_file"
+
+	log := logger.Init(ctx)
+
+	hasPerms, userId, apiKeyId := h.isPermsGranted_getUserId(ctx, log, actPermission)
+	if !hasPerms {
+		return
+	}
+
+	req, err := h.useCase.BindJSONBundleRequest(ctx)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotBindInputData(err))
+		return
+	}
+
+	if h.useCase.IsBundleEmpty(req) {
+		h.logUseCase.Report(ctx, log, msg.ErrorBundleItemsCannotBeEmpty())
+		return
+	}
+	if h.useCase.IsBundleTooLarge(req) {
+		h.logUseCase.Report(ctx, log, msg.ErrorBundleHasTooManyItems(h.cfg.DownloadBundleMaxItems))
+		return
+	}
+
+	items := []*download.BundleItem{}
+
+	seen := map[int]bool{}
+	for _, id := range req.VideoIds {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		item, ok := h.bundleVideo(ctx, log, id)
+		if !ok {
+			return
+		}
+		items = append(items, item)
+	}
+
+	seen = map[int]bool{}
+	for _, id := range req.ExportIds {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		item, ok := h.bundleExport(ctx, log, id, userId, apiKeyId)
+		if !ok {
+			return
+		}
+		items = append(items, item)
+	}
+
+	// The bundle may take longer to send than the write timeout of the server
+	writeTimeout := time.Duration(h.cfg.ServerWriteTimeoutSeconds) * time.Second
+	body, release, ok := h.acquireDownload(ctx, log, userId,
+		deadline.NewResponseWriter(ctx.Writer, ctx.Request, writeTimeout))
+	if !ok {
+		return
+	}
+	defer release()
+
+	fileName := "bundle_" + time.Now().Format("20060102_150405") + ".zip"
+	ctx.Header("Content-Type", "application/zip")
+	ctx.Header("Content-Disposition", mime.FormatMediaType("attachment",
+		map[string]string{"filename": fileName}))
+	ctx.Status(200)
+
+	start := timedate.GetTimestamp()
+	err = h.useCase.WriteBundle(body, items, log.SessionOwner)
+	h.recordBundle(ctx, userId, items, start)
+	if err != nil {
+		h.record(log, msg.ErrorCannotWriteBundle(err))
+		return
+	}
+
+	h.record(log, msg.InfoBundleSent(len(items)))
+}
+
+// recordBundle writes the audit record of every file of the bundle with the
+// bytes of the file written before the download ended.
+func (h *DownloadHandler) recordBundle(ctx *gin.Context, userId int, items []*download.BundleItem, start string) {
+	for _, item := range items {
+		acc := &audit.Access{
+			Kind:      audit.KindBundle,
+			UserId:    userId,
+			ClientIP:  ctx.ClientIP(),
+			BytesSent: item.Sent,
+			StartDate: start,
+		}
+		switch meta := item.Metadata.(type) {
+		case *video.Video:
+			acc.VideoId = meta.Id
+			acc.File = meta.File
+		case *export.Export:
+			acc.ExportId = meta.Id
+			acc.Stream = meta.PathStream
+		}
+		h.auditUseCase.RecordAccess(acc)
+	}
+}
+
+func (h *DownloadHandler) bundleVideo(ctx *gin.Context, log *logger.Log, id int) (*download.BundleItem, bool) {
+	exists, err := h.videoUseCase.IsVideoExists(id)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotCheckVideoExistence(err))
+		return nil, false
+	}
+	if !exists {
+		h.logUseCase.Report(ctx, log, msg.ErrorBundleVideoIsNotExist(id))
+		return nil, false
+	}
+
+	gottenVideo, err := h.videoUseCase.GetVideo(id)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotGetVideo(err))
+		return nil, false
+	}
+
+	path, err := h.videoUseCase.VideoPath(gottenVideo)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorBundleFileIsNotExist(gottenVideo.File))
+		return nil, false
+	}
+
+	item := &download.BundleItem{
+		Type:     "video",
+		Id:       id,
+		Name:     "videos/" + strconv.Itoa(id) + "_" + filepath.Base(path),
+		Metadata: gottenVideo,
+		Path:     path,
+	}
+	return item, h.isBundleFileExists(ctx, log, item)
+}
+
+// bundleExport checks the export the way GET /export/:id/file does, it is
+// available to the user who created it and to superusers and staff, but
+// not to their API keys.
+func (h *DownloadHandler) bundleExport(ctx *gin.Context, log *logger.Log, id, userId, apiKeyId int) (*download.BundleItem, bool) {
+	exists, err := h.exportUseCase.IsExportExists(id)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotCheckExportExistence(err))
+		return nil, false
+	}
+	if !exists {
+		h.logUseCase.Report(ctx, log, msg.ErrorBundleExportIsNotExist(id))
+		return nil, false
+	}
+
+	gottenExport, err := h.exportUseCase.GetExport(id)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotGetExport(err))
+		return nil, false
+	}
+
+	if gottenExport.UserId != userId {
+		// The API keys have their scopes only, not the access of the staff
+		isSUorStaff := false
+		if apiKeyId == 0 {
+			if isSUorStaff, err = h.userUseCase.IsUserSuperuserOrStaff(log.SessionOwner); err != nil {
+				h.logUseCase.Report(ctx, log, msg.ErrorCannotCheckSuperuserStaffPermissions(err))
+				return nil, false
+			}
+		}
+		if !isSUorStaff {
+			h.logUseCase.Report(ctx, log, msg.ErrorYouHaveNotEnoughPermissions())
+			return nil, false
+		}
+	}
+
+	if gottenExport.State != archive.StateDone {
+		h.logUseCase.Report(ctx, log, msg.ErrorBundleExportIsNotReady(id, gottenExport.State))
+		return nil, false
+	}
+
+	item := &download.BundleItem{
+		Type:     "export",
+		Id:       id,
+		Name:     "exports/export_" + strconv.Itoa(id) + ".mp4",
+		Metadata: gottenExport,
+		Path:     gottenExport.FilePath,
+	}
+	return item, h.isBundleFileExists(ctx, log, item)
+}
+
+func (h *DownloadHandler) isBundleFileExists(ctx *gin.Context, log *logger.Log, item *download.BundleItem) bool {
+	info, err := os.Stat(item.Path)
+	if err != nil || info.IsDir() {
+		h.logUseCase.Report(ctx, log, msg.ErrorBundleFileIsNotExist(item.Name))
+		return false
+	}
+	return true
+}
+
+// record writes the message to the logs table without a response, the
+// response is the bundle itself.
+func (h *DownloadHandler) record(log *logger.Log, messageLog *logger.Log) {
+	logger.Complete(log, messageLog)
+	logger.Finish(log)
+	if err := h.logUseCase.CreateLogRecord(log); err != nil {
+		logger.Print(msg.ErrorCannotDoLogging(err))
+	}
+	logger.Print(log)
+}
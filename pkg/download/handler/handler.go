@@ -1,36 +1,55 @@
 package handler
 
 import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"time"
+
 	"github.com/gin-gonic/gin"
+	"vhosting/internal/audit"
 	msg "vhosting/internal/messages"
 	sess "vhosting/internal/session"
+	"vhosting/internal/video"
 	"vhosting/pkg/auth"
 	"vhosting/pkg/config"
+	"vhosting/pkg/deadline"
 	"vhosting/pkg/download"
+	"vhosting/pkg/export"
 	"vhosting/pkg/logger"
 	"vhosting/pkg/timedate"
 	"vhosting/pkg/user"
 )
 
 type DownloadHandler struct {
-	cfg         *config.Config
-	useCase     download.DownloadUseCase
-	logUseCase  logger.LogUseCase
-	authUseCase auth.AuthUseCase
-	sessUseCase sess.SessUseCase
-	userUseCase user.UserUseCase
+	cfg           *config.Config
+	useCase       download.DownloadUseCase
+	logUseCase    logger.LogUseCase
+	authUseCase   auth.AuthUseCase
+	sessUseCase   sess.SessUseCase
+	userUseCase   user.UserUseCase
+	videoUseCase  video.VideoUseCase
+	exportUseCase export.ExportUseCase
+	auditUseCase  audit.AuditUseCase
 }
 
 func NewDownloadHandler(cfg *config.Config, useCase download.DownloadUseCase,
 	logUseCase logger.LogUseCase, authUseCase auth.AuthUseCase,
-	sessUseCase sess.SessUseCase, userUseCase user.UserUseCase) *DownloadHandler {
+	sessUseCase sess.SessUseCase, userUseCase user.UserUseCase,
+	videoUseCase video.VideoUseCase, exportUseCase export.ExportUseCase,
+	auditUseCase audit.AuditUseCase) *DownloadHandler {
 	return &DownloadHandler{
-		cfg:         cfg,
-		useCase:     useCase,
-		logUseCase:  logUseCase,
-		authUseCase: authUseCase,
-		sessUseCase: sessUseCase,
-		userUseCase: userUseCase,
+		cfg:           cfg,
+		useCase:       useCase,
+		logUseCase:    logUseCase,
+		authUseCase:   authUseCase,
+		sessUseCase:   sessUseCase,
+		userUseCase:   userUseCase,
+		videoUseCase:  videoUseCase,
+		exportUseCase: exportUseCase,
+		auditUseCase:  auditUseCase,
 	}
 }
 
@@ -39,7 +58,7 @@ func (h *DownloadHandler) DownloadFile(ctx *gin.Context) {
 
 	log := logger.Init(ctx)
 
-	hasPerms, _ := h.isPermsGranted_getUserId(ctx, log, actPermission)
+	hasPerms, userId, _ := h.isPermsGranted_getUserId(ctx, log, actPermission)
 	if !hasPerms {
 		return
 	}
@@ -48,80 +67,278 @@ func (h *DownloadHandler) DownloadFile(ctx *gin.Context) {
 
 	if !h.useCase.IsValidExtension(fileName) {
 		h.logUseCase.Report(ctx, log, msg.ErrorExtensionIsNotMp4())
+		return
 	}
 
 	fileDir := ctx.Param("file_dir")
 
-	download := h.useCase.CreateDownloadLink(fileDir + "/" + fileName)
+	if _, ok := h.mediaPath(ctx, log, fileDir, fileName); !ok {
+		return
+	}
+
+	dload := h.useCase.CreateDownloadLink(fileDir+"/"+fileName, userId)
+
+	h.logUseCase.Report(ctx, log, msg.InfoPutDownloadLink(dload))
+}
+
+// RevokeDownloadLinks makes all the download links of the session owner
+// invalid.
+func (h *DownloadHandler) RevokeDownloadLinks(ctx *gin.Context) {
+	actPermission := "download_file"
+
+	log := logger.Init(ctx)
+
+	hasPerms, userId, _ := h.isPermsGranted_getUserId(ctx, log, actPermission)
+	if !hasPerms {
+		return
+	}
+
+	if err := h.useCase.RevokeDownloadLinks(userId); err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotRevokeDownloadLinks(err))
+		return
+	}
+
+	h.logUseCase.Report(ctx, log, msg.InfoDownloadLinksRevoked())
+}
+
+func (h *DownloadHandler) RevokeUserDownloadLinks(ctx *gin.Context) {
+	actPermission := "revoke_download_links"
+
+	log := logger.Init(ctx)
+
+	hasPerms, _, _ := h.isPermsGranted_getUserId(ctx, log, actPermission)
+	if !hasPerms {
+		return
+	}
+
+	reqId, err := h.userUseCase.AtoiRequestedId(ctx)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotConvertRequestedIDToTypeInt(err))
+		return
+	}
+
+	exists, err := h.userUseCase.IsUserExists(reqId)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotCheckUserExistence(err))
+		return
+	}
+	if !exists {
+		h.logUseCase.Report(ctx, log, msg.ErrorUserWithRequestedIDIsNotExist())
+		return
+	}
+
+	if err := h.useCase.RevokeDownloadLinks(reqId); err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotRevokeDownloadLinks(err))
+		return
+	}
+
+	h.logUseCase.Report(ctx, log, msg.InfoDownloadLinksRevoked())
+}
+
+// ServeMedia sends the MP4 file of the media directory by a signed link.
+// Range and If-Range requests are answered with the requested parts of the
+// file.
+func (h *DownloadHandler) ServeMedia(ctx *gin.Context) {
+	log := logger.Init(ctx)
+
+	fileDir := ctx.Param("file_dir")
+	fileName := ctx.Param("file_name")
+
+	userId, err := h.useCase.VerifyDownloadLink(fileDir+"/"+fileName, ctx.Request.URL.Query())
+	if err != nil {
+		if errors.Is(err, download.ErrLinkInvalid) {
+			h.logUseCase.Report(ctx, log, msg.ErrorDownloadLinkIsInvalid())
+		} else if errors.Is(err, download.ErrLinkExpired) {
+			h.logUseCase.Report(ctx, log, msg.ErrorDownloadLinkIsExpired())
+		} else if errors.Is(err, download.ErrLinkRevoked) {
+			h.logUseCase.Report(ctx, log, msg.ErrorDownloadLinkIsRevoked())
+		} else {
+			h.logUseCase.Report(ctx, log, msg.ErrorCannotCheckDownloadLink(err))
+		}
+		return
+	}
+
+	if !h.useCase.IsValidExtension(fileName) {
+		h.logUseCase.Report(ctx, log, msg.ErrorExtensionIsNotMp4())
+		return
+	}
+
+	path, ok := h.mediaPath(ctx, log, fileDir, fileName)
+	if !ok {
+		return
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotOpenFile(err))
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotOpenFile(err))
+		return
+	}
+	if info.IsDir() {
+		h.logUseCase.Report(ctx, log, msg.ErrorFileIsNotExist())
+		return
+	}
+
+	// The file may take longer to send than the write timeout of the server
+	writeTimeout := time.Duration(h.cfg.ServerWriteTimeoutSeconds) * time.Second
+	body, release, ok := h.acquireDownload(ctx, log, userId,
+		deadline.NewResponseWriter(ctx.Writer, ctx.Request, writeTimeout))
+	if !ok {
+		return
+	}
+	defer release()
 
-	h.logUseCase.Report(ctx, log, msg.InfoPutDownloadLink(download))
+	acc := &audit.Access{
+		Kind:      audit.KindMedia,
+		UserId:    userId,
+		File:      fileDir + "/" + fileName,
+		ClientIP:  ctx.ClientIP(),
+		StartDate: timedate.GetTimestamp(),
+	}
+
+	ctx.Header("Content-Type", "video/mp4")
+	ctx.Header("Content-Disposition", mime.FormatMediaType("attachment",
+		map[string]string{"filename": fileName}))
+	http.ServeContent(&throttledResponseWriter{ctx.Writer, body}, ctx.Request,
+		fileName, info.ModTime(), file)
+
+	acc.BytesSent = int64(ctx.Writer.Size())
+	h.auditUseCase.RecordAccess(acc)
+}
+
+// acquireDownload waits for a download slot, reporting the exceeded limit
+// to the client when the slot cannot be reserved. The returned writer sends
+// the body to w.
+func (h *DownloadHandler) acquireDownload(ctx *gin.Context, log *logger.Log, userId int,
+	w io.Writer) (io.Writer, func(), bool) {
+	body, release, err := h.useCase.AcquireDownload(ctx.Request.Context(), userId, w)
+
+	// The wait in the queue must not take the time of sending the response
+	deadline.Extend(ctx.Request, time.Duration(h.cfg.ServerWriteTimeoutSeconds)*time.Second)
+
+	switch {
+	case err == nil:
+		return body, release, true
+	case errors.Is(err, download.ErrQueueIsFull):
+		h.logUseCase.Report(ctx, log, msg.ErrorDownloadQueueIsFull(h.cfg.DownloadMaxConcurrent))
+	case errors.Is(err, download.ErrQueueTimeout):
+		h.logUseCase.Report(ctx, log, msg.ErrorDownloadQueueTimeout(h.cfg.DownloadMaxConcurrent))
+	default:
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotAcquireDownloadSlot(err))
+	}
+	return nil, nil, false
+}
+
+// throttledResponseWriter sends the response body through the throttled
+// writer of the download.
+type throttledResponseWriter struct {
+	gin.ResponseWriter
+	body io.Writer
+}
+
+func (w *throttledResponseWriter) Write(b []byte) (int, error) {
+	return w.body.Write(b)
 }
 
-func (h *DownloadHandler) isPermsGranted_getUserId(ctx *gin.Context, log *logger.Log, permission string) (bool, int) {
+// mediaPath resolves the requested file and reports invalid or missing
+// paths.
+func (h *DownloadHandler) mediaPath(ctx *gin.Context, log *logger.Log, fileDir, fileName string) (string, bool) {
+	path, err := h.useCase.MediaPath(fileDir, fileName)
+	if err != nil {
+		if errors.Is(err, download.ErrInvalidPath) {
+			h.logUseCase.Report(ctx, log, msg.ErrorFilePathIsInvalid())
+		} else if errors.Is(err, os.ErrNotExist) {
+			h.logUseCase.Report(ctx, log, msg.ErrorFileIsNotExist())
+		} else {
+			h.logUseCase.Report(ctx, log, msg.ErrorCannotOpenFile(err))
+		}
+		return "", false
+	}
+	return path, true
+}
+
+// isPermsGranted_getUserId also returns the id of the API key making the
+// request, zero for the users signed in.
+func (h *DownloadHandler) isPermsGranted_getUserId(ctx *gin.Context, log *logger.Log, permission string) (bool, int, int) {
 	headerToken := h.authUseCase.ReadHeader(ctx)
 	if !h.authUseCase.IsTokenExists(headerToken) {
 		h.logUseCase.Report(ctx, log, msg.ErrorYouHaveNotEnoughPermissions())
-		return false, -1
+		return false, -1, 0
 	}
 
-	session, err := h.sessUseCase.GetSessionAndDate(headerToken)
+	session, err := h.sessUseCase.GetSessionAndDate(ctx, headerToken)
 	if err != nil {
 		h.logUseCase.Report(ctx, log, msg.ErrorCannotGetSessionAndDate(err))
-		return false, -1
+		return false, -1, 0
 	}
 	if !h.authUseCase.IsSessionExists(session) {
 		h.logUseCase.Report(ctx, log, msg.ErrorYouHaveNotEnoughPermissions())
-		return false, -1
+		return false, -1, 0
 	}
 
-	if timedate.IsDateExpired(session.CreationDate, h.cfg.SessionTTLHours) {
+	if timedate.IsDateExpired(session.RefreshDate, h.cfg.SessionTTLHours) {
 		if err := h.sessUseCase.DeleteSession(headerToken); err != nil {
 			h.logUseCase.Report(ctx, log, msg.ErrorCannotDeleteSession(err))
-			return false, -1
+			return false, -1, 0
 		}
 		h.logUseCase.Report(ctx, log, msg.ErrorYouHaveNotEnoughPermissions())
-		return false, -1
-	}
-
-	headerNamepass, err := h.authUseCase.ParseToken(headerToken)
-	if err != nil {
-		h.logUseCase.Report(ctx, log, msg.ErrorCannotParseToken(err))
-		return false, -1
+		return false, -1, 0
 	}
 
-	gottenUserId, err := h.userUseCase.GetUserId(headerNamepass.Username)
+	gottenUserId, err := h.userUseCase.GetUserId(session.Username)
 	if err != nil {
 		h.logUseCase.Report(ctx, log, msg.ErrorCannotCheckUserExistence(err))
-		return false, -1
+		return false, -1, 0
 	}
 	if gottenUserId < 0 {
 		if err := h.sessUseCase.DeleteSession(headerToken); err != nil {
 			h.logUseCase.Report(ctx, log, msg.ErrorCannotDeleteSession(err))
-			return false, -1
+			return false, -1, 0
 		}
 		h.logUseCase.Report(ctx, log, msg.ErrorUserWithThisUsernameIsNotExist())
-		return false, -1
+		return false, -1, 0
 	}
 
-	log.SessionOwner = headerNamepass.Username
+	log.SessionOwner = session.Username
+
+	// The API keys are granted the permissions of their scopes which their
+	// users still hold
+	if session.ApiKeyId != 0 {
+		granted, err := h.sessUseCase.IsApiKeyGranted(session, permission)
+		if err != nil {
+			h.logUseCase.Report(ctx, log, msg.ErrorCannotCheckApiKeyScopes(err))
+			return false, -1, 0
+		}
+		if !granted {
+			h.logUseCase.Report(ctx, log, msg.ErrorYouHaveNotEnoughPermissions())
+			return false, -1, 0
+		}
+		return true, gottenUserId, session.ApiKeyId
+	}
 
 	isSUorStaff := false
 	hasPersonalPerm := false
-	if isSUorStaff, err = h.userUseCase.IsUserSuperuserOrStaff(headerNamepass.Username); err != nil {
+	if isSUorStaff, err = h.userUseCase.IsUserSuperuserOrStaff(session.Username); err != nil {
 		h.logUseCase.Report(ctx, log, msg.ErrorCannotCheckSuperuserStaffPermissions(err))
-		return false, -1
+		return false, -1, 0
 	}
 	if !isSUorStaff {
 		if hasPersonalPerm, err = h.userUseCase.IsUserHavePersonalPermission(gottenUserId, permission); err != nil {
 			h.logUseCase.Report(ctx, log, msg.ErrorCannotCheckPersonalPermission(err))
-			return false, -1
+			return false, -1, 0
 		}
 	}
 
 	if !isSUorStaff && !hasPersonalPerm {
 		h.logUseCase.Report(ctx, log, msg.ErrorYouHaveNotEnoughPermissions())
-		return false, -1
+		return false, -1, 0
 	}
 
-	return true, gottenUserId
+	return true, gottenUserId, 0
 }
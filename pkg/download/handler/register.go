@@ -2,19 +2,31 @@ package handler
 
 import (
 	"github.com/gin-gonic/gin"
+	"vhosting/internal/audit"
 	sess "vhosting/internal/session"
+	"vhosting/internal/video"
 	"vhosting/pkg/auth"
 	"vhosting/pkg/config"
 	"vhosting/pkg/download"
+	"vhosting/pkg/export"
 	"vhosting/pkg/logger"
 	"vhosting/pkg/user"
 )
 
-func RegisterHTTPEndpoints(router *gin.Engine, cfg *config.Config, uc download.DownloadUseCase, luc logger.LogUseCase, auc auth.AuthUseCase, suc sess.SessUseCase, uuc user.UserUseCase) {
-	h := NewDownloadHandler(cfg, uc, luc, auc, suc, uuc)
+func RegisterHTTPEndpoints(router *gin.Engine, cfg *config.Config, uc download.DownloadUseCase, luc logger.LogUseCase, auc auth.AuthUseCase, suc sess.SessUseCase, uuc user.UserUseCase, vuc video.VideoUseCase, euc export.ExportUseCase, aduc audit.AuditUseCase) {
+	h := NewDownloadHandler(cfg, uc, luc, auc, suc, uuc, vuc, euc, aduc)
 
 	downloadRoute := router.Group("/download")
 	{
 		downloadRoute.GET("/:file_dir/:file_name", h.DownloadFile)
+		downloadRoute.POST("/bundle", h.DownloadBundle)
+		downloadRoute.POST("/revoke", h.RevokeDownloadLinks)
+		downloadRoute.POST("/revoke/:id", h.RevokeUserDownloadLinks)
+	}
+
+	mediaRoute := router.Group("/media")
+	{
+		mediaRoute.GET("/:file_dir/:file_name", h.ServeMedia)
+		mediaRoute.HEAD("/:file_dir/:file_name", h.ServeMedia)
 	}
 }
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"vhosting/pkg/config"
+	qconsts "vhosting/pkg/constants/query"
+	"vhosting/pkg/db_connect"
+	"vhosting/pkg/download"
+)
+
+type DownloadRepository struct {
+	cfg *config.Config
+}
+
+func NewDownloadRepository(cfg *config.Config) *DownloadRepository {
+	return &DownloadRepository{cfg: cfg}
+}
+
+func (r *DownloadRepository) SetRevokeDate(userId int, date time.Time) error {
+	db := db_connect.CreateLocalDBConnection(r.cfg)
+	defer db_connect.CloseDBConnection(r.cfg, db)
+
+	template := qconsts.UPSERT_INTO_TBL_VALUES_VAL_ON_COL
+	tbl := fmt.Sprintf("%s (%s, %s)", download.RevTableName,
+		download.RevUserId, download.RevRevokeDate)
+	val := "($1, $2)"
+	col := download.RevUserId
+	set := fmt.Sprintf("%s=$2", download.RevRevokeDate)
+	query := fmt.Sprintf(template, tbl, val, col, set)
+
+	rows, err := db.Query(query, userId, date)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	return nil
+}
+
+// GetRevokeDate returns the zero time if the user links were never revoked.
+func (r *DownloadRepository) GetRevokeDate(userId int) (time.Time, error) {
+	db := db_connect.CreateLocalDBConnection(r.cfg)
+	defer db_connect.CloseDBConnection(r.cfg, db)
+
+	template := qconsts.SELECT_COL_FROM_TBL_WHERE_CND
+	col := download.RevRevokeDate
+	tbl := download.RevTableName
+	cnd := fmt.Sprintf("%s=$1", download.RevUserId)
+	query := fmt.Sprintf(template, col, tbl, cnd)
+
+	rows, err := db.Query(query, userId)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer rows.Close()
+
+	var date time.Time
+	if rows.Next() {
+		if err := rows.Scan(&date); err != nil {
+			return time.Time{}, err
+		}
+	}
+
+	return date, rows.Err()
+}
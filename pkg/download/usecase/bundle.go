@@ -0,0 +1,123 @@
+package usecase
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"vhosting/pkg/download"
+)
+
+const manifestFile = "manifest.json"
+
+func (u *DownloadUseCase) BindJSONBundleRequest(ctx *gin.Context) (*download.BundleRequest, error) {
+	var req download.BundleRequest
+	if err := ctx.BindJSON(&req); err != nil {
+		return &req, err
+	}
+	return &req, nil
+}
+
+func (u *DownloadUseCase) IsBundleEmpty(req *download.BundleRequest) bool {
+	return len(req.VideoIds) == 0 && len(req.ExportIds) == 0
+}
+
+func (u *DownloadUseCase) IsBundleTooLarge(req *download.BundleRequest) bool {
+	return len(req.VideoIds)+len(req.ExportIds) > u.cfg.DownloadBundleMaxItems
+}
+
+// WriteBundle streams the files into a ZIP archive followed by the manifest.
+// The videos are compressed already, so the files are stored as they are.
+// The bytes of every file written to w are counted in its Sent.
+func (u *DownloadUseCase) WriteBundle(w io.Writer, items []*download.BundleItem, createdBy string) error {
+	cw := &countingWriter{w: w}
+	zw := zip.NewWriter(cw)
+
+	for _, item := range items {
+		if err := writeBundleItem(zw, cw, item); err != nil {
+			return err
+		}
+	}
+
+	manifest := download.BundleManifest{
+		CreationDate: time.Now().Format(time.RFC3339),
+		CreatedBy:    createdBy,
+		Items:        items,
+	}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	fw, err := zw.CreateHeader(&zip.FileHeader{
+		Name:     manifestFile,
+		Method:   zip.Deflate,
+		Modified: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := fw.Write(data); err != nil {
+		return err
+	}
+
+	return zw.Close()
+}
+
+func writeBundleItem(zw *zip.Writer, cw *countingWriter, item *download.BundleItem) error {
+	file, err := os.Open(item.Path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	fw, err := zw.CreateHeader(&zip.FileHeader{
+		Name:     item.Name,
+		Method:   zip.Store,
+		Modified: info.ModTime(),
+	})
+	if err != nil {
+		return err
+	}
+
+	// The header is flushed so only the file data is counted
+	if err := zw.Flush(); err != nil {
+		return err
+	}
+	start := cw.n
+
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(fw, hash), file)
+	if err == nil {
+		err = zw.Flush()
+	}
+	item.Sent = cw.n - start
+	if err != nil {
+		return err
+	}
+	item.Size = size
+	item.SHA256 = hex.EncodeToString(hash.Sum(nil))
+
+	return nil
+}
+
+// countingWriter counts the bytes accepted by the writer.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
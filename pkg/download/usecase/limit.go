@@ -0,0 +1,173 @@
+package usecase
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"vhosting/pkg/download"
+)
+
+const throttleChunkBytes = 16 * 1024
+
+// bucket is a token bucket of bytes. The tokens may go below zero, the debt
+// makes the next writers wait longer.
+type bucket struct {
+	mu     sync.Mutex
+	rate   float64 // bytes per second
+	tokens float64
+	last   time.Time
+}
+
+func newBucket(kbps int) *bucket {
+	rate := float64(kbps) * 1000 / 8
+	return &bucket{rate: rate, tokens: rate, last: time.Now()}
+}
+
+// reserve takes n bytes and returns the time to wait before sending them.
+func (b *bucket) reserve(n int) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.rate {
+		b.tokens = b.rate // a second of burst
+	}
+	b.last = now
+
+	b.tokens -= float64(n)
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// AcquireDownload reserves a download slot, waiting in the queue when all
+// the slots are taken. The returned writer is throttled by the global and
+// the user rate limits, release must be called when the download ends.
+func (u *DownloadUseCase) AcquireDownload(ctx context.Context, userId int, w io.Writer) (io.Writer, func(), error) {
+	if err := u.acquireSlot(ctx); err != nil {
+		return nil, nil, err
+	}
+
+	u.limitMutex.Lock()
+	defer u.limitMutex.Unlock()
+
+	u.userDownloads[userId]++
+	if u.cfg.DownloadUserRateKbps > 0 && u.userBuckets[userId] == nil {
+		u.userBuckets[userId] = newBucket(u.cfg.DownloadUserRateKbps)
+	}
+
+	tw := &throttledWriter{ctx: ctx, w: w}
+	if u.globalBucket != nil {
+		tw.buckets = append(tw.buckets, u.globalBucket)
+	}
+	if b := u.userBuckets[userId]; b != nil {
+		tw.buckets = append(tw.buckets, b)
+	}
+
+	release := func() {
+		u.limitMutex.Lock()
+		defer u.limitMutex.Unlock()
+
+		if u.userDownloads[userId]--; u.userDownloads[userId] <= 0 {
+			delete(u.userDownloads, userId)
+			delete(u.userBuckets, userId)
+		}
+		if u.slots != nil {
+			<-u.slots
+		}
+	}
+
+	return tw, release, nil
+}
+
+// acquireSlot takes a free slot of the concurrent downloads. When there is
+// none the request waits in the queue up to the queue timeout.
+func (u *DownloadUseCase) acquireSlot(ctx context.Context) error {
+	if u.slots == nil {
+		return nil
+	}
+
+	select {
+	case u.slots <- struct{}{}:
+		return nil
+	default:
+	}
+
+	u.limitMutex.Lock()
+	if u.queued >= u.cfg.DownloadMaxQueued {
+		u.limitMutex.Unlock()
+		return download.ErrQueueIsFull
+	}
+	u.queued++
+	u.limitMutex.Unlock()
+
+	defer func() {
+		u.limitMutex.Lock()
+		u.queued--
+		u.limitMutex.Unlock()
+	}()
+
+	timer := time.NewTimer(time.Duration(u.cfg.DownloadQueueTimeoutSeconds) * time.Second)
+	defer timer.Stop()
+
+	select {
+	case u.slots <- struct{}{}:
+		return nil
+	case <-timer.C:
+		return download.ErrQueueTimeout
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+type throttledWriter struct {
+	ctx     context.Context
+	w       io.Writer
+	buckets []*bucket
+}
+
+// Write sends the data by chunks, waiting for the slowest bucket before
+// every chunk.
+func (t *throttledWriter) Write(p []byte) (int, error) {
+	if len(t.buckets) == 0 {
+		return t.w.Write(p)
+	}
+
+	written := 0
+	for len(p) > 0 {
+		n := len(p)
+		if n > throttleChunkBytes {
+			n = throttleChunkBytes
+		}
+
+		var delay time.Duration
+		for _, b := range t.buckets {
+			if d := b.reserve(n); d > delay {
+				delay = d
+			}
+		}
+
+		if delay > 0 {
+			timer := time.NewTimer(delay)
+			select {
+			case <-timer.C:
+			case <-t.ctx.Done():
+				timer.Stop()
+				return written, t.ctx.Err()
+			}
+		}
+
+		m, err := t.w.Write(p[:n])
+		written += m
+		if err != nil {
+			return written, err
+		}
+		p = p[n:]
+	}
+
+	return written, nil
+}
@@ -0,0 +1,76 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"vhosting/pkg/config"
+	"vhosting/pkg/deadline"
+)
+
+// TestThrottledDownloadOutlivesWriteTimeout sends a throttled 100 MB body
+// which takes longer than the write timeout of the server, after waiting in
+// the queue for longer than the timeout too, and checks it arrives complete.
+func TestThrottledDownloadOutlivesWriteTimeout(t *testing.T) {
+	const size = 100 << 20
+	const writeTimeout = time.Second
+
+	u := NewDownloadUseCase(&config.Config{
+		DownloadGlobalRateKbps:      size * 8 / 1000 / 2, // two seconds for the body
+		DownloadMaxConcurrent:       1,
+		DownloadMaxQueued:           1,
+		DownloadQueueTimeoutSeconds: 10,
+	}, nil)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &http.Server{
+		WriteTimeout: writeTimeout,
+		ConnContext:  deadline.SaveConn,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, release, err := u.AcquireDownload(r.Context(), 1,
+				deadline.NewResponseWriter(w, r, writeTimeout))
+			deadline.Extend(r, writeTimeout)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusServiceUnavailable)
+				return
+			}
+			defer release()
+			io.Copy(body, bytes.NewReader(make([]byte, size)))
+		}),
+	}
+	go srv.Serve(ln)
+	defer srv.Close()
+
+	// Take the only slot so the download waits in the queue
+	_, release, err := u.AcquireDownload(context.Background(), 2, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.AfterFunc(2*writeTimeout, release)
+
+	start := time.Now()
+	resp, err := http.Get("http://" + ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	n, err := io.Copy(io.Discard, resp.Body)
+	if err != nil {
+		t.Fatalf("download is cut off after %d bytes in %s: %v", n, time.Since(start), err)
+	}
+	if n != size {
+		t.Fatalf("got %d bytes, want %d", n, size)
+	}
+	if elapsed := time.Since(start); elapsed < 3*writeTimeout {
+		t.Fatalf("download took %s, it is not throttled", elapsed)
+	}
+}
@@ -1,35 +1,138 @@
 package usecase
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"vhosting/pkg/config"
 	"vhosting/pkg/download"
+	"vhosting/pkg/mediadir"
 )
 
 type DownloadUseCase struct {
-	cfg *config.Config
+	cfg          *config.Config
+	downloadRepo download.DownloadRepository
+
+	limitMutex    sync.Mutex
+	slots         chan struct{} // nil when concurrent downloads are not limited
+	queued        int
+	globalBucket  *bucket
+	userBuckets   map[int]*bucket
+	userDownloads map[int]int
 }
 
-func NewDownloadUseCase(cfg *config.Config) *DownloadUseCase {
-	return &DownloadUseCase{
-		cfg: cfg,
+func NewDownloadUseCase(cfg *config.Config, downloadRepo download.DownloadRepository) *DownloadUseCase {
+	u := &DownloadUseCase{
+		cfg:           cfg,
+		downloadRepo:  downloadRepo,
+		userBuckets:   map[int]*bucket{},
+		userDownloads: map[int]int{},
+	}
+	if cfg.DownloadMaxConcurrent > 0 {
+		u.slots = make(chan struct{}, cfg.DownloadMaxConcurrent)
 	}
+	if cfg.DownloadGlobalRateKbps > 0 {
+		u.globalBucket = newBucket(cfg.DownloadGlobalRateKbps)
+	}
+	return u
 }
 
 func (u *DownloadUseCase) IsValidExtension(file_name string) bool {
-	extension := file_name[len(file_name)-4:]
-	strings.ToLower(extension)
-	if extension != ".mp4" {
-		return false
-	}
-	return true
+	return strings.EqualFold(filepath.Ext(file_name), ".mp4")
 }
 
-func (u *DownloadUseCase) CreateDownloadLink(local_file_path string) *download.Download {
+// CreateDownloadLink signs the file path for the user. The link carries the
+// user ID, the issue and the expiration time in milliseconds.
+func (u *DownloadUseCase) CreateDownloadLink(local_file_path string, userId int) *download.Download {
+	issued := time.Now()
+	expires := issued.Add(time.Duration(u.cfg.DownloadLinkTTLMinutes) * time.Minute)
+
+	urlparams := url.Values{}
+	urlparams.Set("uid", strconv.Itoa(userId))
+	urlparams.Set("iat", strconv.FormatInt(issued.UnixMilli(), 10))
+	urlparams.Set("exp", strconv.FormatInt(expires.UnixMilli(), 10))
+	urlparams.Set("sig", u.sign(local_file_path, userId, issued.UnixMilli(), expires.UnixMilli()))
+
 	var dload download.Download
-	dload.DownloadLink = fmt.Sprintf("http://%s:%d/media/%s", u.cfg.ServerIP,
-		u.cfg.ServerPort, local_file_path)
+	dload.DownloadLink = fmt.Sprintf("http://%s:%d/media/%s?%s", u.cfg.ServerIP,
+		u.cfg.ServerPort, local_file_path, urlparams.Encode())
+	dload.ExpirationDate = expires.Format(time.RFC3339)
 	return &dload
 }
+
+// VerifyDownloadLink checks the signature, the expiration time and that the
+// link was issued after the last revocation of the user links. It returns
+// the ID of the user the link was issued to.
+func (u *DownloadUseCase) VerifyDownloadLink(local_file_path string, urlparams url.Values) (int, error) {
+	userId, err := strconv.Atoi(urlparams.Get("uid"))
+	if err != nil {
+		return -1, download.ErrLinkInvalid
+	}
+	issued, err := strconv.ParseInt(urlparams.Get("iat"), 10, 64)
+	if err != nil {
+		return -1, download.ErrLinkInvalid
+	}
+	expires, err := strconv.ParseInt(urlparams.Get("exp"), 10, 64)
+	if err != nil {
+		return -1, download.ErrLinkInvalid
+	}
+
+	expected := u.sign(local_file_path, userId, issued, expires)
+	if !hmac.Equal([]byte(urlparams.Get("sig")), []byte(expected)) {
+		return -1, download.ErrLinkInvalid
+	}
+	if time.Now().UnixMilli() > expires {
+		return -1, download.ErrLinkExpired
+	}
+
+	revokeDate, err := u.downloadRepo.GetRevokeDate(userId)
+	if err != nil {
+		return -1, err
+	}
+	if !revokeDate.IsZero() && issued <= revokeDate.UnixMilli() {
+		return -1, download.ErrLinkRevoked
+	}
+
+	return userId, nil
+}
+
+// RevokeDownloadLinks makes all the links issued to the user so far invalid.
+func (u *DownloadUseCase) RevokeDownloadLinks(userId int) error {
+	return u.downloadRepo.SetRevokeDate(userId, time.Now())
+}
+
+// MediaPath resolves the file in the media directory. Both parts must be
+// plain names, and symlinks must not lead out of the directory.
+func (u *DownloadUseCase) MediaPath(fileDir, fileName string) (string, error) {
+	for _, part := range []string{fileDir, fileName} {
+		if part == "" || part == "." || part == ".." ||
+			strings.ContainsAny(part, "/\\\x00") {
+			return "", download.ErrInvalidPath
+		}
+	}
+
+	path, err := mediadir.Resolve(u.cfg.MediaDir, filepath.Join(fileDir, fileName))
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(path); err != nil {
+		return "", err
+	}
+
+	return path, nil
+}
+
+func (u *DownloadUseCase) sign(local_file_path string, userId int, issued, expires int64) string {
+	mac := hmac.New(sha256.New, []byte(u.cfg.HashingDownloadSigningKey))
+	fmt.Fprintf(mac, "%s\n%d\n%d\n%d", local_file_path, userId, issued, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
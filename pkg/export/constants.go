@@ -0,0 +1,27 @@
+package export
+
+const (
+	TableName       = "exports"
+	Id              = "id"
+	UserId          = "user_id"
+	StreamId        = "stream_id"
+	PathStream      = "path_stream"
+	From            = "from_time"
+	To              = "to_time"
+	State           = "state"
+	Progress        = "progress"
+	Error           = "error"
+	Accurate        = "accurate"
+	ActualFrom      = "actual_from"
+	ActualTo        = "actual_to"
+	OverlaySettings = "overlay"
+	MediaInfo       = "media"
+	FilePath        = "file_path"
+	CreationDate    = "creation_date"
+	UpdateDate      = "update_date"
+)
+
+const (
+	DatetimeLayout       = "2006-01-02 15:04:05"
+	ActualDatetimeLayout = "2006-01-02 15:04:05.000"
+)
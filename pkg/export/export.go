@@ -0,0 +1,121 @@
+package export
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"vhosting/pkg/archive"
+	"vhosting/pkg/delivery"
+)
+
+type Export struct {
+	Id           int      `json:"id"           db:"id"`
+	UserId       int      `json:"userId"       db:"user_id"`
+	StreamId     int      `json:"streamId"     db:"stream_id"`
+	PathStream   string   `json:"pathStream"   db:"path_stream"`
+	From         string   `json:"from"         db:"from_time"`
+	To           string   `json:"to"           db:"to_time"`
+	State        string   `json:"state"        db:"state"`
+	Progress     int      `json:"progress"     db:"progress"` // percent
+	Error        string   `json:"error"        db:"error"`
+	Accurate     bool     `json:"accurate"     db:"accurate"`
+	ActualFrom   string   `json:"actualFrom"   db:"actual_from"` // real start of the clip
+	ActualTo     string   `json:"actualTo"     db:"actual_to"`
+	Overlay      *Overlay `json:"overlay"      db:"overlay"`
+	Media        *Media   `json:"media"        db:"media"` // probed when done
+	FilePath     string   `json:"-"            db:"file_path"`
+	CreationDate string   `json:"creationDate" db:"creation_date"`
+	UpdateDate   string   `json:"updateDate"   db:"update_date"`
+
+	Deliveries []*delivery.Delivery `json:"deliveries" db:"-"`
+}
+
+type ExportRequest struct {
+	StreamId int      `json:"streamId"`
+	From     string   `json:"from"` // "2006-01-02 15:04:05" or RFC3339
+	To       string   `json:"to"`
+	Accurate bool     `json:"accurate"` // trim to the exact period
+	Overlay  *Overlay `json:"overlay"`
+}
+
+// Overlay is burned into the exported picture. Text may contain {camera},
+// {user} and {time} placeholders, Logo is a PNG file name in the logo
+// directory. Camera and User are set by the server and stored with the
+// export as the values used.
+type Overlay struct {
+	Text     string `json:"text"`
+	Position string `json:"position"`
+	FontSize int    `json:"fontSize"`
+	Logo     string `json:"logo"`
+	Camera   string `json:"camera"`
+	User     string `json:"user"`
+}
+
+// Value stores the overlay as JSON text, nil overlay is stored as NULL.
+func (o Overlay) Value() (driver.Value, error) {
+	val, err := json.Marshal(o)
+	if err != nil {
+		return nil, err
+	}
+	return string(val), nil
+}
+
+func (o *Overlay) Scan(src interface{}) error {
+	switch val := src.(type) {
+	case string:
+		return json.Unmarshal([]byte(val), o)
+	case []byte:
+		return json.Unmarshal(val, o)
+	}
+	return errors.New("cannot scan overlay")
+}
+
+// Media is the metadata probed from the exported clip, stored as JSON text.
+type Media archive.Media
+
+func (m Media) Value() (driver.Value, error) {
+	val, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	return string(val), nil
+}
+
+func (m *Media) Scan(src interface{}) error {
+	switch val := src.(type) {
+	case string:
+		return json.Unmarshal([]byte(val), m)
+	case []byte:
+		return json.Unmarshal(val, m)
+	}
+	return errors.New("cannot scan media")
+}
+
+type ExportCommon interface {
+	GetExport(id int) (*Export, error)
+	IsExportExists(id int) (bool, error)
+}
+
+type ExportUseCase interface {
+	ExportCommon
+
+	ServeExports()
+	CreateExport(exp *Export) (int, error)
+
+	BindJSONExportRequest(ctx *gin.Context) (*ExportRequest, error)
+	ParseExportPeriod(req *ExportRequest) (time.Time, time.Time, bool)
+	IsExportPeriodTooLong(from, to time.Time) bool
+	AtoiRequestedId(ctx *gin.Context) (int, error)
+	PrepareOverlay(overlay *Overlay) error
+}
+
+type ExportRepository interface {
+	ExportCommon
+
+	CreateExport(exp *Export) (int, error)
+	UpdateExport(exp *Export) error
+	GetExportsByState(state string) ([]*Export, error)
+}
@@ -0,0 +1,316 @@
+package handler
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"vhosting/internal/audit"
+	msg "vhosting/internal/messages"
+	sess "vhosting/internal/session"
+	"vhosting/pkg/archive"
+	"vhosting/pkg/auth"
+	"vhosting/pkg/config"
+	"vhosting/pkg/deadline"
+	"vhosting/pkg/export"
+	"vhosting/pkg/export/usecase"
+	"vhosting/pkg/logger"
+	"vhosting/pkg/stream"
+	"vhosting/pkg/timedate"
+	"vhosting/pkg/user"
+)
+
+type ExportHandler struct {
+	cfg           *config.Config
+	useCase       export.ExportUseCase
+	logUseCase    logger.LogUseCase
+	authUseCase   auth.AuthUseCase
+	sessUseCase   sess.SessUseCase
+	userUseCase   user.UserUseCase
+	streamUseCase stream.StreamUseCase
+	auditUseCase  audit.AuditUseCase
+}
+
+func NewExportHandler(cfg *config.Config, useCase export.ExportUseCase,
+	logUseCase logger.LogUseCase, authUseCase auth.AuthUseCase,
+	sessUseCase sess.SessUseCase, userUseCase user.UserUseCase,
+	streamUseCase stream.StreamUseCase, auditUseCase audit.AuditUseCase) *ExportHandler {
+	return &ExportHandler{
+		cfg:           cfg,
+		useCase:       useCase,
+		logUseCase:    logUseCase,
+		authUseCase:   authUseCase,
+		sessUseCase:   sessUseCase,
+		userUseCase:   userUseCase,
+		streamUseCase: streamUseCase,
+		auditUseCase:  auditUseCase,
+	}
+}
+
+func (h *ExportHandler) CreateExport(ctx *gin.Context) {
+	actPermission := "post_export"
+
+	log := logger.Init(ctx)
+
+	hasPerms, userId, _ := h.isPermsGranted_getUserId(ctx, log, actPermission)
+	if !hasPerms {
+		return
+	}
+
+	req, err := h.useCase.BindJSONExportRequest(ctx)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotBindInputData(err))
+		return
+	}
+
+	exists, err := h.streamUseCase.IsStreamExists(req.StreamId)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotCheckStreamExistence(err))
+		return
+	}
+	if !exists {
+		h.logUseCase.Report(ctx, log, msg.ErrorStreamWithRequestedIDIsNotExist())
+		return
+	}
+
+	from, to, ok := h.useCase.ParseExportPeriod(req)
+	if !ok {
+		h.logUseCase.Report(ctx, log, msg.ErrorExportPeriodIsInvalid())
+		return
+	}
+	if h.useCase.IsExportPeriodTooLong(from, to) {
+		h.logUseCase.Report(ctx, log, msg.ErrorExportPeriodIsTooLong(h.cfg.ExportMaxDurationMinutes))
+		return
+	}
+
+	if req.Overlay != nil {
+		if err := h.useCase.PrepareOverlay(req.Overlay); err != nil {
+			h.logUseCase.Report(ctx, log, msg.ErrorExportOverlayIsInvalid(err))
+			return
+		}
+	}
+
+	gottenStream, err := h.streamUseCase.GetStream(req.StreamId)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotGetStream(err))
+		return
+	}
+
+	if req.Overlay != nil {
+		req.Overlay.Camera = gottenStream.Stream
+		if gottenStream.Camera != nil && gottenStream.Camera.DisplayName != "" {
+			req.Overlay.Camera = gottenStream.Camera.DisplayName
+		}
+		req.Overlay.User = log.SessionOwner
+	}
+
+	exp := &export.Export{
+		UserId:     userId,
+		StreamId:   req.StreamId,
+		PathStream: gottenStream.PathStream,
+		From:       from.Format(export.DatetimeLayout),
+		To:         to.Format(export.DatetimeLayout),
+		Accurate:   req.Accurate,
+		Overlay:    req.Overlay,
+	}
+
+	id, err := h.useCase.CreateExport(exp)
+	if err != nil {
+		if errors.Is(err, usecase.ErrQueueIsFull) {
+			h.logUseCase.Report(ctx, log, msg.ErrorExportQueueIsFull())
+			return
+		}
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotCreateExport(err))
+		return
+	}
+
+	gottenExport, err := h.useCase.GetExport(id)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotGetExport(err))
+		return
+	}
+
+	h.logUseCase.Report(ctx, log, msg.InfoExportCreated(gottenExport))
+}
+
+func (h *ExportHandler) GetExport(ctx *gin.Context) {
+	log := logger.Init(ctx)
+
+	gottenExport, _, ok := h.getOwnExport(ctx, log)
+	if !ok {
+		return
+	}
+
+	h.logUseCase.Report(ctx, log, msg.InfoGotExport(gottenExport))
+}
+
+func (h *ExportHandler) GetExportFile(ctx *gin.Context) {
+	log := logger.Init(ctx)
+
+	gottenExport, userId, ok := h.getOwnExport(ctx, log)
+	if !ok {
+		return
+	}
+
+	if gottenExport.State != archive.StateDone {
+		h.logUseCase.Report(ctx, log, msg.ErrorExportFileIsNotReady(gottenExport.State))
+		return
+	}
+	if _, err := os.Stat(gottenExport.FilePath); err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorExportFileIsNotExist())
+		return
+	}
+
+	acc := &audit.Access{
+		Kind:      audit.KindExport,
+		UserId:    userId,
+		Stream:    gottenExport.PathStream,
+		ExportId:  gottenExport.Id,
+		ClientIP:  ctx.ClientIP(),
+		StartDate: timedate.GetTimestamp(),
+	}
+
+	// The file may take longer to send than the write timeout of the server
+	writeTimeout := time.Duration(h.cfg.ServerWriteTimeoutSeconds) * time.Second
+	ctx.Header("Content-Disposition", mime.FormatMediaType("attachment",
+		map[string]string{"filename": "export_" + ctx.Param("id") + ".mp4"}))
+	http.ServeFile(deadline.NewResponseWriter(ctx.Writer, ctx.Request, writeTimeout), ctx.Request,
+		gottenExport.FilePath)
+
+	acc.BytesSent = int64(ctx.Writer.Size())
+	h.auditUseCase.RecordAccess(acc)
+}
+
+// getOwnExport reads the requested export with the id of the requesting
+// user, the export is available to the user who created it and to
+// superusers and staff, but not to their API keys.
+func (h *ExportHandler) getOwnExport(ctx *gin.Context, log *logger.Log) (*export.Export, int, bool) {
+	actPermission := "post_export"
+
+	hasPerms, userId, apiKeyId := h.isPermsGranted_getUserId(ctx, log, actPermission)
+	if !hasPerms {
+		return nil, -1, false
+	}
+
+	reqId, err := h.useCase.AtoiRequestedId(ctx)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotConvertRequestedIDToTypeInt(err))
+		return nil, -1, false
+	}
+
+	exists, err := h.useCase.IsExportExists(reqId)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotCheckExportExistence(err))
+		return nil, -1, false
+	}
+	if !exists {
+		h.logUseCase.Report(ctx, log, msg.ErrorExportWithRequestedIDIsNotExist())
+		return nil, -1, false
+	}
+
+	gottenExport, err := h.useCase.GetExport(reqId)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotGetExport(err))
+		return nil, -1, false
+	}
+
+	if gottenExport.UserId != userId {
+		// The API keys have their scopes only, not the access of the staff
+		isSUorStaff := false
+		if apiKeyId == 0 {
+			if isSUorStaff, err = h.userUseCase.IsUserSuperuserOrStaff(log.SessionOwner); err != nil {
+				h.logUseCase.Report(ctx, log, msg.ErrorCannotCheckSuperuserStaffPermissions(err))
+				return nil, -1, false
+			}
+		}
+		if !isSUorStaff {
+			h.logUseCase.Report(ctx, log, msg.ErrorYouHaveNotEnoughPermissions())
+			return nil, -1, false
+		}
+	}
+
+	return gottenExport, userId, true
+}
+
+// isPermsGranted_getUserId also returns the id of the API key making the
+// request, zero for the users signed in.
+func (h *ExportHandler) isPermsGranted_getUserId(ctx *gin.Context, log *logger.Log, permission string) (bool, int, int) {
+	headerToken := h.authUseCase.ReadHeader(ctx)
+	if !h.authUseCase.IsTokenExists(headerToken) {
+		h.logUseCase.Report(ctx, log, msg.ErrorYouHaveNotEnoughPermissions())
+		return false, -1, 0
+	}
+
+	session, err := h.sessUseCase.GetSessionAndDate(ctx, headerToken)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotGetSessionAndDate(err))
+		return false, -1, 0
+	}
+	if !h.authUseCase.IsSessionExists(session) {
+		h.logUseCase.Report(ctx, log, msg.ErrorYouHaveNotEnoughPermissions())
+		return false, -1, 0
+	}
+
+	if timedate.IsDateExpired(session.RefreshDate, h.cfg.SessionTTLHours) {
+		if err := h.sessUseCase.DeleteSession(headerToken); err != nil {
+			h.logUseCase.Report(ctx, log, msg.ErrorCannotDeleteSession(err))
+			return false, -1, 0
+		}
+		h.logUseCase.Report(ctx, log, msg.ErrorYouHaveNotEnoughPermissions())
+		return false, -1, 0
+	}
+
+	gottenUserId, err := h.userUseCase.GetUserId(session.Username)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotCheckUserExistence(err))
+		return false, -1, 0
+	}
+	if gottenUserId < 0 {
+		if err := h.sessUseCase.DeleteSession(headerToken); err != nil {
+			h.logUseCase.Report(ctx, log, msg.ErrorCannotDeleteSession(err))
+			return false, -1, 0
+		}
+		h.logUseCase.Report(ctx, log, msg.ErrorUserWithThisUsernameIsNotExist())
+		return false, -1, 0
+	}
+
+	log.SessionOwner = session.Username
+
+	// The API keys are granted the permissions of their scopes which their
+	// users still hold
+	if session.ApiKeyId != 0 {
+		granted, err := h.sessUseCase.IsApiKeyGranted(session, permission)
+		if err != nil {
+			h.logUseCase.Report(ctx, log, msg.ErrorCannotCheckApiKeyScopes(err))
+			return false, -1, 0
+		}
+		if !granted {
+			h.logUseCase.Report(ctx, log, msg.ErrorYouHaveNotEnoughPermissions())
+			return false, -1, 0
+		}
+		return true, gottenUserId, session.ApiKeyId
+	}
+
+	isSUorStaff := false
+	hasPersonalPerm := false
+	if isSUorStaff, err = h.userUseCase.IsUserSuperuserOrStaff(session.Username); err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotCheckSuperuserStaffPermissions(err))
+		return false, -1, 0
+	}
+	if !isSUorStaff {
+		if hasPersonalPerm, err = h.userUseCase.IsUserHavePersonalPermission(gottenUserId, permission); err != nil {
+			h.logUseCase.Report(ctx, log, msg.ErrorCannotCheckPersonalPermission(err))
+			return false, -1, 0
+		}
+	}
+
+	if !isSUorStaff && !hasPersonalPerm {
+		h.logUseCase.Report(ctx, log, msg.ErrorYouHaveNotEnoughPermissions())
+		return false, -1, 0
+	}
+
+	return true, gottenUserId, 0
+}
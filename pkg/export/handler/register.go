@@ -0,0 +1,26 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"vhosting/internal/audit"
+	sess "vhosting/internal/session"
+	"vhosting/pkg/auth"
+	"vhosting/pkg/config"
+	"vhosting/pkg/export"
+	"vhosting/pkg/logger"
+	"vhosting/pkg/stream"
+	"vhosting/pkg/user"
+)
+
+func RegisterHTTPEndpoints(router *gin.Engine, cfg *config.Config, uc export.ExportUseCase, luc logger.LogUseCase,
+	auc auth.AuthUseCase, suc sess.SessUseCase, uuc user.UserUseCase, stuc stream.StreamUseCase,
+	aduc audit.AuditUseCase) {
+	h := NewExportHandler(cfg, uc, luc, auc, suc, uuc, stuc, aduc)
+
+	exportRoute := router.Group("/export")
+	{
+		exportRoute.POST("", h.CreateExport)
+		exportRoute.GET("/:id", h.GetExport)
+		exportRoute.GET("/:id/file", h.GetExportFile)
+	}
+}
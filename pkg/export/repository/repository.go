@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"fmt"
+
+	"vhosting/pkg/config"
+	qconsts "vhosting/pkg/constants/query"
+	"vhosting/pkg/db_connect"
+	"vhosting/pkg/export"
+	"vhosting/pkg/timedate"
+)
+
+var columns = fmt.Sprintf("%s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s",
+	export.Id, export.UserId, export.StreamId, export.PathStream, export.From,
+	export.To, export.State, export.Progress, export.Error, export.Accurate,
+	export.ActualFrom, export.ActualTo, export.OverlaySettings, export.MediaInfo,
+	export.FilePath, export.CreationDate, export.UpdateDate)
+
+type ExportRepository struct {
+	cfg *config.Config
+}
+
+func NewExportRepository(cfg *config.Config) *ExportRepository {
+	return &ExportRepository{cfg: cfg}
+}
+
+func (r *ExportRepository) CreateExport(exp *export.Export) (int, error) {
+	db := db_connect.CreateLocalDBConnection(r.cfg)
+	defer db_connect.CloseDBConnection(r.cfg, db)
+
+	exp.CreationDate = timedate.GetTimestamp()
+	exp.UpdateDate = exp.CreationDate
+
+	template := qconsts.INSERT_INTO_TBL_VALUES_VAL + " RETURNING %s"
+	tbl := fmt.Sprintf("%s (%s, %s, %s, %s, %s, %s, %s, %s, %s, %s)", export.TableName,
+		export.UserId, export.StreamId, export.PathStream, export.From,
+		export.To, export.State, export.Accurate, export.OverlaySettings,
+		export.CreationDate, export.UpdateDate)
+	val := "($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)"
+	query := fmt.Sprintf(template, tbl, val, export.Id)
+
+	var id int
+	if err := db.QueryRow(query, exp.UserId, exp.StreamId, exp.PathStream,
+		exp.From, exp.To, exp.State, exp.Accurate, exp.Overlay,
+		exp.CreationDate, exp.UpdateDate).Scan(&id); err != nil {
+		return -1, err
+	}
+
+	return id, nil
+}
+
+func (r *ExportRepository) GetExport(id int) (*export.Export, error) {
+	db := db_connect.CreateLocalDBConnection(r.cfg)
+	defer db_connect.CloseDBConnection(r.cfg, db)
+
+	template := qconsts.SELECT_COL_FROM_TBL_WHERE_CND
+	tbl := export.TableName
+	cnd := fmt.Sprintf("%s=$1", export.Id)
+	query := fmt.Sprintf(template, columns, tbl, cnd)
+
+	var exp export.Export
+	if err := db.Get(&exp, query, id); err != nil {
+		return nil, err
+	}
+
+	return &exp, nil
+}
+
+func (r *ExportRepository) GetExportsByState(state string) ([]*export.Export, error) {
+	db := db_connect.CreateLocalDBConnection(r.cfg)
+	defer db_connect.CloseDBConnection(r.cfg, db)
+
+	template := qconsts.SELECT_COL_FROM_TBL_WHERE_CND
+	tbl := export.TableName
+	cnd := fmt.Sprintf("%s=$1 ORDER BY %s", export.State, export.Id)
+	query := fmt.Sprintf(template, columns, tbl, cnd)
+
+	exports := []*export.Export{}
+	if err := db.Select(&exports, query, state); err != nil {
+		return nil, err
+	}
+
+	return exports, nil
+}
+
+func (r *ExportRepository) UpdateExport(exp *export.Export) error {
+	db := db_connect.CreateLocalDBConnection(r.cfg)
+	defer db_connect.CloseDBConnection(r.cfg, db)
+
+	exp.UpdateDate = timedate.GetTimestamp()
+
+	template := qconsts.UPDATE_TBL_SET_VAL_WHERE_CND
+	tbl := export.TableName
+	val := fmt.Sprintf("%s=$1, %s=$2, %s=$3, %s=$4, %s=$5, %s=$6, %s=$7, %s=$8",
+		export.State, export.Progress, export.Error, export.ActualFrom,
+		export.ActualTo, export.MediaInfo, export.FilePath, export.UpdateDate)
+	cnd := fmt.Sprintf("%s=$9", export.Id)
+	query := fmt.Sprintf(template, tbl, val, cnd)
+
+	rows, err := db.Query(query, exp.State, exp.Progress, exp.Error,
+		exp.ActualFrom, exp.ActualTo, exp.Media, exp.FilePath, exp.UpdateDate,
+		exp.Id)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	return nil
+}
+
+func (r *ExportRepository) IsExportExists(id int) (bool, error) {
+	db := db_connect.CreateLocalDBConnection(r.cfg)
+	defer db_connect.CloseDBConnection(r.cfg, db)
+
+	template := qconsts.SELECT_COL_FROM_TBL_WHERE_CND
+	col := export.Id
+	tbl := export.TableName
+	cnd := fmt.Sprintf("%s=$1", export.Id)
+	query := fmt.Sprintf(template, col, tbl, cnd)
+
+	rows, err := db.Query(query, id)
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	if isRowPresent := rows.Next(); !isRowPresent {
+		return false, nil
+	}
+
+	return true, nil
+}
@@ -0,0 +1,79 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"vhosting/pkg/archive"
+	"vhosting/pkg/export"
+)
+
+// gapTolerance is how much later than the end of a record the next one may
+// start without a gap, the record times are whole seconds.
+const gapTolerance = time.Second
+
+// segment places a record on the joined video.
+type segment struct {
+	start  time.Time     // record time
+	end    time.Time     // record time and the probed duration
+	offset time.Duration // start of the record in the joined video
+}
+
+// recordTimeline probes the records and places them one after another the
+// way they are joined.
+func (u *ExportUseCase) recordTimeline(ctx context.Context, records []*archive.Record) ([]segment, error) {
+	segments := make([]segment, len(records))
+	var offset time.Duration
+	for i, rec := range records {
+		duration, err := u.archiveUseCase.ProbeDuration(ctx, rec.Path)
+		if err != nil {
+			return nil, err
+		}
+		segments[i] = segment{start: rec.RecordTime, end: rec.RecordTime.Add(duration), offset: offset}
+		offset += duration
+	}
+	return segments, nil
+}
+
+// checkGaps reports the first gap between the records inside the period,
+// joining the records over it would cut the wrong footage.
+func checkGaps(segments []segment, from, to time.Time) error {
+	for i := 1; i < len(segments); i++ {
+		gapFrom, gapTo := segments[i-1].end, segments[i].start
+		if gapTo.Sub(gapFrom) <= gapTolerance {
+			continue
+		}
+		if gapFrom.Before(to) && gapTo.After(from) {
+			return fmt.Errorf("video records have a gap from %s to %s in requested period",
+				gapFrom.Format(export.ActualDatetimeLayout), gapTo.Format(export.ActualDatetimeLayout))
+		}
+	}
+	return nil
+}
+
+// toOffset converts the time into the offset in the joined video, the times
+// out of the records are moved to the nearest record.
+func toOffset(segments []segment, t time.Time) time.Duration {
+	for _, seg := range segments {
+		if t.Before(seg.start) {
+			return seg.offset
+		}
+		if t.Before(seg.end) {
+			return seg.offset + t.Sub(seg.start)
+		}
+	}
+	last := segments[len(segments)-1]
+	return last.offset + last.end.Sub(last.start)
+}
+
+// toTime converts the offset in the joined video into the time it was
+// recorded at.
+func toTime(segments []segment, offset time.Duration) time.Time {
+	for _, seg := range segments {
+		if offset < seg.offset+seg.end.Sub(seg.start) {
+			return seg.start.Add(offset - seg.offset)
+		}
+	}
+	return segments[len(segments)-1].end
+}
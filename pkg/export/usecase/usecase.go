@@ -0,0 +1,426 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	msg "vhosting/internal/messages"
+	"vhosting/pkg/archive"
+	"vhosting/pkg/config"
+	"vhosting/pkg/delivery"
+	"vhosting/pkg/export"
+	"vhosting/pkg/logger"
+)
+
+const queueSize = 100
+
+const (
+	defaultOverlayText     = "{camera} {time} {user}"
+	maxOverlayTextLength   = 200
+	defaultOverlayFontSize = 24
+	minOverlayFontSize     = 8
+	maxOverlayFontSize     = 200
+)
+
+var ErrQueueIsFull = errors.New("export queue is full")
+
+type ExportUseCase struct {
+	cfg             *config.Config
+	exportRepo      export.ExportRepository
+	archiveUseCase  archive.ArchiveUseCase
+	deliveryUseCase delivery.DeliveryUseCase
+	queue           chan int
+	progressMu      sync.RWMutex
+	progress        map[int]int
+}
+
+func NewExportUseCase(cfg *config.Config, exportRepo export.ExportRepository,
+	archiveUseCase archive.ArchiveUseCase, deliveryUseCase delivery.DeliveryUseCase) *ExportUseCase {
+	return &ExportUseCase{
+		cfg:             cfg,
+		exportRepo:      exportRepo,
+		archiveUseCase:  archiveUseCase,
+		deliveryUseCase: deliveryUseCase,
+		queue:           make(chan int, queueSize),
+		progress:        map[int]int{},
+	}
+}
+
+// ServeExports starts the export workers. Jobs left queued or running by
+// a previous run are processed again, unfinished deliveries are resumed.
+func (u *ExportUseCase) ServeExports() {
+	for _, dir := range []string{u.cfg.ArchiveTmpDir, u.cfg.ExportOutputDir} {
+		if err := os.MkdirAll(dir, 0777); err != nil {
+			logger.Print(msg.ErrorCannotCreateExportDir(err))
+			return
+		}
+	}
+
+	for i := 0; i < u.cfg.ExportWorkers; i++ {
+		go func() {
+			for id := range u.queue {
+				u.processExport(id)
+			}
+		}()
+	}
+
+	var ids []int
+	for _, state := range []string{archive.StateRunning, archive.StateQueued} {
+		exports, err := u.exportRepo.GetExportsByState(state)
+		if err != nil {
+			logger.Print(msg.ErrorCannotGetExport(err))
+			continue
+		}
+		for _, exp := range exports {
+			ids = append(ids, exp.Id)
+		}
+	}
+	go func() {
+		for _, id := range ids {
+			u.queue <- id
+		}
+	}()
+	go u.deliveryUseCase.ResumeDeliveries(context.Background(), delivery.SourceExport)
+
+	logger.Print(msg.InfoExportWorkersStarted(u.cfg.ExportWorkers))
+}
+
+// CreateExport saves the job and puts it into the queue.
+func (u *ExportUseCase) CreateExport(exp *export.Export) (int, error) {
+	if len(u.queue) == cap(u.queue) {
+		return -1, ErrQueueIsFull
+	}
+
+	exp.State = archive.StateQueued
+	id, err := u.exportRepo.CreateExport(exp)
+	if err != nil {
+		return -1, err
+	}
+
+	select {
+	case u.queue <- id:
+	default:
+		exp.Id = id
+		exp.State = archive.StateFailed
+		exp.Error = ErrQueueIsFull.Error()
+		if err := u.exportRepo.UpdateExport(exp); err != nil {
+			return -1, err
+		}
+		return -1, ErrQueueIsFull
+	}
+
+	return id, nil
+}
+
+// GetExport returns the job with the progress of a running ffmpeg and the
+// delivery states.
+func (u *ExportUseCase) GetExport(id int) (*export.Export, error) {
+	exp, err := u.exportRepo.GetExport(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if exp.Deliveries, err = u.deliveryUseCase.GetDeliveries(delivery.SourceExport, id); err != nil {
+		return nil, err
+	}
+
+	u.progressMu.RLock()
+	if progress, ok := u.progress[id]; ok && exp.State == archive.StateRunning {
+		exp.Progress = progress
+	}
+	u.progressMu.RUnlock()
+
+	return exp, nil
+}
+
+func (u *ExportUseCase) IsExportExists(id int) (bool, error) {
+	exists, err := u.exportRepo.IsExportExists(id)
+	if err != nil {
+		return false, err
+	}
+	return exists, nil
+}
+
+func (u *ExportUseCase) BindJSONExportRequest(ctx *gin.Context) (*export.ExportRequest, error) {
+	var req export.ExportRequest
+	if err := ctx.BindJSON(&req); err != nil {
+		return &req, err
+	}
+	return &req, nil
+}
+
+// ParseExportPeriod accepts "2006-01-02 15:04:05" in local time or RFC3339
+// and checks that the period is not empty.
+func (u *ExportUseCase) ParseExportPeriod(req *export.ExportRequest) (time.Time, time.Time, bool) {
+	from, ok := parseDatetime(req.From)
+	if !ok {
+		return time.Time{}, time.Time{}, false
+	}
+	to, ok := parseDatetime(req.To)
+	if !ok || !to.After(from) {
+		return time.Time{}, time.Time{}, false
+	}
+	return from, to, true
+}
+
+func (u *ExportUseCase) IsExportPeriodTooLong(from, to time.Time) bool {
+	return to.Sub(from) > time.Duration(u.cfg.ExportMaxDurationMinutes)*time.Minute
+}
+
+func (u *ExportUseCase) AtoiRequestedId(ctx *gin.Context) (int, error) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		return -1, err
+	}
+	return id, nil
+}
+
+func (u *ExportUseCase) processExport(id int) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Print(msg.ErrorExportWorkerPanic(r))
+		}
+	}()
+
+	exp, err := u.exportRepo.GetExport(id)
+	if err != nil {
+		logger.Print(msg.ErrorCannotGetExport(err))
+		return
+	}
+
+	exp.State = archive.StateRunning
+	exp.Progress = 0
+	exp.Error = ""
+	if !u.saveExport(exp) {
+		return
+	}
+	logger.Print(msg.InfoExportStarted(id))
+
+	from, _ := time.ParseInLocation(export.DatetimeLayout, exp.From, time.Local)
+	to, _ := time.ParseInLocation(export.DatetimeLayout, exp.To, time.Local)
+	total := to.Sub(from)
+	minutes := int(math.Ceil(total.Minutes()))
+
+	outputPath := filepath.Join(u.cfg.ExportOutputDir, fmt.Sprintf("%d.mp4", id))
+
+	defer func() {
+		u.progressMu.Lock()
+		delete(u.progress, id)
+		u.progressMu.Unlock()
+	}()
+
+	records, err := u.archiveUseCase.GetVideoRecords(exp.PathStream, exp.From, minutes)
+	if err == nil {
+		err = u.makeClip(context.Background(), exp, from, to, records, outputPath)
+	}
+
+	if err != nil {
+		exp.State = archive.StateFailed
+		exp.Error = err.Error()
+		logger.Print(msg.ErrorExportFailed(id, err))
+		u.saveExport(exp)
+		return
+	}
+
+	exp.State = archive.StateDone
+	exp.Progress = 100
+	exp.FilePath = outputPath
+	if media, err := u.archiveUseCase.ProbeMedia(context.Background(), outputPath); err != nil {
+		logger.Print(msg.WarningCannotProbeExport(id, err))
+	} else {
+		exp.Media = (*export.Media)(media)
+	}
+	if !u.saveExport(exp) {
+		return
+	}
+	logger.Print(msg.InfoExportDone(id, outputPath))
+
+	go u.deliveryUseCase.Deliver(context.Background(), &delivery.File{
+		Source:   delivery.SourceExport,
+		SourceId: id,
+		Path:     outputPath,
+	})
+}
+
+// makeClip joins the records into the output and sets the real start and
+// end of the clip. In the accurate mode the joined records are trimmed to
+// the requested period, otherwise the clip starts with the first record.
+// The times are placed on the joined video by the probed durations of the
+// records, a gap between the records in the period fails the export.
+// The overlay is burned in by the last step.
+func (u *ExportUseCase) makeClip(ctx context.Context, exp *export.Export, from, to time.Time,
+	records []*archive.Record, outputPath string) error {
+	if len(records) == 0 {
+		return errors.New("no video records in requested period")
+	}
+
+	segments, err := u.recordTimeline(ctx, records)
+	if err != nil {
+		return err
+	}
+	if err := checkGaps(segments, from, to); err != nil {
+		return err
+	}
+
+	paths := make([]string, len(records))
+	for i, rec := range records {
+		paths[i] = rec.Path
+	}
+	listPath := filepath.Join(u.cfg.ArchiveTmpDir, fmt.Sprintf("export_%d.txt", exp.Id))
+	total := to.Sub(from)
+	var start time.Duration
+
+	steps, step := 1, 0
+	if exp.Accurate {
+		steps++
+	}
+	if exp.Overlay != nil {
+		steps++
+	}
+	nextProgress := func() func(time.Duration) {
+		min, max := step*100/steps, (step+1)*100/steps-1
+		step++
+		return u.progressFunc(exp.Id, total, min, max)
+	}
+
+	// Every step but the last one writes a temporary file
+	clipPath := outputPath
+	if exp.Overlay != nil {
+		clipPath = filepath.Join(u.cfg.ArchiveTmpDir, fmt.Sprintf("export_%d_clip.mp4", exp.Id))
+		defer os.Remove(clipPath)
+	}
+	joinedPath := clipPath
+	if exp.Accurate {
+		joinedPath = filepath.Join(u.cfg.ArchiveTmpDir, fmt.Sprintf("export_%d_joined.mp4", exp.Id))
+		defer os.Remove(joinedPath)
+	}
+
+	if err := u.archiveUseCase.ConcatVideo(ctx, paths, listPath, joinedPath, nextProgress()); err != nil {
+		return err
+	}
+
+	if exp.Accurate {
+		start = toOffset(segments, from)
+		if err := u.archiveUseCase.TrimVideo(ctx, joinedPath, clipPath, start, toOffset(segments, to),
+			nextProgress()); err != nil {
+			return err
+		}
+	}
+	actualFrom := toTime(segments, start)
+
+	if exp.Overlay != nil {
+		if err := u.archiveUseCase.BurnOverlay(ctx, clipPath, outputPath, u.renderOverlay(exp.Overlay),
+			actualFrom, nextProgress()); err != nil {
+			return err
+		}
+	}
+
+	duration, err := u.archiveUseCase.ProbeDuration(ctx, outputPath)
+	if err != nil {
+		return err
+	}
+	exp.ActualFrom = actualFrom.Format(export.ActualDatetimeLayout)
+	exp.ActualTo = toTime(segments, start+duration).Format(export.ActualDatetimeLayout)
+
+	return nil
+}
+
+// PrepareOverlay checks the overlay settings and sets the defaults.
+func (u *ExportUseCase) PrepareOverlay(overlay *export.Overlay) error {
+	if overlay.Text == "" {
+		overlay.Text = defaultOverlayText
+	}
+	if len(overlay.Text) > maxOverlayTextLength {
+		return errors.New("text is longer than " + strconv.Itoa(maxOverlayTextLength) + " bytes")
+	}
+
+	switch overlay.Position {
+	case "":
+		overlay.Position = archive.PositionBottomLeft
+	case archive.PositionTopLeft, archive.PositionTopRight,
+		archive.PositionBottomLeft, archive.PositionBottomRight:
+	default:
+		return errors.New("position is not one of top-left, top-right, bottom-left, bottom-right")
+	}
+
+	if overlay.FontSize == 0 {
+		overlay.FontSize = defaultOverlayFontSize
+	}
+	if overlay.FontSize < minOverlayFontSize || overlay.FontSize > maxOverlayFontSize {
+		return errors.New("font size is not in range " + strconv.Itoa(minOverlayFontSize) +
+			"-" + strconv.Itoa(maxOverlayFontSize))
+	}
+
+	if overlay.Logo != "" {
+		// Only files of the logo directory are allowed
+		if filepath.Base(overlay.Logo) != overlay.Logo || strings.ToLower(filepath.Ext(overlay.Logo)) != ".png" {
+			return errors.New("logo is not a PNG file name")
+		}
+		info, err := os.Stat(filepath.Join(u.cfg.ExportLogoDir, overlay.Logo))
+		if err != nil || !info.Mode().IsRegular() {
+			return errors.New("logo is not found")
+		}
+	}
+
+	return nil
+}
+
+// renderOverlay replaces the {camera} and {user} placeholders, {time} is
+// replaced by ffmpeg for every frame.
+func (u *ExportUseCase) renderOverlay(overlay *export.Overlay) *archive.Overlay {
+	text := strings.NewReplacer("{camera}", overlay.Camera, "{user}", overlay.User).Replace(overlay.Text)
+
+	var logoPath string
+	if overlay.Logo != "" {
+		logoPath = filepath.Join(u.cfg.ExportLogoDir, overlay.Logo)
+	}
+
+	return &archive.Overlay{
+		Text:     text,
+		Position: overlay.Position,
+		FontSize: overlay.FontSize,
+		LogoPath: logoPath,
+	}
+}
+
+// progressFunc maps the done duration of an ffmpeg step to the [min, max]
+// percent of the job. The records around the period make the output a bit
+// longer, so 100 is left for the finished job.
+func (u *ExportUseCase) progressFunc(id int, total time.Duration, min, max int) func(time.Duration) {
+	return func(done time.Duration) {
+		percent := min + int(done*time.Duration(max-min)/total)
+		if percent > max {
+			percent = max
+		}
+		u.progressMu.Lock()
+		u.progress[id] = percent
+		u.progressMu.Unlock()
+	}
+}
+
+func (u *ExportUseCase) saveExport(exp *export.Export) bool {
+	if err := u.exportRepo.UpdateExport(exp); err != nil {
+		logger.Print(msg.ErrorCannotUpdateExport(err))
+		return false
+	}
+	return true
+}
+
+func parseDatetime(value string) (time.Time, bool) {
+	if t, err := time.ParseInLocation(export.DatetimeLayout, value, time.Local); err == nil {
+		return t, true
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.In(time.Local), true
+	}
+	return time.Time{}, false
+}
@@ -1,17 +1,101 @@
 package hasher
 
 import (
+	"crypto/rand"
 	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
 	"fmt"
+	"strings"
 
+	"golang.org/x/crypto/argon2"
 	msg "vhosting/internal/messages"
 	"vhosting/pkg/logger"
 )
 
-func GeneratePasswordHash(password, salt string) string {
+// Parameters of the argon2id hashes, the hashes made with other
+// parameters are reported as outdated and upgraded on sign-in.
+const (
+	argon2Time    = 1
+	argon2Memory  = 64 * 1024
+	argon2Threads = 4
+	argon2KeyLen  = 32
+	argon2SaltLen = 16
+)
+
+const argon2Prefix = "$argon2id$"
+
+// GeneratePasswordHash returns the argon2id hash of the password with a
+// random salt in the self-describing format
+// "$argon2id$v=19$m=65536,t=1,p=4$<salt>$<hash>".
+func GeneratePasswordHash(password string) string {
 	if password == "" {
 		return ""
 	}
+	salt := make([]byte, argon2SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		logger.Print(msg.ErrorCannotGeneratePasswordSalt(err))
+		return ""
+	}
+	key := argon2.IDKey([]byte(password), salt, argon2Time, argon2Memory, argon2Threads, argon2KeyLen)
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s", argon2Prefix, argon2.Version,
+		argon2Memory, argon2Time, argon2Threads,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key))
+}
+
+// VerifyPasswordHash checks the password against the stored hash. The
+// hashes without a format prefix are the legacy SHA-256 ones made with
+// the salt. The outdated flag tells that the hash must be regenerated.
+func VerifyPasswordHash(password, salt, passwordHash string) (matched, outdated bool) {
+	if password == "" || passwordHash == "" {
+		return false, false
+	}
+	if !strings.HasPrefix(passwordHash, "$") {
+		legacyHash := legacyPasswordHash(password, salt)
+		if legacyHash == "" {
+			return false, false
+		}
+		return subtle.ConstantTimeCompare([]byte(legacyHash), []byte(passwordHash)) == 1, true
+	}
+	if !strings.HasPrefix(passwordHash, argon2Prefix) {
+		return false, false
+	}
+
+	var version int
+	var memory, time uint32
+	var threads uint8
+	parts := strings.Split(passwordHash, "$")
+	if len(parts) != 6 {
+		return false, false
+	}
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return false, false
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &time, &threads); err != nil ||
+		time == 0 || threads == 0 {
+		return false, false
+	}
+	hashSalt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return false, false
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return false, false
+	}
+
+	newKey := argon2.IDKey([]byte(password), hashSalt, time, memory, threads, uint32(len(key)))
+	if subtle.ConstantTimeCompare(key, newKey) != 1 {
+		return false, false
+	}
+	outdated = memory != argon2Memory || time != argon2Time || threads != argon2Threads ||
+		len(hashSalt) != argon2SaltLen || len(key) != argon2KeyLen
+	return true, outdated
+}
+
+// legacyPasswordHash is the SHA-256 hash used before argon2id, kept for
+// verifying the passwords not yet upgraded.
+func legacyPasswordHash(password, salt string) string {
 	hash := sha256.New()
 	if _, err := hash.Write([]byte(password)); err != nil {
 		logger.Print(msg.ErrorCannotWriteBytesIntoInternalVariable(err))
@@ -1,49 +1,68 @@
 package hasher
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"time"
 
 	"github.com/dgrijalva/jwt-go"
-	"vhosting/pkg/auth"
 )
 
+// tokenClaims identify the server-side session, nothing about the user
+// besides its id is put into the token.
 type tokenClaims struct {
 	jwt.StandardClaims
-	Username string `json:"username"`
-	Password string `json:"password"`
+	SessionId int `json:"sid"`
+	UserId    int `json:"uid"`
 }
 
-func GenerateToken(namepass *auth.Namepass, signingKey string, tokenTTLHours int) (string, error) {
-	tokenTTL := time.Duration(tokenTTLHours) * time.Hour
+func GenerateToken(sessionId, userId int, signingKey string, tokenTTLMinutes int) (string, error) {
+	tokenTTL := time.Duration(tokenTTLMinutes) * time.Minute
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, &tokenClaims{
 		jwt.StandardClaims{
 			ExpiresAt: time.Now().Add(tokenTTL).Unix(),
 			IssuedAt:  time.Now().Unix(),
 		},
-		namepass.Username,
-		namepass.PasswordHash,
+		sessionId,
+		userId,
 	})
 	return token.SignedString([]byte(signingKey))
 }
 
-func ParseToken(tokenContent, signingKey string) (*auth.Namepass, error) {
-	var namepass auth.Namepass
-	ok := false
-	token, err := jwt.ParseWithClaims(tokenContent, &tokenClaims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok = token.Method.(*jwt.SigningMethodHMAC); !ok {
+// ParseToken checks the signature and returns the session and user ids.
+// Without checking the expiration the token still identifies its session
+// for signing out and revoking.
+func ParseToken(tokenContent, signingKey string, checkExpiration bool) (int, int, error) {
+	parser := jwt.Parser{SkipClaimsValidation: !checkExpiration}
+	token, err := parser.ParseWithClaims(tokenContent, &tokenClaims{}, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, errors.New("Invalid signing method.")
 		}
 		return []byte(signingKey), nil
 	})
 	if err != nil {
-		return &namepass, err
+		return -1, -1, err
 	}
 	claims, ok := token.Claims.(*tokenClaims)
 	if !ok {
-		return &namepass, errors.New("Token claims has wrong type.")
+		return -1, -1, errors.New("Token claims has wrong type.")
 	}
-	namepass.Username = claims.Username
-	namepass.PasswordHash = claims.Password
-	return &namepass, nil
+	return claims.SessionId, claims.UserId, nil
+}
+
+// GenerateRandomToken returns a random opaque token for the refresh
+// tokens and API keys, only its hash is stored on the server.
+func GenerateRandomToken() (string, error) {
+	token := make([]byte, 32)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(token), nil
+}
+
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
@@ -35,7 +35,7 @@ type LogUseCase interface {
 	LogCommon
 
 	Report(ctx *gin.Context, log *Log, messageLog *Log)
-	ReportWithToken(ctx *gin.Context, log *Log, messageLog *Log, token string)
+	ReportWithToken(ctx *gin.Context, log *Log, messageLog *Log, token, refreshToken string)
 }
 
 type LogRepository interface {
@@ -164,6 +164,10 @@ func ParseMessage(log *Log) string {
 		return "Got all infos" + tab
 	} else if msgType == "*video.Video" {
 		return "Got video" + tab
+	} else if msgType == "*video.Metadata" {
+		return "Video probed" + tab
+	} else if msgType == "*video.Upload" {
+		return "Got upload" + tab
 	} else if msgType == "map[int]*video.Video" {
 		return "Got all videos" + tab
 	} else if msgType == "*group.GroupIds" {
@@ -172,8 +176,32 @@ func ParseMessage(log *Log) string {
 		return "Got stream" + tab
 	} else if msgType == "map[int]*stream.Stream" {
 		return "Got all streams" + tab
+	} else if msgType == "*stream.Camera" {
+		return "Got camera" + tab
+	} else if msgType == "*stream.GeoJSONFeatureCollection" {
+		return "Got cameras GeoJSON" + tab
+	} else if msgType == "*stream.SourceView" {
+		return "Got stream source" + tab
+	} else if msgType == "*stream.Usage" {
+		return "Got stream usage" + tab
+	} else if msgType == "*stream.Timeline" {
+		return "Got stream timeline" + tab
+	} else if msgType == "*stream.EventsTicket" {
+		return "Got events ticket" + tab
 	} else if msgType == "*download.Download" {
 		return "Got download link" + tab
+	} else if msgType == "*export.Export" {
+		return "Got export" + tab
+	} else if msgType == "*retention.Report" {
+		return "Got retention report" + tab
+	} else if msgType == "[]*audit.Access" {
+		return "Got accesses" + tab
+	} else if msgType == "[]*session.Session" {
+		return "Got sessions" + tab
+	} else if msgType == "*apikey.ApiKey" {
+		return "Got API key" + tab
+	} else if msgType == "[]*apikey.ApiKey" {
+		return "Got API keys" + tab
 	}
 
 	return "Got data of unknown type. Type: " + msgType + tab
@@ -28,12 +28,12 @@ func (u *LogUseCase) Report(ctx *gin.Context, log *logger.Log, messageLog *logge
 	logger.Print(log)
 }
 
-func (u *LogUseCase) ReportWithToken(ctx *gin.Context, log *logger.Log, messageLog *logger.Log, token string) {
+func (u *LogUseCase) ReportWithToken(ctx *gin.Context, log *logger.Log, messageLog *logger.Log, token, refreshToken string) {
 	logger.Complete(log, messageLog)
-	responder.ResponseToken(ctx, log, token)
+	responder.ResponseToken(ctx, log, token, refreshToken)
 	if err := u.CreateLogRecord(log); err != nil {
 		logger.Complete(log, msg.ErrorCannotDoLogging(err))
-		responder.ResponseToken(ctx, log, token)
+		responder.ResponseToken(ctx, log, token, refreshToken)
 	}
 	logger.Print(log)
 }
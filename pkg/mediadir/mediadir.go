@@ -0,0 +1,60 @@
+// Package mediadir resolves the files of the media directory.
+package mediadir
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var ErrPathIsOutOfDir = errors.New("file path is out of media directory")
+
+// Resolve returns the absolute path of the file in the directory, the file
+// is relative to the directory or an absolute path inside it. Symlinks are
+// followed, so neither the path nor a symlink may lead out of the
+// directory. The file itself does not have to exist.
+func Resolve(dir, file string) (string, error) {
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	if root, err = evalExistingSymlinks(root); err != nil {
+		return "", err
+	}
+
+	path := file
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(root, path)
+	}
+	if path, err = evalExistingSymlinks(filepath.Clean(path)); err != nil {
+		return "", err
+	}
+
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%w: %s", ErrPathIsOutOfDir, file)
+	}
+	return path, nil
+}
+
+// evalExistingSymlinks follows the symlinks of the longest existing part of
+// the path and appends the rest to it.
+func evalExistingSymlinks(path string) (string, error) {
+	resolved, err := filepath.EvalSymlinks(path)
+	if err == nil {
+		return resolved, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+	parent := filepath.Dir(path)
+	if parent == path {
+		return path, nil
+	}
+	if parent, err = evalExistingSymlinks(parent); err != nil {
+		return "", err
+	}
+	return filepath.Join(parent, filepath.Base(path)), nil
+}
@@ -12,8 +12,9 @@ type MessageOutput struct {
 }
 
 type MessageTokenOutput struct {
-	Message string `json:"message"`
-	Token   string `json:"token"`
+	Message      string `json:"message"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refreshToken"`
 }
 
 type ErrorOutput struct {
@@ -25,8 +26,9 @@ type ErrorData struct {
 	Statement string `json:"statement"`
 }
 
-func ResponseToken(ctx *gin.Context, log *logger.Log, token string) {
-	ctx.AbortWithStatusJSON(log.StatusCode, MessageTokenOutput{Message: log.Message.(string), Token: token})
+func ResponseToken(ctx *gin.Context, log *logger.Log, token, refreshToken string) {
+	ctx.AbortWithStatusJSON(log.StatusCode, MessageTokenOutput{Message: log.Message.(string), Token: token,
+		RefreshToken: refreshToken})
 }
 
 func Response(ctx *gin.Context, log *logger.Log) {
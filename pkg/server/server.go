@@ -11,6 +11,14 @@ import (
 	"syscall"
 	"time"
 
+	"vhosting/internal/apikey"
+	apikeyhandler "vhosting/internal/apikey/handler"
+	apikeyrepo "vhosting/internal/apikey/repository"
+	apikeyusecase "vhosting/internal/apikey/usecase"
+	"vhosting/internal/audit"
+	audithandler "vhosting/internal/audit/handler"
+	auditrepo "vhosting/internal/audit/repository"
+	auditusecase "vhosting/internal/audit/usecase"
 	"vhosting/internal/group"
 	grouphandler "vhosting/internal/group/handler"
 	grouprepo "vhosting/internal/group/repository"
@@ -24,6 +32,10 @@ import (
 	permhandler "vhosting/internal/permission/handler"
 	permrepo "vhosting/internal/permission/repository"
 	permusecase "vhosting/internal/permission/usecase"
+	"vhosting/internal/retention"
+	retentionhandler "vhosting/internal/retention/handler"
+	retentionrepo "vhosting/internal/retention/repository"
+	retentionusecase "vhosting/internal/retention/usecase"
 	sess "vhosting/internal/session"
 	sessrepo "vhosting/internal/session/repository"
 	sessusecase "vhosting/internal/session/usecase"
@@ -31,6 +43,8 @@ import (
 	videohandler "vhosting/internal/video/handler"
 	videorepo "vhosting/internal/video/repository"
 	videousecase "vhosting/internal/video/usecase"
+	archiverepo "vhosting/pkg/archive/repository"
+	archiveusecase "vhosting/pkg/archive/usecase"
 	"vhosting/pkg/auth"
 	authhandler "vhosting/pkg/auth/handler"
 	authrepo "vhosting/pkg/auth/repository"
@@ -38,9 +52,17 @@ import (
 	"vhosting/pkg/config"
 	"vhosting/pkg/config_stream"
 	sconfig "vhosting/pkg/config_stream"
+	"vhosting/pkg/deadline"
+	deliveryrepo "vhosting/pkg/delivery/repository"
+	deliveryusecase "vhosting/pkg/delivery/usecase"
 	"vhosting/pkg/download"
 	downloadhandler "vhosting/pkg/download/handler"
+	downloadrepo "vhosting/pkg/download/repository"
 	downloadusecase "vhosting/pkg/download/usecase"
+	"vhosting/pkg/export"
+	exporthandler "vhosting/pkg/export/handler"
+	exportrepo "vhosting/pkg/export/repository"
+	exportusecase "vhosting/pkg/export/usecase"
 	"vhosting/pkg/logger"
 	logrepo "vhosting/pkg/logger/repository"
 	logusecase "vhosting/pkg/logger/usecase"
@@ -57,19 +79,23 @@ import (
 )
 
 type App struct {
-	httpServer      *http.Server
-	cfg             *config.Config
-	scfg            *sconfig.Config
-	userUseCase     user.UserUseCase
-	authUseCase     auth.AuthUseCase
-	sessUseCase     sess.SessUseCase
-	logUseCase      logger.LogUseCase
-	groupUseCase    group.GroupUseCase
-	permUseCase     perm.PermUseCase
-	infoUseCase     info.InfoUseCase
-	videoUseCase    video.VideoUseCase
-	StreamUC        stream.StreamUseCase
-	downloadUseCase download.DownloadUseCase
+	httpServer       *http.Server
+	cfg              *config.Config
+	scfg             *sconfig.Config
+	userUseCase      user.UserUseCase
+	authUseCase      auth.AuthUseCase
+	sessUseCase      sess.SessUseCase
+	logUseCase       logger.LogUseCase
+	groupUseCase     group.GroupUseCase
+	permUseCase      perm.PermUseCase
+	infoUseCase      info.InfoUseCase
+	videoUseCase     video.VideoUseCase
+	StreamUC         stream.StreamUseCase
+	downloadUseCase  download.DownloadUseCase
+	exportUseCase    export.ExportUseCase
+	retentionUseCase retention.RetentionUseCase
+	auditUseCase     audit.AuditUseCase
+	apiKeyUseCase    apikey.ApiKeyUseCase
 }
 
 func NewApp(cfg *config.Config) *App {
@@ -82,22 +108,38 @@ func NewApp(cfg *config.Config) *App {
 	infoRepo := inforepo.NewInfoRepository(cfg)
 	videoRepo := videorepo.NewVideoRepository(cfg)
 	streamRepo := streamrepo.NewStreamRepository(cfg)
+	archiveRepo := archiverepo.NewArchiveRepository(cfg)
+	exportRepo := exportrepo.NewExportRepository(cfg)
+	deliveryRepo := deliveryrepo.NewDeliveryRepository(cfg)
+	retentionRepo := retentionrepo.NewRetentionRepository(cfg)
+	downloadRepo := downloadrepo.NewDownloadRepository(cfg)
+	auditRepo := auditrepo.NewAuditRepository(cfg)
+	apiKeyRepo := apikeyrepo.NewApiKeyRepository(cfg)
+
+	logUseCase := logusecase.NewLogUseCase(logRepo)
+
+	deliveryUseCase := deliveryusecase.NewDeliveryUseCase(cfg, deliveryRepo)
+	archiveUseCase := archiveusecase.NewArchiveUseCase(cfg, archiveRepo, deliveryUseCase)
 
 	scfg := &config_stream.Config{}
 
 	return &App{
-		cfg:             cfg,
-		scfg:            scfg,
-		userUseCase:     userusecase.NewUserUseCase(cfg, userRepo),
-		authUseCase:     authusecase.NewAuthUseCase(cfg, authRepo),
-		sessUseCase:     sessusecase.NewSessUseCase(sessRepo, authRepo),
-		logUseCase:      logusecase.NewLogUseCase(logRepo),
-		groupUseCase:    groupusecase.NewGroupUseCase(groupRepo),
-		permUseCase:     permusecase.NewPermUseCase(permRepo),
-		infoUseCase:     infousecase.NewInfoUseCase(infoRepo),
-		videoUseCase:    videousecase.NewVideoUseCase(videoRepo),
-		StreamUC:        streamusecase.NewStreamUseCase(cfg, scfg, streamRepo),
-		downloadUseCase: downloadusecase.NewDownloadUseCase(cfg),
+		cfg:              cfg,
+		scfg:             scfg,
+		userUseCase:      userusecase.NewUserUseCase(cfg, userRepo),
+		authUseCase:      authusecase.NewAuthUseCase(cfg, authRepo),
+		sessUseCase:      sessusecase.NewSessUseCase(cfg, sessRepo, authRepo, apiKeyRepo),
+		logUseCase:       logUseCase,
+		groupUseCase:     groupusecase.NewGroupUseCase(groupRepo),
+		permUseCase:      permusecase.NewPermUseCase(permRepo),
+		infoUseCase:      infousecase.NewInfoUseCase(infoRepo),
+		videoUseCase:     videousecase.NewVideoUseCase(cfg, videoRepo, archiveUseCase),
+		StreamUC:         streamusecase.NewStreamUseCase(cfg, scfg, streamRepo),
+		downloadUseCase:  downloadusecase.NewDownloadUseCase(cfg, downloadRepo),
+		exportUseCase:    exportusecase.NewExportUseCase(cfg, exportRepo, archiveUseCase, deliveryUseCase),
+		retentionUseCase: retentionusecase.NewRetentionUseCase(cfg, retentionRepo, logUseCase),
+		auditUseCase:     auditusecase.NewAuditUseCase(cfg, auditRepo),
+		apiKeyUseCase:    apikeyusecase.NewApiKeyUseCase(cfg, apiKeyRepo),
 	}
 }
 
@@ -119,7 +161,7 @@ func (a *App) Run() error {
 	if _, err := os.Stat("./web"); !os.IsNotExist(err) {
 		router.LoadHTMLGlob("./web/templates/*")
 		streamhandler.RegisterTemplateHTTPEndpoints(router, a.cfg, a.scfg, a.StreamUC,
-			a.userUseCase, a.logUseCase, a.authUseCase, a.sessUseCase)
+			a.userUseCase, a.logUseCase, a.authUseCase, a.sessUseCase, a.auditUseCase)
 	}
 
 	router.StaticFS("/static", http.Dir("./web/static"))
@@ -134,12 +176,20 @@ func (a *App) Run() error {
 	permhandler.RegisterHTTPEndpoints(router, a.cfg, a.permUseCase, a.logUseCase,
 		a.authUseCase, a.sessUseCase, a.userUseCase, a.groupUseCase)
 	infohandler.RegisterHTTPEndpoints(router, a.cfg, a.scfg, a.infoUseCase, a.logUseCase,
-		a.authUseCase, a.sessUseCase, a.userUseCase)
+		a.authUseCase, a.sessUseCase, a.userUseCase, a.retentionUseCase)
 	videohandler.RegisterHTTPEndpoints(router, a.cfg, a.videoUseCase, a.logUseCase,
-		a.authUseCase, a.sessUseCase, a.userUseCase)
+		a.authUseCase, a.sessUseCase, a.userUseCase, a.infoUseCase, a.retentionUseCase)
 	streamhandler.RegisterStreamingHTTPEndpoints(router, a.cfg, a.scfg, a.StreamUC,
-		a.userUseCase, a.logUseCase, a.authUseCase, a.sessUseCase)
+		a.userUseCase, a.logUseCase, a.authUseCase, a.sessUseCase, a.auditUseCase)
 	downloadhandler.RegisterHTTPEndpoints(router, a.cfg, a.downloadUseCase, a.logUseCase,
+		a.authUseCase, a.sessUseCase, a.userUseCase, a.videoUseCase, a.exportUseCase, a.auditUseCase)
+	exporthandler.RegisterHTTPEndpoints(router, a.cfg, a.exportUseCase, a.logUseCase,
+		a.authUseCase, a.sessUseCase, a.userUseCase, a.StreamUC, a.auditUseCase)
+	retentionhandler.RegisterHTTPEndpoints(router, a.cfg, a.retentionUseCase, a.logUseCase,
+		a.authUseCase, a.sessUseCase, a.userUseCase, a.infoUseCase)
+	audithandler.RegisterHTTPEndpoints(router, a.cfg, a.auditUseCase, a.logUseCase,
+		a.authUseCase, a.sessUseCase, a.userUseCase, a.videoUseCase)
+	apikeyhandler.RegisterHTTPEndpoints(router, a.cfg, a.apiKeyUseCase, a.logUseCase,
 		a.authUseCase, a.sessUseCase, a.userUseCase)
 
 	// Set HTTP server params.
@@ -149,6 +199,7 @@ func (a *App) Run() error {
 		ReadTimeout:    time.Duration(a.cfg.ServerReadTimeoutSeconds) * time.Second,
 		WriteTimeout:   time.Duration(a.cfg.ServerWriteTimeoutSeconds) * time.Second,
 		MaxHeaderBytes: a.cfg.ServerMaxHeaderBytes,
+		ConnContext:    deadline.SaveConn,
 	}
 
 	// Start HTTP server.
@@ -170,6 +221,18 @@ func (a *App) Run() error {
 	// Start videostreams worker.
 	go a.StreamUC.ServeStreams()
 
+	// Start export workers.
+	a.exportUseCase.ServeExports()
+
+	// Start video preview workers.
+	a.videoUseCase.ServePreviews()
+
+	// Start deleting expired uploads.
+	a.videoUseCase.ServeUploads()
+
+	// Start retention enforcement.
+	go a.retentionUseCase.ServeRetention(context.Background())
+
 	// Listen for interrupt signal from keyboard.
 	sigs := make(chan os.Signal, 1)
 	done := make(chan bool, 1)
@@ -9,4 +9,44 @@ const (
 	StatusPublic = "\"StatusPublic\""
 	StatusRecord = "\"StatusRecord\""
 	PathStream   = "\"pathStream\""
+
+	CamTableName   = "cameras"
+	CamStreamId    = "stream_id"
+	CamDisplayName = "display_name"
+	CamSite        = "site"
+	CamBuilding    = "building"
+	CamFloor       = "floor"
+	CamLatitude    = "latitude"
+	CamLongitude   = "longitude"
+	CamTags        = "tags"
+	CamGroups      = "camera_groups"
+
+	SrcTableName = "stream_sources"
+	SrcStreamId  = "stream_id"
+	SrcURL       = "url"
+	SrcTransport = "transport"
+	SrcUsername  = "username_enc"
+	SrcPassword  = "password_enc"
+
+	RecTableName  = "\"VideoRecord\""
+	RecPathStream = "\"pathStream\""
+	RecTime       = "\"recordTime\""
+)
+
+const (
+	TimelineDatetimeLayout = "2006-01-02 15:04:05"
+	TimelineDateLayout     = "2006-01-02"
+)
+
+const (
+	TransportTCP = "tcp"
+	TransportUDP = "udp"
+)
+
+const (
+	EventSnapshot = "snapshot"
+	EventOnline   = "online"
+	EventOffline  = "offline"
+	EventCodec    = "codec"
+	EventViewers  = "viewers"
 )
@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	msg "vhosting/internal/messages"
+	"vhosting/pkg/logger"
+)
+
+func (h *StreamHandler) SetCamera(ctx *gin.Context) {
+	actPermission := "set_camera"
+
+	log := logger.Init(ctx)
+
+	hasPerms, _ := h.isPermsGranted_getUserId(ctx, log, actPermission)
+	if !hasPerms {
+		return
+	}
+
+	// Read requested ID, check stream existence
+	reqId, err := h.useCase.AtoiRequestedId(ctx)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotConvertRequestedIDToTypeInt(err))
+		return
+	}
+
+	exists, err := h.useCase.IsStreamExists(reqId)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotCheckStreamExistence(err))
+		return
+	}
+	if !exists {
+		h.logUseCase.Report(ctx, log, msg.ErrorStreamWithRequestedIDIsNotExist())
+		return
+	}
+
+	// Read input, link it to the requested stream, upsert camera
+	inputCamera, err := h.useCase.BindJSONCamera(ctx)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotBindInputData(err))
+		return
+	}
+
+	inputCamera.StreamId = reqId
+
+	if err := h.useCase.SetCamera(inputCamera); err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotSetCamera(err))
+		return
+	}
+
+	h.logUseCase.Report(ctx, log, msg.InfoCameraSet())
+}
+
+func (h *StreamHandler) GetCamera(ctx *gin.Context) {
+	actPermission := "get_stream"
+
+	log := logger.Init(ctx)
+
+	hasPerms, _ := h.isPermsGranted_getUserId(ctx, log, actPermission)
+	if !hasPerms {
+		return
+	}
+
+	// Read requested ID, check camera existence, get camera
+	reqId, err := h.useCase.AtoiRequestedId(ctx)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotConvertRequestedIDToTypeInt(err))
+		return
+	}
+
+	exists, err := h.useCase.IsCameraExists(reqId)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotCheckCameraExistence(err))
+		return
+	}
+	if !exists {
+		h.logUseCase.Report(ctx, log, msg.ErrorCameraWithRequestedStreamIDIsNotExist())
+		return
+	}
+
+	gottenCamera, err := h.useCase.GetCamera(reqId)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotGetCamera(err))
+		return
+	}
+
+	h.logUseCase.Report(ctx, log, msg.InfoGotCamera(gottenCamera))
+}
+
+func (h *StreamHandler) DeleteCamera(ctx *gin.Context) {
+	actPermission := "delete_camera"
+
+	log := logger.Init(ctx)
+
+	hasPerms, _ := h.isPermsGranted_getUserId(ctx, log, actPermission)
+	if !hasPerms {
+		return
+	}
+
+	// Read requested ID, check camera existence, delete camera
+	reqId, err := h.useCase.AtoiRequestedId(ctx)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotConvertRequestedIDToTypeInt(err))
+		return
+	}
+
+	exists, err := h.useCase.IsCameraExists(reqId)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotCheckCameraExistence(err))
+		return
+	}
+	if !exists {
+		h.logUseCase.Report(ctx, log, msg.ErrorCameraWithRequestedStreamIDIsNotExist())
+		return
+	}
+
+	if err := h.useCase.DeleteCamera(reqId); err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotDeleteCamera(err))
+		return
+	}
+
+	h.logUseCase.Report(ctx, log, msg.InfoCameraDeleted())
+}
+
+func (h *StreamHandler) GetCamerasGeoJSON(ctx *gin.Context) {
+	actPermission := "get_all_streams"
+
+	log := logger.Init(ctx)
+
+	hasPerms, _ := h.isPermsGranted_getUserId(ctx, log, actPermission)
+	if !hasPerms {
+		return
+	}
+
+	filter := h.useCase.ParseCameraFilter(ctx)
+
+	collection, err := h.useCase.GetCamerasGeoJSON(filter)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotGetCamerasGeoJSON(err))
+		return
+	}
+
+	h.logUseCase.Report(ctx, log, msg.InfoGotCamerasGeoJSON(collection))
+}
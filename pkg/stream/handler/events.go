@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	msg "vhosting/internal/messages"
+	"vhosting/pkg/logger"
+	"vhosting/pkg/stream"
+)
+
+const (
+	eventsRetryMilliseconds = 1000
+	eventsHeartbeatSeconds  = 10
+)
+
+// CreateEventsTicket issues the ticket for GET /events/streams. EventSource
+// cannot set request headers and the token must not be put in the URL,
+// so the ticket is asked for with the token and put in the URL instead.
+func (h *StreamHandler) CreateEventsTicket(ctx *gin.Context) {
+	actPermission := "get_all_streams"
+
+	log := logger.Init(ctx)
+
+	hasPerms, _ := h.isPermsGranted_getUserId(ctx, log, actPermission)
+	if !hasPerms {
+		return
+	}
+
+	ticket, err := h.useCase.CreateEventsTicket(log.SessionOwner)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotCreateEventsTicket(err))
+		return
+	}
+
+	h.logUseCase.Report(ctx, log, msg.InfoEventsTicketCreated(ticket))
+}
+
+func (h *StreamHandler) ServeStreamEvents(ctx *gin.Context) {
+	log := logger.Init(ctx)
+
+	// The permission was checked when the ticket was issued
+	username, ok := h.useCase.UseEventsTicket(ctx.Query("ticket"))
+	if !ok {
+		h.logUseCase.Report(ctx, log, msg.ErrorEventsTicketIsInvalid())
+		return
+	}
+	log.SessionOwner = username
+
+	id, events := h.useCase.SubscribeEvents()
+	defer h.useCase.UnsubscribeEvents(id)
+
+	logger.Complete(log, msg.InfoStreamEventsSubscribed())
+	logger.Print(log)
+
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	ctx.Header("X-Accel-Buffering", "no")
+
+	// The server write timeout closes long responses, so the connection is
+	// ended just before it and the client reconnects with a new ticket
+	var lifetime <-chan time.Time
+	if h.cfg.ServerWriteTimeoutSeconds > 1 {
+		timer := time.NewTimer(time.Duration(h.cfg.ServerWriteTimeoutSeconds-1) * time.Second)
+		defer timer.Stop()
+		lifetime = timer.C
+	}
+
+	heartbeat := time.NewTicker(eventsHeartbeatSeconds * time.Second)
+	defer heartbeat.Stop()
+
+	fmt.Fprintf(ctx.Writer, "retry: %d\n\n", eventsRetryMilliseconds)
+	ctx.SSEvent(stream.EventSnapshot, h.useCase.States())
+	ctx.Writer.Flush()
+
+	ctx.Stream(func(w io.Writer) bool {
+		select {
+		case event := <-events:
+			ctx.SSEvent(event.Type, event)
+			return true
+		case <-heartbeat.C:
+			_, err := io.WriteString(w, ": ping\n\n")
+			return err == nil
+		case <-lifetime:
+			return false
+		case <-ctx.Request.Context().Done():
+			return false
+		}
+	})
+
+	logger.Complete(log, msg.InfoStreamEventsUnsubscribed())
+	logger.Print(log)
+}
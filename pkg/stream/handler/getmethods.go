@@ -54,9 +54,10 @@ func (h *StreamHandler) GetAllStreams(ctx *gin.Context) {
 	}
 
 	urlparams := h.useCase.ParseURLParams(ctx)
+	filter := h.useCase.ParseCameraFilter(ctx)
 
-	// Get all users. If gotten is nothing - send such a message
-	gottenStreams, err := h.useCase.GetAllStreams(urlparams)
+	// Get all streams. If gotten is nothing - send such a message
+	gottenStreams, err := h.useCase.GetAllStreams(urlparams, filter)
 	if err != nil {
 		h.logUseCase.Report(ctx, log, msg.ErrorCannotGetAllStreams(err))
 		return
@@ -77,7 +78,7 @@ func (h *StreamHandler) isPermsGranted_getUserId(ctx *gin.Context, log *logger.L
 		return false, -1
 	}
 
-	session, err := h.sessUseCase.GetSessionAndDate(headerToken)
+	session, err := h.sessUseCase.GetSessionAndDate(ctx, headerToken)
 	if err != nil {
 		h.logUseCase.Report(ctx, log, msg.ErrorCannotGetSessionAndDate(err))
 		return false, -1
@@ -87,7 +88,7 @@ func (h *StreamHandler) isPermsGranted_getUserId(ctx *gin.Context, log *logger.L
 		return false, -1
 	}
 
-	if timedate.IsDateExpired(session.CreationDate, h.cfg.SessionTTLHours) {
+	if timedate.IsDateExpired(session.RefreshDate, h.cfg.SessionTTLHours) {
 		if err := h.sessUseCase.DeleteSession(headerToken); err != nil {
 			h.logUseCase.Report(ctx, log, msg.ErrorCannotDeleteSession(err))
 			return false, -1
@@ -96,13 +97,7 @@ func (h *StreamHandler) isPermsGranted_getUserId(ctx *gin.Context, log *logger.L
 		return false, -1
 	}
 
-	headerNamepass, err := h.authUseCase.ParseToken(headerToken)
-	if err != nil {
-		h.logUseCase.Report(ctx, log, msg.ErrorCannotParseToken(err))
-		return false, -1
-	}
-
-	gottenUserId, err := h.userUseCase.GetUserId(headerNamepass.Username)
+	gottenUserId, err := h.userUseCase.GetUserId(session.Username)
 	if err != nil {
 		h.logUseCase.Report(ctx, log, msg.ErrorCannotCheckUserExistence(err))
 		return false, -1
@@ -116,11 +111,26 @@ func (h *StreamHandler) isPermsGranted_getUserId(ctx *gin.Context, log *logger.L
 		return false, -1
 	}
 
-	log.SessionOwner = headerNamepass.Username
+	log.SessionOwner = session.Username
+
+	// The API keys are granted the permissions of their scopes which their
+	// users still hold
+	if session.ApiKeyId != 0 {
+		granted, err := h.sessUseCase.IsApiKeyGranted(session, permission)
+		if err != nil {
+			h.logUseCase.Report(ctx, log, msg.ErrorCannotCheckApiKeyScopes(err))
+			return false, -1
+		}
+		if !granted {
+			h.logUseCase.Report(ctx, log, msg.ErrorYouHaveNotEnoughPermissions())
+			return false, -1
+		}
+		return true, gottenUserId
+	}
 
 	isSUorStaff := false
 	hasPersonalPerm := false
-	if isSUorStaff, err = h.userUseCase.IsUserSuperuserOrStaff(headerNamepass.Username); err != nil {
+	if isSUorStaff, err = h.userUseCase.IsUserSuperuserOrStaff(session.Username); err != nil {
 		h.logUseCase.Report(ctx, log, msg.ErrorCannotCheckSuperuserStaffPermissions(err))
 		return false, -1
 	}
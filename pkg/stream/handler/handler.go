@@ -10,6 +10,7 @@ import (
 	"github.com/deepch/vdk/av"
 	webrtc "github.com/deepch/vdk/format/webrtcv3"
 	"github.com/gin-gonic/gin"
+	"vhosting/internal/audit"
 	msg "vhosting/internal/messages"
 	sess "vhosting/internal/session"
 	"vhosting/pkg/auth"
@@ -17,30 +18,33 @@ import (
 	sconfig "vhosting/pkg/config_stream"
 	"vhosting/pkg/logger"
 	"vhosting/pkg/stream"
+	"vhosting/pkg/timedate"
 	"vhosting/pkg/user"
 )
 
 type StreamHandler struct {
-	cfg         *config.Config
-	scfg        *sconfig.Config
-	useCase     stream.StreamUseCase
-	userUseCase user.UserUseCase
-	logUseCase  logger.LogUseCase
-	authUseCase auth.AuthUseCase
-	sessUseCase sess.SessUseCase
+	cfg          *config.Config
+	scfg         *sconfig.Config
+	useCase      stream.StreamUseCase
+	userUseCase  user.UserUseCase
+	logUseCase   logger.LogUseCase
+	authUseCase  auth.AuthUseCase
+	sessUseCase  sess.SessUseCase
+	auditUseCase audit.AuditUseCase
 }
 
 func NewStreamHandler(cfg *config.Config, scfg *sconfig.Config, useCase stream.StreamUseCase,
 	userUseCase user.UserUseCase, logUseCase logger.LogUseCase, authUseCase auth.AuthUseCase,
-	sessUseCase sess.SessUseCase) *StreamHandler {
+	sessUseCase sess.SessUseCase, auditUseCase audit.AuditUseCase) *StreamHandler {
 	return &StreamHandler{
-		cfg:         cfg,
-		scfg:        scfg,
-		useCase:     useCase,
-		userUseCase: userUseCase,
-		logUseCase:  logUseCase,
-		authUseCase: authUseCase,
-		sessUseCase: sessUseCase,
+		cfg:          cfg,
+		scfg:         scfg,
+		useCase:      useCase,
+		userUseCase:  userUseCase,
+		logUseCase:   logUseCase,
+		authUseCase:  authUseCase,
+		sessUseCase:  sessUseCase,
+		auditUseCase: auditUseCase,
 	}
 }
 
@@ -127,32 +131,38 @@ func (h *StreamHandler) ServeStreamVidOverWebRTC(ctx *gin.Context) {
 		audioOnly = true
 	}
 
+	slotId, owner, ok := h.acquireViewerSlot(ctx, suuid)
+	if !ok {
+		return
+	}
+
 	muxerWebRTC := webrtc.NewMuxer(webrtc.Options{ICEServers: h.useCase.GetICEServers(),
 		ICEUsername: h.useCase.GetICEUsername(), ICECredential: h.useCase.GetICECredential(),
 		PortMin: h.useCase.GetWebRTCPortMin(), PortMax: h.useCase.GetWebRTCPortMax()})
 	answer, err := muxerWebRTC.WriteHeader(codecs, ctx.PostForm("data"))
 	if err != nil {
+		h.useCase.ReleaseViewerSlot(slotId)
 		logger.Printc(ctx, msg.ErrorWriteHeaderError(err))
 		return
 	}
 
 	if _, err := ctx.Writer.Write([]byte(answer)); err != nil {
+		h.useCase.ReleaseViewerSlot(slotId)
 		logger.Printc(ctx, msg.ErrorCannotWriteBytes(err))
 		return
 	}
 
-	go h.useCase.WritePackets(suuid, muxerWebRTC, audioOnly)
+	acc := liveAccess(suuid, owner)
+	go func() {
+		defer h.useCase.ReleaseViewerSlot(slotId)
+		acc.BytesSent = h.useCase.WritePackets(suuid, muxerWebRTC, audioOnly)
+		h.auditUseCase.RecordAccess(acc)
+	}()
 }
 
 func (h *StreamHandler) ServeStreamWebRTC2(ctx *gin.Context) {
 	url := ctx.PostForm("url")
-	if _, ok := h.scfg.Streams[url]; !ok {
-		h.scfg.Streams[url] = sconfig.Stream{
-			URL:        url,
-			OnDemand:   true,
-			ClientList: make(map[string]sconfig.Viewer),
-		}
-	}
+	h.useCase.AddOnDemandStream(url)
 
 	h.useCase.RunIfNotRun(url)
 
@@ -162,6 +172,11 @@ func (h *StreamHandler) ServeStreamWebRTC2(ctx *gin.Context) {
 		return
 	}
 
+	slotId, owner, ok := h.acquireViewerSlot(ctx, url)
+	if !ok {
+		return
+	}
+
 	muxerWebRTC := webrtc.NewMuxer(
 		webrtc.Options{
 			ICEServers: h.useCase.GetICEServers(),
@@ -173,6 +188,7 @@ func (h *StreamHandler) ServeStreamWebRTC2(ctx *gin.Context) {
 	sdp64 := ctx.PostForm("sdp64")
 	answer, err := muxerWebRTC.WriteHeader(codecs, sdp64)
 	if err != nil {
+		h.useCase.ReleaseViewerSlot(slotId)
 		logger.Printc(ctx, msg.ErrorMuxerWriteHeaderError(err))
 		return
 	}
@@ -200,5 +216,22 @@ func (h *StreamHandler) ServeStreamWebRTC2(ctx *gin.Context) {
 
 	audioOnly := len(codecs) == 1 && codecs[0].Type().IsAudio()
 
-	go h.useCase.WritePackets(url, muxerWebRTC, audioOnly)
+	acc := liveAccess(url, owner)
+	go func() {
+		defer h.useCase.ReleaseViewerSlot(slotId)
+		acc.BytesSent = h.useCase.WritePackets(url, muxerWebRTC, audioOnly)
+		h.auditUseCase.RecordAccess(acc)
+	}()
+}
+
+// liveAccess starts the audit record of a live viewing session, it is
+// written when the session ends.
+func liveAccess(suuid string, owner *stream.ViewerOwner) *audit.Access {
+	return &audit.Access{
+		Kind:      audit.KindLive,
+		UserId:    owner.UserId,
+		Stream:    suuid,
+		ClientIP:  owner.ClientIP,
+		StartDate: timedate.GetTimestamp(),
+	}
 }
@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	msg "vhosting/internal/messages"
+	"vhosting/pkg/logger"
+	"vhosting/pkg/stream"
+	"vhosting/pkg/timedate"
+)
+
+func (h *StreamHandler) GetStreamUsage(ctx *gin.Context) {
+	actPermission := "get_stream_usage"
+
+	log := logger.Init(ctx)
+
+	hasPerms, _ := h.isPermsGranted_getUserId(ctx, log, actPermission)
+	if !hasPerms {
+		return
+	}
+
+	h.logUseCase.Report(ctx, log, msg.InfoGotStreamUsage(h.useCase.GetUsage()))
+}
+
+// acquireViewerSlot reserves a viewer slot for the stream, reporting
+// the exceeded limit to the client when the slot cannot be reserved.
+func (h *StreamHandler) acquireViewerSlot(ctx *gin.Context, suuid string) (string, *stream.ViewerOwner, bool) {
+	log := logger.Init(ctx)
+
+	owner, err := h.getViewerOwner(ctx)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotGetViewerGroups(err))
+		return "", nil, false
+	}
+
+	slotId, err := h.useCase.AcquireViewerSlot(suuid, owner)
+	switch {
+	case err == nil:
+		return slotId, owner, true
+	case errors.Is(err, stream.ErrViewersPerStreamLimit):
+		h.logUseCase.Report(ctx, log, msg.ErrorViewersPerStreamLimitReached(h.cfg.QuotaMaxViewersPerStream))
+	case errors.Is(err, stream.ErrStreamsPerUserLimit):
+		h.logUseCase.Report(ctx, log, msg.ErrorStreamsPerUserLimitReached(h.cfg.QuotaMaxStreamsPerUser))
+	case errors.Is(err, stream.ErrStreamsPerGroupLimit):
+		h.logUseCase.Report(ctx, log, msg.ErrorStreamsPerGroupLimitReached(h.cfg.QuotaMaxStreamsPerGroup))
+	case errors.Is(err, stream.ErrOutboundBitrateExceeded):
+		h.logUseCase.Report(ctx, log, msg.ErrorOutboundBitrateBudgetExceeded(h.cfg.QuotaOutboundBitrateBudgetKbps))
+	default:
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotAcquireViewerSlot(err))
+	}
+	return "", nil, false
+}
+
+// getViewerOwner identifies the viewer by the session token when it is
+// valid. Viewing does not require authorization, so any other viewer is
+// counted as a guest by its IP address.
+func (h *StreamHandler) getViewerOwner(ctx *gin.Context) (*stream.ViewerOwner, error) {
+	owner := &stream.ViewerOwner{UserId: -1, ClientIP: ctx.ClientIP(), GroupIds: []int{}}
+
+	token := h.authUseCase.ReadHeader(ctx)
+	if token == "" {
+		token = ctx.PostForm("token")
+	}
+	if !h.authUseCase.IsTokenExists(token) {
+		return owner, nil
+	}
+
+	session, err := h.sessUseCase.GetSessionAndDate(ctx, token)
+	if err != nil || !h.authUseCase.IsSessionExists(session) ||
+		timedate.IsDateExpired(session.RefreshDate, h.cfg.SessionTTLHours) {
+		return owner, nil
+	}
+
+	userId, err := h.userUseCase.GetUserId(session.Username)
+	if err != nil || userId < 0 {
+		return owner, nil
+	}
+
+	groupIds, err := h.useCase.GetUserGroupIds(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	owner.UserId = userId
+	owner.GroupIds = groupIds
+	return owner, nil
+}
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"github.com/gin-gonic/gin"
+	"vhosting/internal/audit"
 	sess "vhosting/internal/session"
 	"vhosting/pkg/auth"
 	"vhosting/pkg/config"
@@ -12,16 +13,16 @@ import (
 )
 
 func RegisterTemplateHTTPEndpoints(router *gin.Engine, cfg *config.Config, scfg *sconfig.Config, uc stream.StreamUseCase,
-	uuc user.UserUseCase, luc logger.LogUseCase, auc auth.AuthUseCase, suc sess.SessUseCase) {
-	h := NewStreamHandler(cfg, scfg, uc, uuc, luc, auc, suc)
+	uuc user.UserUseCase, luc logger.LogUseCase, auc auth.AuthUseCase, suc sess.SessUseCase, aduc audit.AuditUseCase) {
+	h := NewStreamHandler(cfg, scfg, uc, uuc, luc, auc, suc, aduc)
 
 	router.GET("/stream", h.ServeIndex)
 	router.GET("/stream/:uuid", h.ServeStream)
 }
 
 func RegisterStreamingHTTPEndpoints(router *gin.Engine, cfg *config.Config, scfg *sconfig.Config, uc stream.StreamUseCase,
-	uuc user.UserUseCase, luc logger.LogUseCase, auc auth.AuthUseCase, suc sess.SessUseCase) {
-	h := NewStreamHandler(cfg, scfg, uc, uuc, luc, auc, suc)
+	uuc user.UserUseCase, luc logger.LogUseCase, auc auth.AuthUseCase, suc sess.SessUseCase, aduc audit.AuditUseCase) {
+	h := NewStreamHandler(cfg, scfg, uc, uuc, luc, auc, suc, aduc)
 
 	streamRoute := router.Group("/stream")
 	{
@@ -31,5 +32,24 @@ func RegisterStreamingHTTPEndpoints(router *gin.Engine, cfg *config.Config, scfg
 
 		streamRoute.GET("/get/:id", h.GetStream)
 		streamRoute.GET("/get/all", h.GetAllStreams)
+
+		streamRoute.POST("/camera/:id", h.SetCamera)
+		streamRoute.GET("/camera/:id", h.GetCamera)
+		streamRoute.DELETE("/camera/:id", h.DeleteCamera)
+		streamRoute.GET("/geojson", h.GetCamerasGeoJSON)
+
+		streamRoute.POST("/source/:id", h.SetSource)
+		streamRoute.GET("/source/:id", h.GetSource)
+		streamRoute.DELETE("/source/:id", h.DeleteSource)
+
+		streamRoute.GET("/usage", h.GetStreamUsage)
+
+		streamRoute.GET("/timeline/:id", h.GetTimeline)
+	}
+
+	eventsRoute := router.Group("/events")
+	{
+		eventsRoute.POST("/ticket", h.CreateEventsTicket)
+		eventsRoute.GET("/streams", h.ServeStreamEvents)
 	}
 }
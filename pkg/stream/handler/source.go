@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	msg "vhosting/internal/messages"
+	"vhosting/pkg/logger"
+)
+
+func (h *StreamHandler) SetSource(ctx *gin.Context) {
+	actPermission := "set_stream_source"
+
+	log := logger.Init(ctx)
+
+	hasPerms, _ := h.isPermsGranted_getUserId(ctx, log, actPermission)
+	if !hasPerms {
+		return
+	}
+
+	// Read requested ID, check stream existence
+	reqId, err := h.useCase.AtoiRequestedId(ctx)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotConvertRequestedIDToTypeInt(err))
+		return
+	}
+
+	exists, err := h.useCase.IsStreamExists(reqId)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotCheckStreamExistence(err))
+		return
+	}
+	if !exists {
+		h.logUseCase.Report(ctx, log, msg.ErrorStreamWithRequestedIDIsNotExist())
+		return
+	}
+
+	// Read input, check URL and transport, set source
+	inputSource, err := h.useCase.BindJSONSource(ctx)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotBindInputData(err))
+		return
+	}
+
+	if !h.useCase.IsSourceURLValid(inputSource.URL) {
+		h.logUseCase.Report(ctx, log, msg.ErrorStreamSourceURLIsInvalid())
+		return
+	}
+
+	if !h.useCase.IsSourceTransportValid(inputSource.Transport) {
+		h.logUseCase.Report(ctx, log, msg.ErrorStreamSourceTransportIsInvalid())
+		return
+	}
+
+	inputSource.StreamId = reqId
+
+	if err := h.useCase.SetSource(inputSource); err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotSetStreamSource(err))
+		return
+	}
+
+	h.logUseCase.Report(ctx, log, msg.InfoStreamSourceSet())
+}
+
+func (h *StreamHandler) GetSource(ctx *gin.Context) {
+	actPermission := "get_stream_source"
+
+	log := logger.Init(ctx)
+
+	hasPerms, _ := h.isPermsGranted_getUserId(ctx, log, actPermission)
+	if !hasPerms {
+		return
+	}
+
+	// Read requested ID, check source existence, get source
+	reqId, err := h.useCase.AtoiRequestedId(ctx)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotConvertRequestedIDToTypeInt(err))
+		return
+	}
+
+	exists, err := h.useCase.IsSourceExists(reqId)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotCheckStreamSourceExistence(err))
+		return
+	}
+	if !exists {
+		h.logUseCase.Report(ctx, log, msg.ErrorStreamSourceWithRequestedStreamIDIsNotExist())
+		return
+	}
+
+	gottenSource, err := h.useCase.GetSource(reqId)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotGetStreamSource(err))
+		return
+	}
+
+	h.logUseCase.Report(ctx, log, msg.InfoGotStreamSource(gottenSource))
+}
+
+func (h *StreamHandler) DeleteSource(ctx *gin.Context) {
+	actPermission := "delete_stream_source"
+
+	log := logger.Init(ctx)
+
+	hasPerms, _ := h.isPermsGranted_getUserId(ctx, log, actPermission)
+	if !hasPerms {
+		return
+	}
+
+	// Read requested ID, check source existence, delete source
+	reqId, err := h.useCase.AtoiRequestedId(ctx)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotConvertRequestedIDToTypeInt(err))
+		return
+	}
+
+	exists, err := h.useCase.IsSourceExists(reqId)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotCheckStreamSourceExistence(err))
+		return
+	}
+	if !exists {
+		h.logUseCase.Report(ctx, log, msg.ErrorStreamSourceWithRequestedStreamIDIsNotExist())
+		return
+	}
+
+	if err := h.useCase.DeleteSource(reqId); err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotDeleteStreamSource(err))
+		return
+	}
+
+	h.logUseCase.Report(ctx, log, msg.InfoStreamSourceDeleted())
+}
@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	msg "vhosting/internal/messages"
+	"vhosting/pkg/logger"
+)
+
+func (h *StreamHandler) GetTimeline(ctx *gin.Context) {
+	actPermission := "get_stream_timeline"
+
+	log := logger.Init(ctx)
+
+	hasPerms, _ := h.isPermsGranted_getUserId(ctx, log, actPermission)
+	if !hasPerms {
+		return
+	}
+
+	// Read requested ID and period, check stream existence, get timeline
+	reqId, err := h.useCase.AtoiRequestedId(ctx)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotConvertRequestedIDToTypeInt(err))
+		return
+	}
+
+	from, to, ok := h.useCase.ParseTimelinePeriod(ctx)
+	if !ok {
+		h.logUseCase.Report(ctx, log, msg.ErrorTimelinePeriodIsInvalid())
+		return
+	}
+	if h.useCase.IsTimelinePeriodTooLong(from, to) {
+		h.logUseCase.Report(ctx, log, msg.ErrorTimelinePeriodIsTooLong(h.cfg.StreamTimelineMaxDays))
+		return
+	}
+
+	exists, err := h.useCase.IsStreamExists(reqId)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotCheckStreamExistence(err))
+		return
+	}
+	if !exists {
+		h.logUseCase.Report(ctx, log, msg.ErrorStreamWithRequestedIDIsNotExist())
+		return
+	}
+
+	timeline, err := h.useCase.GetTimeline(reqId, from, to, h.useCase.IsTimelineDaysRequested(ctx))
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotGetStreamTimeline(err))
+		return
+	}
+
+	h.logUseCase.Report(ctx, log, msg.InfoGotStreamTimeline(timeline))
+}
@@ -0,0 +1,200 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+	qconsts "vhosting/pkg/constants/query"
+	"vhosting/pkg/db_connect"
+	"vhosting/pkg/stream"
+	"vhosting/pkg/user"
+)
+
+var camColumns = fmt.Sprintf("%s, %s, %s, %s, %s, %s, %s, %s, %s",
+	stream.CamStreamId, stream.CamDisplayName, stream.CamSite,
+	stream.CamBuilding, stream.CamFloor, stream.CamLatitude,
+	stream.CamLongitude, stream.CamTags, stream.CamGroups)
+
+func (r *StreamRepository) SetCamera(cam *stream.Camera) error {
+	db := db_connect.CreateLocalDBConnection(r.cfg)
+	defer db_connect.CloseDBConnection(r.cfg, db)
+
+	template := qconsts.UPSERT_INTO_TBL_VALUES_VAL_ON_COL
+	tbl := fmt.Sprintf("%s (%s)", stream.CamTableName, camColumns)
+	val := "($1, $2, $3, $4, $5, $6, $7, $8, $9)"
+	col := stream.CamStreamId
+	set := fmt.Sprintf("%s=$2, %s=$3, %s=$4, %s=$5, %s=$6, %s=$7, %s=$8, %s=$9",
+		stream.CamDisplayName, stream.CamSite, stream.CamBuilding,
+		stream.CamFloor, stream.CamLatitude, stream.CamLongitude,
+		stream.CamTags, stream.CamGroups)
+	query := fmt.Sprintf(template, tbl, val, col, set)
+
+	rows, err := db.Query(query, cam.StreamId, cam.DisplayName, cam.Site,
+		cam.Building, cam.Floor, cam.Latitude, cam.Longitude,
+		pq.Array(cam.Tags), pq.Array(cam.Groups))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	return nil
+}
+
+func (r *StreamRepository) GetCamera(streamId int) (*stream.Camera, error) {
+	db := db_connect.CreateLocalDBConnection(r.cfg)
+	defer db_connect.CloseDBConnection(r.cfg, db)
+
+	template := qconsts.SELECT_COL_FROM_TBL_WHERE_CND
+	tbl := stream.CamTableName
+	cnd := fmt.Sprintf("%s=$1", stream.CamStreamId)
+	query := fmt.Sprintf(template, camColumns, tbl, cnd)
+
+	rows, err := db.Query(query, streamId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, rows.Err()
+	}
+
+	var cam stream.Camera
+	if err := rows.Scan(&cam.StreamId, &cam.DisplayName, &cam.Site,
+		&cam.Building, &cam.Floor, &cam.Latitude, &cam.Longitude,
+		pq.Array(&cam.Tags), pq.Array(&cam.Groups)); err != nil {
+		return nil, err
+	}
+
+	return &cam, nil
+}
+
+func (r *StreamRepository) GetCameras(streamIds []int) (map[int]*stream.Camera, error) {
+	var cameras = map[int]*stream.Camera{}
+	if len(streamIds) == 0 {
+		return cameras, nil
+	}
+
+	db := db_connect.CreateLocalDBConnection(r.cfg)
+	defer db_connect.CloseDBConnection(r.cfg, db)
+
+	template := qconsts.SELECT_COL_FROM_TBL_WHERE_CND
+	tbl := stream.CamTableName
+	cnd := fmt.Sprintf("%s = ANY($1)", stream.CamStreamId)
+	query := fmt.Sprintf(template, camColumns, tbl, cnd)
+
+	rows, err := db.Query(query, pq.Array(streamIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var cam stream.Camera
+		if err := rows.Scan(&cam.StreamId, &cam.DisplayName, &cam.Site,
+			&cam.Building, &cam.Floor, &cam.Latitude, &cam.Longitude,
+			pq.Array(&cam.Tags), pq.Array(&cam.Groups)); err != nil {
+			return nil, err
+		}
+		cameras[cam.StreamId] = &cam
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return cameras, nil
+}
+
+func (r *StreamRepository) GetFilteredCameras(filter *stream.CameraFilter, urlparams *user.Pagin) ([]*stream.Camera, error) {
+	db := db_connect.CreateLocalDBConnection(r.cfg)
+	defer db_connect.CloseDBConnection(r.cfg, db)
+
+	template := qconsts.SELECT_COL_FROM_TBL_WHERE_CND
+	tbl := stream.CamTableName
+	cnd := strings.Join([]string{
+		fmt.Sprintf("($1 = '' OR %s=$1)", stream.CamSite),
+		fmt.Sprintf("($2 = '' OR %s=$2)", stream.CamBuilding),
+		fmt.Sprintf("($3 = '' OR %s=$3)", stream.CamFloor),
+		fmt.Sprintf("($4 = '' OR $4 = ANY(%s))", stream.CamTags),
+		fmt.Sprintf("($5 = '' OR $5 = ANY(%s))", stream.CamGroups),
+	}, " AND ")
+	query := fmt.Sprintf(template, camColumns, tbl, cnd) +
+		fmt.Sprintf(" ORDER BY %s", stream.CamStreamId)
+
+	args := []interface{}{filter.Site, filter.Building, filter.Floor,
+		filter.Tag, filter.Group}
+	if urlparams != nil {
+		offset := urlparams.Page
+		if offset < 0 {
+			offset = 0
+		}
+		query += " LIMIT $6 OFFSET $7"
+		args = append(args, urlparams.Limit, offset)
+	}
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cameras := []*stream.Camera{}
+	for rows.Next() {
+		var cam stream.Camera
+		if err := rows.Scan(&cam.StreamId, &cam.DisplayName, &cam.Site,
+			&cam.Building, &cam.Floor, &cam.Latitude, &cam.Longitude,
+			pq.Array(&cam.Tags), pq.Array(&cam.Groups)); err != nil {
+			return nil, err
+		}
+		cameras = append(cameras, &cam)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return cameras, nil
+}
+
+func (r *StreamRepository) DeleteCamera(streamId int) error {
+	db := db_connect.CreateLocalDBConnection(r.cfg)
+	defer db_connect.CloseDBConnection(r.cfg, db)
+
+	template := qconsts.DELETE_FROM_TBL_WHERE_CND
+	tbl := stream.CamTableName
+	cnd := fmt.Sprintf("%s=$1", stream.CamStreamId)
+	query := fmt.Sprintf(template, tbl, cnd)
+
+	rows, err := db.Query(query, streamId)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	return nil
+}
+
+func (r *StreamRepository) IsCameraExists(streamId int) (bool, error) {
+	db := db_connect.CreateLocalDBConnection(r.cfg)
+	defer db_connect.CloseDBConnection(r.cfg, db)
+
+	template := qconsts.SELECT_COL_FROM_TBL_WHERE_CND
+	col := stream.CamStreamId
+	tbl := stream.CamTableName
+	cnd := fmt.Sprintf("%s=$1", stream.CamStreamId)
+	query := fmt.Sprintf(template, col, tbl, cnd)
+
+	rows, err := db.Query(query, streamId)
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	if isRowPresent := rows.Next(); !isRowPresent {
+		return false, nil
+	}
+
+	return true, nil
+}
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"fmt"
+
+	"vhosting/internal/group"
+	qconsts "vhosting/pkg/constants/query"
+	"vhosting/pkg/db_connect"
+)
+
+func (r *StreamRepository) GetUserGroupIds(userId int) ([]int, error) {
+	db := db_connect.CreateLocalDBConnection(r.cfg)
+	defer db_connect.CloseDBConnection(r.cfg, db)
+
+	template := qconsts.SELECT_COL_FROM_TBL_WHERE_CND
+	col := group.GroupId
+	tbl := group.UGTableName
+	cnd := fmt.Sprintf("%s=$1", group.UserId)
+	query := fmt.Sprintf(template, col, tbl, cnd)
+
+	rows, err := db.Query(query, userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	groupIds := []int{}
+	var grp int
+	for rows.Next() {
+		if err := rows.Scan(&grp); err != nil {
+			return nil, err
+		}
+		groupIds = append(groupIds, grp)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return groupIds, nil
+}
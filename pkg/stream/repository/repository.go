@@ -2,6 +2,8 @@ package repository
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 
 	"vhosting/internal/constants"
 	"vhosting/pkg/config"
@@ -144,3 +146,52 @@ func (r *StreamRepository) IsStreamExists(id int) (bool, error) {
 
 	return true, nil
 }
+
+func (r *StreamRepository) GetStreamsByIds(ids []int) (map[int]*stream.StreamGet, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	r.cfg.DBOName = constants.DBO_L3_Name
+	dbo := db_connect.CreateOuterDBConnection(r.cfg)
+	defer db_connect.CloseDBConnection(r.cfg, dbo)
+
+	condIds := make([]string, len(ids))
+	for i, id := range ids {
+		condIds[i] = strconv.Itoa(id)
+	}
+
+	template := qconsts.SELECT_COL_FROM_TBL_WHERE_CND
+	col := fmt.Sprintf("%s, %s, %s, %s, %s", stream.Id,
+		stream.StreamColumn, stream.DateTime,
+		stream.StatusPublic, stream.PathStream)
+	tbl := stream.TableName
+	cnd := fmt.Sprintf("%s IN (%s)", stream.Id, strings.Join(condIds, ","))
+	query := fmt.Sprintf(template, col, tbl, cnd)
+
+	rows, err := dbo.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var streams = map[int]*stream.StreamGet{}
+	for rows.Next() {
+		var strm stream.StreamGet
+		if err := rows.Scan(&strm.Id, &strm.Stream, &strm.DateTime,
+			&strm.StatusPublic, &strm.PathStream); err != nil {
+			return nil, err
+		}
+		streams[strm.Id] = &strm
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(streams) == 0 {
+		return nil, nil
+	}
+
+	return streams, nil
+}
@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"fmt"
+
+	qconsts "vhosting/pkg/constants/query"
+	"vhosting/pkg/db_connect"
+	"vhosting/pkg/stream"
+)
+
+var srcColumns = fmt.Sprintf("%s, %s, %s, %s, %s",
+	stream.SrcStreamId, stream.SrcURL, stream.SrcTransport,
+	stream.SrcUsername, stream.SrcPassword)
+
+// SetSource stores the source as is, credentials are expected
+// to be encrypted by the caller.
+func (r *StreamRepository) SetSource(src *stream.Source) error {
+	db := db_connect.CreateLocalDBConnection(r.cfg)
+	defer db_connect.CloseDBConnection(r.cfg, db)
+
+	template := qconsts.UPSERT_INTO_TBL_VALUES_VAL_ON_COL
+	tbl := fmt.Sprintf("%s (%s)", stream.SrcTableName, srcColumns)
+	val := "($1, $2, $3, $4, $5)"
+	col := stream.SrcStreamId
+	set := fmt.Sprintf("%s=$2, %s=$3, %s=$4, %s=$5", stream.SrcURL,
+		stream.SrcTransport, stream.SrcUsername, stream.SrcPassword)
+	query := fmt.Sprintf(template, tbl, val, col, set)
+
+	rows, err := db.Query(query, src.StreamId, src.URL, src.Transport,
+		src.Username, src.Password)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	return nil
+}
+
+func (r *StreamRepository) GetSource(streamId int) (*stream.Source, error) {
+	db := db_connect.CreateLocalDBConnection(r.cfg)
+	defer db_connect.CloseDBConnection(r.cfg, db)
+
+	template := qconsts.SELECT_COL_FROM_TBL_WHERE_CND
+	tbl := stream.SrcTableName
+	cnd := fmt.Sprintf("%s=$1", stream.SrcStreamId)
+	query := fmt.Sprintf(template, srcColumns, tbl, cnd)
+
+	var src stream.Source
+	if err := db.Get(&src, query, streamId); err != nil {
+		return nil, err
+	}
+
+	return &src, nil
+}
+
+func (r *StreamRepository) GetAllSources() (map[int]*stream.Source, error) {
+	db := db_connect.CreateLocalDBConnection(r.cfg)
+	defer db_connect.CloseDBConnection(r.cfg, db)
+
+	template := qconsts.SELECT_COL_FROM_TBL
+	tbl := stream.SrcTableName
+	query := fmt.Sprintf(template, srcColumns, tbl)
+
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sources = map[int]*stream.Source{}
+	for rows.Next() {
+		var src stream.Source
+		if err := rows.Scan(&src.StreamId, &src.URL, &src.Transport,
+			&src.Username, &src.Password); err != nil {
+			return nil, err
+		}
+		sources[src.StreamId] = &src
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return sources, nil
+}
+
+func (r *StreamRepository) DeleteSource(streamId int) error {
+	db := db_connect.CreateLocalDBConnection(r.cfg)
+	defer db_connect.CloseDBConnection(r.cfg, db)
+
+	template := qconsts.DELETE_FROM_TBL_WHERE_CND
+	tbl := stream.SrcTableName
+	cnd := fmt.Sprintf("%s=$1", stream.SrcStreamId)
+	query := fmt.Sprintf(template, tbl, cnd)
+
+	rows, err := db.Query(query, streamId)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	return nil
+}
+
+func (r *StreamRepository) IsSourceExists(streamId int) (bool, error) {
+	db := db_connect.CreateLocalDBConnection(r.cfg)
+	defer db_connect.CloseDBConnection(r.cfg, db)
+
+	template := qconsts.SELECT_COL_FROM_TBL_WHERE_CND
+	col := stream.SrcStreamId
+	tbl := stream.SrcTableName
+	cnd := fmt.Sprintf("%s=$1", stream.SrcStreamId)
+	query := fmt.Sprintf(template, col, tbl, cnd)
+
+	rows, err := db.Query(query, streamId)
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	if isRowPresent := rows.Next(); !isRowPresent {
+		return false, nil
+	}
+
+	return true, nil
+}
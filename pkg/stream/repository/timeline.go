@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"vhosting/internal/constants"
+	qconsts "vhosting/pkg/constants/query"
+	"vhosting/pkg/db_connect"
+	"vhosting/pkg/stream"
+)
+
+const recordDatetimeLayout = "2006-01-02 15:04:05.000"
+
+// GetRecordTimes returns the start times of the stream segments in the
+// period, the segment started before it is included as it may cover its
+// beginning. The "recordTime" wall clock is read in the local time zone.
+func (r *StreamRepository) GetRecordTimes(pathStream string, from, to time.Time) ([]time.Time, error) {
+	r.cfg.DBOName = constants.DBO_L3_Name
+	dbo := db_connect.CreateOuterDBConnection(r.cfg)
+	defer db_connect.CloseDBConnection(r.cfg, dbo)
+
+	template := qconsts.SELECT_COL_FROM_TBL_WHERE_CND
+	col := stream.RecTime
+	tbl := stream.RecTableName
+	prev := fmt.Sprintf(template, "MAX("+stream.RecTime+")", tbl,
+		fmt.Sprintf("%s=$1 AND %s<=$2", stream.RecPathStream, stream.RecTime))
+	cnd := fmt.Sprintf("%s=$1 AND %s>=COALESCE((%s), $2) AND %s<$3 ORDER BY %s",
+		stream.RecPathStream, stream.RecTime, prev, stream.RecTime, stream.RecTime)
+	query := fmt.Sprintf(template, col, tbl, cnd)
+
+	rows, err := dbo.Query(query, pathStream, from.Format(recordDatetimeLayout),
+		to.Format(recordDatetimeLayout))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	times := []time.Time{}
+	for rows.Next() {
+		var t time.Time
+		if err := rows.Scan(&t); err != nil {
+			return nil, err
+		}
+		times = append(times, time.Date(t.Year(), t.Month(), t.Day(), t.Hour(),
+			t.Minute(), t.Second(), t.Nanosecond(), time.Local))
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return times, nil
+}
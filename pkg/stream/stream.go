@@ -2,6 +2,8 @@ package stream
 
 import (
 	"database/sql"
+	"errors"
+	"time"
 	"vhosting/pkg/user"
 
 	"github.com/deepch/vdk/av"
@@ -10,13 +12,14 @@ import (
 )
 
 type Stream struct {
-	Id           int    `json:"id" db:"id"`
-	Stream       string `json:"stream" db:"Stream"`
-	DateTime     string `json:"dateTime" db:"DateTime"`
-	StatePublic  int    `json:"-" db:"StatePublic"`
-	StatusPublic int    `json:"statusPublic" db:"StatusPublic"`
-	StatusRecord int    `json:"-" db:"StatusRecord"`
-	PathStream   string `json:"pathStream" db:"pathStream"`
+	Id           int     `json:"id" db:"id"`
+	Stream       string  `json:"stream" db:"Stream"`
+	DateTime     string  `json:"dateTime" db:"DateTime"`
+	StatePublic  int     `json:"-" db:"StatePublic"`
+	StatusPublic int     `json:"statusPublic" db:"StatusPublic"`
+	StatusRecord int     `json:"-" db:"StatusRecord"`
+	PathStream   string  `json:"pathStream" db:"pathStream"`
+	Camera       *Camera `json:"camera,omitempty"`
 }
 
 type StreamGet struct {
@@ -29,6 +32,72 @@ type StreamGet struct {
 	PathStream   sql.NullString `db:"pathStream"`
 }
 
+type Camera struct {
+	StreamId    int      `json:"streamId"    db:"stream_id"`
+	DisplayName string   `json:"displayName" db:"display_name"`
+	Site        string   `json:"site"        db:"site"`
+	Building    string   `json:"building"    db:"building"`
+	Floor       string   `json:"floor"       db:"floor"`
+	Latitude    *float64 `json:"latitude"    db:"latitude"`
+	Longitude   *float64 `json:"longitude"   db:"longitude"`
+	Tags        []string `json:"tags"        db:"tags"`
+	Groups      []string `json:"groups"      db:"camera_groups"`
+}
+
+// Source holds the decrypted credentials and must never be sent in
+// responses or logs, use SourceView instead.
+type Source struct {
+	StreamId  int    `json:"streamId"  db:"stream_id"`
+	URL       string `json:"url"       db:"url"`
+	Transport string `json:"transport" db:"transport"`
+	Username  string `json:"username"  db:"username_enc"`
+	Password  string `json:"password"  db:"password_enc"`
+}
+
+type SourceView struct {
+	StreamId       int    `json:"streamId"`
+	URL            string `json:"url"`
+	Transport      string `json:"transport"`
+	HasCredentials bool   `json:"hasCredentials"`
+}
+
+type CameraFilter struct {
+	Site     string
+	Building string
+	Floor    string
+	Tag      string
+	Group    string
+}
+
+type GeoJSONFeatureCollection struct {
+	Type     string           `json:"type"`
+	Features []GeoJSONFeature `json:"features"`
+}
+
+type GeoJSONFeature struct {
+	Type       string            `json:"type"`
+	Geometry   GeoJSONGeometry   `json:"geometry"`
+	Properties GeoJSONProperties `json:"properties"`
+}
+
+type GeoJSONGeometry struct {
+	Type        string     `json:"type"`
+	Coordinates [2]float64 `json:"coordinates"` // longitude, latitude
+}
+
+type GeoJSONProperties struct {
+	StreamId    int      `json:"streamId"`
+	Stream      string   `json:"stream"`
+	DisplayName string   `json:"displayName"`
+	Site        string   `json:"site"`
+	Building    string   `json:"building"`
+	Floor       string   `json:"floor"`
+	Tags        []string `json:"tags"`
+	Groups      []string `json:"groups"`
+	Online      bool     `json:"online"`
+	Viewers     int      `json:"viewers"`
+}
+
 type JCodec struct {
 	Type string
 }
@@ -38,6 +107,56 @@ type Response struct {
 	Sdp64  string   `json:"sdp64"`
 }
 
+type State struct {
+	Stream  string   `json:"stream"`
+	Online  bool     `json:"online"`
+	Codecs  []string `json:"codecs"`
+	Viewers int      `json:"viewers"`
+}
+
+type Event struct {
+	Type  string `json:"type"` // "online", "offline", "codec", "viewers"
+	State State  `json:"state"`
+	Time  string `json:"time"`
+}
+
+// EventsTicket lets an EventSource, which cannot send the Authorization
+// header, subscribe to the events once and shortly after it is issued.
+type EventsTicket struct {
+	Ticket         string `json:"ticket"`
+	ExpirationDate string `json:"expirationDate"`
+}
+
+type ViewerOwner struct {
+	UserId   int // -1 for unauthenticated viewers
+	ClientIP string
+	GroupIds []int
+}
+
+type Limits struct {
+	MaxViewersPerStream       int `json:"maxViewersPerStream"`
+	MaxStreamsPerUser         int `json:"maxStreamsPerUser"`
+	MaxStreamsPerGroup        int `json:"maxStreamsPerGroup"`
+	OutboundBitrateBudgetKbps int `json:"outboundBitrateBudgetKbps"`
+}
+
+type Usage struct {
+	Limits       Limits         `json:"limits"`
+	Viewers      int            `json:"viewers"`
+	OutboundKbps int            `json:"outboundKbps"`
+	Streams      map[string]int `json:"streams"`
+	Users        map[int]int    `json:"users"`
+	Guests       map[string]int `json:"guests"` // unauthenticated viewers by IP
+	Groups       map[int]int    `json:"groups"`
+}
+
+var (
+	ErrViewersPerStreamLimit   = errors.New("viewers per stream limit reached")
+	ErrStreamsPerUserLimit     = errors.New("concurrent streams per user limit reached")
+	ErrStreamsPerGroupLimit    = errors.New("concurrent streams per group limit reached")
+	ErrOutboundBitrateExceeded = errors.New("outbound bitrate budget exceeded")
+)
+
 type StreamCommon interface {
 	IsStreamExists(id int) (bool, error)
 }
@@ -46,13 +165,32 @@ type StreamUseCase interface {
 	StreamCommon
 
 	GetStream(id int) (*Stream, error)
-	GetAllStreams(urlparams *user.Pagin) (map[int]*Stream, error)
+	GetAllStreams(urlparams *user.Pagin, filter *CameraFilter) (map[int]*Stream, error)
+
+	SetCamera(cam *Camera) error
+	GetCamera(streamId int) (*Camera, error)
+	DeleteCamera(streamId int) error
+	GetCamerasGeoJSON(filter *CameraFilter) (*GeoJSONFeatureCollection, error)
+	IsCameraExists(streamId int) (bool, error)
+
+	SetSource(src *Source) error
+	GetSource(streamId int) (*SourceView, error)
+	DeleteSource(streamId int) error
+	IsSourceExists(streamId int) (bool, error)
+	IsSourceURLValid(rawURL string) bool
+	IsSourceTransportValid(transport string) bool
+	DefaultSourceURL(name string) string
 
 	AtoiRequestedId(ctx *gin.Context) (int, error)
 	ParseURLParams(ctx *gin.Context) *user.Pagin
+	ParseCameraFilter(ctx *gin.Context) *CameraFilter
+	BindJSONCamera(ctx *gin.Context) (*Camera, error)
+	BindJSONSource(ctx *gin.Context) (*Source, error)
 
 	ServeStreams()
+	ReloadConfig() error
 	Exit(suuid string) bool
+	AddOnDemandStream(suuid string)
 	RunIfNotRun(uuid string)
 	CodecGet(suuid string) []av.CodecData
 	GetICEServers() []string
@@ -60,10 +198,26 @@ type StreamUseCase interface {
 	GetICECredential() string
 	GetWebRTCPortMin() uint16
 	GetWebRTCPortMax() uint16
-	WritePackets(url string, muxerWebRTC *webrtc.Muxer, audioOnly bool)
+	WritePackets(url string, muxerWebRTC *webrtc.Muxer, audioOnly bool) int64
 	CastListAdd(suuid string) (string, chan av.Packet)
 	CastListDelete(suuid, cuuid string)
 	List() (string, []string)
+
+	States() []State
+	SubscribeEvents() (string, chan Event)
+	UnsubscribeEvents(id string)
+	CreateEventsTicket(username string) (*EventsTicket, error)
+	UseEventsTicket(ticket string) (string, bool)
+
+	GetUserGroupIds(userId int) ([]int, error)
+	AcquireViewerSlot(suuid string, owner *ViewerOwner) (string, error)
+	ReleaseViewerSlot(slotId string)
+	GetUsage() *Usage
+
+	GetTimeline(streamId int, from, to time.Time, days bool) (*Timeline, error)
+	ParseTimelinePeriod(ctx *gin.Context) (time.Time, time.Time, bool)
+	IsTimelinePeriodTooLong(from, to time.Time) bool
+	IsTimelineDaysRequested(ctx *gin.Context) bool
 }
 
 type StreamRepository interface {
@@ -72,4 +226,54 @@ type StreamRepository interface {
 	GetStream(id int) (*StreamGet, error)
 	GetAllStreams(urlparams *user.Pagin) (map[int]*StreamGet, error)
 	GetAllWorkingStreams() (*[]string, error)
+	GetStreamsByIds(ids []int) (map[int]*StreamGet, error)
+	GetUserGroupIds(userId int) ([]int, error)
+
+	SetCamera(cam *Camera) error
+	GetCamera(streamId int) (*Camera, error)
+	GetCameras(streamIds []int) (map[int]*Camera, error)
+	GetFilteredCameras(filter *CameraFilter, urlparams *user.Pagin) ([]*Camera, error)
+	DeleteCamera(streamId int) error
+	IsCameraExists(streamId int) (bool, error)
+
+	SetSource(src *Source) error
+	GetSource(streamId int) (*Source, error)
+	GetAllSources() (map[int]*Source, error)
+	DeleteSource(streamId int) error
+	IsSourceExists(streamId int) (bool, error)
+
+	GetRecordTimes(pathStream string, from, to time.Time) ([]time.Time, error)
+}
+
+// Timeline is the footage recorded in the period, ranges and gaps are
+// clipped to it. Durations are in seconds.
+type Timeline struct {
+	StreamId int              `json:"streamId"`
+	From     string           `json:"from"`
+	To       string           `json:"to"`
+	Recorded float64          `json:"recorded"`
+	Coverage float64          `json:"coverage"` // percent of the period
+	Ranges   []*TimelineRange `json:"ranges"`
+	Gaps     []*TimelineRange `json:"gaps"`
+	Days     []*TimelineDay   `json:"days,omitempty"`
+}
+
+// TimelineRange is a continuous recording made of segments, or a gap
+// between recordings which has no segments.
+type TimelineRange struct {
+	From     string             `json:"from"`
+	To       string             `json:"to"`
+	Duration float64            `json:"duration"`
+	Segments []*TimelineSegment `json:"segments,omitempty"`
+}
+
+type TimelineSegment struct {
+	Start    string  `json:"start"`
+	Duration float64 `json:"duration"`
+}
+
+type TimelineDay struct {
+	Date     string  `json:"date"`
+	Recorded float64 `json:"recorded"`
+	Coverage float64 `json:"coverage"`
 }
@@ -0,0 +1,109 @@
+package usecase
+
+import (
+	"github.com/gin-gonic/gin"
+	"vhosting/pkg/stream"
+)
+
+func (u *StreamUseCase) SetCamera(cam *stream.Camera) error {
+	if cam.Tags == nil {
+		cam.Tags = []string{}
+	}
+	if cam.Groups == nil {
+		cam.Groups = []string{}
+	}
+	return u.streamRepo.SetCamera(cam)
+}
+
+func (u *StreamUseCase) GetCamera(streamId int) (*stream.Camera, error) {
+	return u.streamRepo.GetCamera(streamId)
+}
+
+func (u *StreamUseCase) DeleteCamera(streamId int) error {
+	return u.streamRepo.DeleteCamera(streamId)
+}
+
+func (u *StreamUseCase) IsCameraExists(streamId int) (bool, error) {
+	return u.streamRepo.IsCameraExists(streamId)
+}
+
+func (u *StreamUseCase) GetCamerasGeoJSON(filter *stream.CameraFilter) (*stream.GeoJSONFeatureCollection, error) {
+	cameras, err := u.streamRepo.GetFilteredCameras(filter, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := []int{}
+	for _, cam := range cameras {
+		ids = append(ids, cam.StreamId)
+	}
+	streams, err := u.streamRepo.GetStreamsByIds(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	states := map[string]stream.State{}
+	for _, state := range u.States() {
+		states[state.Stream] = state
+	}
+
+	collection := stream.GeoJSONFeatureCollection{Type: "FeatureCollection",
+		Features: []stream.GeoJSONFeature{}}
+	for _, cam := range cameras {
+		if cam.Latitude == nil || cam.Longitude == nil {
+			continue
+		}
+		name := ""
+		if strm, ok := streams[cam.StreamId]; ok {
+			name = strm.Stream.String
+		}
+		state := states[name]
+		collection.Features = append(collection.Features, stream.GeoJSONFeature{
+			Type: "Feature",
+			Geometry: stream.GeoJSONGeometry{
+				Type:        "Point",
+				Coordinates: [2]float64{*cam.Longitude, *cam.Latitude},
+			},
+			Properties: stream.GeoJSONProperties{
+				StreamId:    cam.StreamId,
+				Stream:      name,
+				DisplayName: cam.DisplayName,
+				Site:        cam.Site,
+				Building:    cam.Building,
+				Floor:       cam.Floor,
+				Tags:        cam.Tags,
+				Groups:      cam.Groups,
+				Online:      state.Online,
+				Viewers:     state.Viewers,
+			},
+		})
+	}
+
+	return &collection, nil
+}
+
+func (u *StreamUseCase) ParseCameraFilter(ctx *gin.Context) *stream.CameraFilter {
+	urlparams := ctx.Request.URL.Query()
+	return &stream.CameraFilter{
+		Site:     urlparams.Get("site"),
+		Building: urlparams.Get("building"),
+		Floor:    urlparams.Get("floor"),
+		Tag:      urlparams.Get("tag"),
+		Group:    urlparams.Get("group"),
+	}
+}
+
+func (u *StreamUseCase) BindJSONCamera(ctx *gin.Context) (*stream.Camera, error) {
+	var cam stream.Camera
+	if err := ctx.BindJSON(&cam); err != nil {
+		return &cam, err
+	}
+	return &cam, nil
+}
+
+func isCameraFilterEmpty(filter *stream.CameraFilter) bool {
+	if filter == nil {
+		return true
+	}
+	return *filter == stream.CameraFilter{}
+}
@@ -0,0 +1,119 @@
+package usecase
+
+import (
+	"sort"
+	"time"
+
+	sconfig "vhosting/pkg/config_stream"
+	"vhosting/pkg/hasher"
+	"vhosting/pkg/stream"
+	"vhosting/pkg/timedate"
+)
+
+const (
+	eventsBufferSize       = 32
+	eventsTicketTTLSeconds = 30
+)
+
+type eventsTicket struct {
+	username       string
+	expirationDate time.Time
+}
+
+func (u *StreamUseCase) States() []stream.State {
+	u.scfg.StreamsMutex.RLock()
+	defer u.scfg.StreamsMutex.RUnlock()
+	states := []stream.State{}
+	for name, cfg := range u.scfg.Streams {
+		states = append(states, streamState(name, cfg))
+	}
+	sort.Slice(states, func(i, j int) bool {
+		return states[i].Stream < states[j].Stream
+	})
+	return states
+}
+
+func (u *StreamUseCase) SubscribeEvents() (string, chan stream.Event) {
+	u.eventsMutex.Lock()
+	defer u.eventsMutex.Unlock()
+	id := pseudoUUID()
+	ch := make(chan stream.Event, eventsBufferSize)
+	u.eventListeners[id] = ch
+	return id, ch
+}
+
+func (u *StreamUseCase) UnsubscribeEvents(id string) {
+	u.eventsMutex.Lock()
+	defer u.eventsMutex.Unlock()
+	delete(u.eventListeners, id)
+}
+
+// CreateEventsTicket issues a ticket for subscribing to the events as the
+// user. The ticket is put in the URL, so it is used once and lives only a
+// few seconds, the expired tickets are dropped here.
+func (u *StreamUseCase) CreateEventsTicket(username string) (*stream.EventsTicket, error) {
+	ticket, err := hasher.GenerateRandomToken()
+	if err != nil {
+		return nil, err
+	}
+	expirationDate := time.Now().Add(eventsTicketTTLSeconds * time.Second)
+
+	u.eventsMutex.Lock()
+	defer u.eventsMutex.Unlock()
+	now := time.Now()
+	for key, t := range u.eventsTickets {
+		if now.After(t.expirationDate) {
+			delete(u.eventsTickets, key)
+		}
+	}
+	u.eventsTickets[hasher.HashToken(ticket)] = eventsTicket{username: username, expirationDate: expirationDate}
+
+	return &stream.EventsTicket{Ticket: ticket, ExpirationDate: expirationDate.Format(time.RFC3339)}, nil
+}
+
+// UseEventsTicket returns the user of the ticket and forgets the ticket.
+func (u *StreamUseCase) UseEventsTicket(ticket string) (string, bool) {
+	if ticket == "" {
+		return "", false
+	}
+	key := hasher.HashToken(ticket)
+
+	u.eventsMutex.Lock()
+	defer u.eventsMutex.Unlock()
+	t, ok := u.eventsTickets[key]
+	if !ok {
+		return "", false
+	}
+	delete(u.eventsTickets, key)
+	if time.Now().After(t.expirationDate) {
+		return "", false
+	}
+	return t.username, true
+}
+
+// publishEvent sends the event to every subscriber. Slow subscribers
+// whose buffer is full miss the event instead of blocking stream workers.
+func (u *StreamUseCase) publishEvent(eventType string, state stream.State) {
+	event := stream.Event{Type: eventType, State: state, Time: timedate.GetTimestamp()}
+	u.eventsMutex.RLock()
+	defer u.eventsMutex.RUnlock()
+	for _, ch := range u.eventListeners {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+func streamState(name string, cfg sconfig.Stream) stream.State {
+	state := stream.State{
+		Stream:  name,
+		Online:  cfg.Working,
+		Codecs:  []string{},
+		Viewers: len(cfg.ClientList),
+	}
+	for _, codec := range cfg.Codecs {
+		state.Codecs = append(state.Codecs, codec.Type().String())
+	}
+	return state
+}
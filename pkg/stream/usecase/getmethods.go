@@ -2,7 +2,6 @@ package usecase
 
 import (
 	"fmt"
-	"os"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
@@ -23,8 +22,16 @@ func (u *StreamUseCase) GetStream(id int) (*stream.Stream, error) {
 	strm.StatusPublic = int(strmg.StatusPublic.Int16)
 	strm.StatusRecord = int(strmg.StatusRecord.Int16)
 	strm.PathStream = strmg.PathStream.String
+	if strm.Camera, err = u.streamRepo.GetCamera(id); err != nil {
+		return nil, err
+	}
 	if (strm.Stream != "") && (strm.StatusPublic != 0) {
-		u.cfg.StreamLink = os.Getenv("RTSP_URL_MAIN") + strm.Stream
+		// Source URLs are stored without credentials
+		if src, err := u.streamRepo.GetSource(id); err == nil {
+			u.cfg.StreamLink = src.URL
+		} else {
+			u.cfg.StreamLink = u.DefaultSourceURL(strm.Stream)
+		}
 		fmt.Println("Got RTSP link")
 	} else {
 		u.cfg.StreamLink = ""
@@ -33,16 +40,44 @@ func (u *StreamUseCase) GetStream(id int) (*stream.Stream, error) {
 	return &strm, nil
 }
 
-func (u *StreamUseCase) GetAllStreams(urlparams *user.Pagin) (map[int]*stream.Stream, error) {
-	streamsg, err := u.streamRepo.GetAllStreams(urlparams)
-	if err != nil {
-		return nil, err
+func (u *StreamUseCase) GetAllStreams(urlparams *user.Pagin, filter *stream.CameraFilter) (map[int]*stream.Stream, error) {
+	var streamsg map[int]*stream.StreamGet
+	var cameras map[int]*stream.Camera
+	var err error
+	if isCameraFilterEmpty(filter) {
+		if streamsg, err = u.streamRepo.GetAllStreams(urlparams); err != nil {
+			return nil, err
+		}
+		ids := []int{}
+		for id := range streamsg {
+			ids = append(ids, id)
+		}
+		if cameras, err = u.streamRepo.GetCameras(ids); err != nil {
+			return nil, err
+		}
+	} else {
+		filtered, err := u.streamRepo.GetFilteredCameras(filter, urlparams)
+		if err != nil {
+			return nil, err
+		}
+		cameras = map[int]*stream.Camera{}
+		ids := []int{}
+		for _, cam := range filtered {
+			cameras[cam.StreamId] = cam
+			ids = append(ids, cam.StreamId)
+		}
+		if streamsg, err = u.streamRepo.GetStreamsByIds(ids); err != nil {
+			return nil, err
+		}
+	}
+	if streamsg == nil {
+		return nil, nil
 	}
 	var streams = map[int]*stream.Stream{}
 	for _, val := range streamsg {
 		streams[val.Id] = &stream.Stream{Id: val.Id, Stream: val.Stream.String,
 			DateTime: val.DateTime.String, StatusPublic: int(val.StatusPublic.Int16),
-			PathStream: val.PathStream.String}
+			PathStream: val.PathStream.String, Camera: cameras[val.Id]}
 	}
 	return streams, nil
 }
@@ -0,0 +1,129 @@
+package usecase
+
+import (
+	"sync/atomic"
+	"time"
+
+	"vhosting/pkg/stream"
+)
+
+const bitrateMeterPeriodSeconds = 1
+
+type viewerSlot struct {
+	stream string
+	owner  stream.ViewerOwner
+}
+
+func (u *StreamUseCase) GetUserGroupIds(userId int) ([]int, error) {
+	return u.streamRepo.GetUserGroupIds(userId)
+}
+
+// AcquireViewerSlot checks the configured limits and reserves a viewer slot
+// for the stream. The slot must be released when the viewer disconnects.
+func (u *StreamUseCase) AcquireViewerSlot(suuid string, owner *stream.ViewerOwner) (string, error) {
+	u.quotaMutex.Lock()
+	defer u.quotaMutex.Unlock()
+
+	streamViewers, userStreams := 0, 0
+	groupStreams := map[int]int{}
+	for _, slot := range u.viewerSlots {
+		if slot.stream == suuid {
+			streamViewers++
+		}
+		if isSameOwner(&slot.owner, owner) {
+			userStreams++
+		}
+		for _, id := range slot.owner.GroupIds {
+			groupStreams[id]++
+		}
+	}
+
+	if limit := u.cfg.QuotaMaxViewersPerStream; limit > 0 && streamViewers >= limit {
+		return "", stream.ErrViewersPerStreamLimit
+	}
+	if limit := u.cfg.QuotaMaxStreamsPerUser; limit > 0 && userStreams >= limit {
+		return "", stream.ErrStreamsPerUserLimit
+	}
+	if limit := u.cfg.QuotaMaxStreamsPerGroup; limit > 0 {
+		for _, id := range owner.GroupIds {
+			if groupStreams[id] >= limit {
+				return "", stream.ErrStreamsPerGroupLimit
+			}
+		}
+	}
+	if budget := u.cfg.QuotaOutboundBitrateBudgetKbps; budget > 0 {
+		// A new viewer is expected to take about the average bitrate
+		// of the current ones
+		current := int(atomic.LoadInt64(&u.outboundKbps))
+		projected := current
+		if len(u.viewerSlots) > 0 {
+			projected += current / len(u.viewerSlots)
+		}
+		if current >= budget || projected > budget {
+			return "", stream.ErrOutboundBitrateExceeded
+		}
+	}
+
+	slotId := pseudoUUID()
+	u.viewerSlots[slotId] = viewerSlot{stream: suuid, owner: *owner}
+	return slotId, nil
+}
+
+func (u *StreamUseCase) ReleaseViewerSlot(slotId string) {
+	u.quotaMutex.Lock()
+	defer u.quotaMutex.Unlock()
+	delete(u.viewerSlots, slotId)
+}
+
+func (u *StreamUseCase) GetUsage() *stream.Usage {
+	u.quotaMutex.Lock()
+	defer u.quotaMutex.Unlock()
+
+	usage := stream.Usage{
+		Limits: stream.Limits{
+			MaxViewersPerStream:       u.cfg.QuotaMaxViewersPerStream,
+			MaxStreamsPerUser:         u.cfg.QuotaMaxStreamsPerUser,
+			MaxStreamsPerGroup:        u.cfg.QuotaMaxStreamsPerGroup,
+			OutboundBitrateBudgetKbps: u.cfg.QuotaOutboundBitrateBudgetKbps,
+		},
+		Viewers:      len(u.viewerSlots),
+		OutboundKbps: int(atomic.LoadInt64(&u.outboundKbps)),
+		Streams:      map[string]int{},
+		Users:        map[int]int{},
+		Guests:       map[string]int{},
+		Groups:       map[int]int{},
+	}
+	for _, slot := range u.viewerSlots {
+		usage.Streams[slot.stream]++
+		if slot.owner.UserId < 0 {
+			usage.Guests[slot.owner.ClientIP]++
+		} else {
+			usage.Users[slot.owner.UserId]++
+		}
+		for _, id := range slot.owner.GroupIds {
+			usage.Groups[id]++
+		}
+	}
+
+	return &usage
+}
+
+// measureOutboundBitrate turns the bytes written to WebRTC viewers into
+// the outbound bitrate of the last period.
+func (u *StreamUseCase) measureOutboundBitrate() {
+	ticker := time.NewTicker(bitrateMeterPeriodSeconds * time.Second)
+	defer ticker.Stop()
+	for range ticker.C {
+		bytes := atomic.SwapUint64(&u.outboundBytes, 0)
+		kbps := int64(bytes * 8 / 1000 / bitrateMeterPeriodSeconds)
+		atomic.StoreInt64(&u.outboundKbps, kbps)
+	}
+}
+
+// isSameOwner matches authenticated viewers by user ID and the rest by IP.
+func isSameOwner(a, b *stream.ViewerOwner) bool {
+	if a.UserId >= 0 || b.UserId >= 0 {
+		return a.UserId == b.UserId
+	}
+	return a.ClientIP == b.ClientIP
+}
@@ -0,0 +1,178 @@
+package usecase
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	msg "vhosting/internal/messages"
+	sconfig "vhosting/pkg/config_stream"
+	"vhosting/pkg/logger"
+	"vhosting/pkg/stream"
+)
+
+// ReloadConfig reads the stream config file and applies it to the running
+// workers: new streams are started, removed ones are stopped and streams
+// with changed settings are restarted.
+func (u *StreamUseCase) ReloadConfig() error {
+	fileCfg, err := sconfig.LoadConfig(u.cfg.StreamConfigFile)
+	if os.IsNotExist(err) {
+		logger.Printc(nil, msg.InfoStreamConfigFileNotFound(u.cfg.StreamConfigFile))
+		fileCfg = &sconfig.Config{Streams: map[string]sconfig.Stream{}}
+	} else if err != nil {
+		return err
+	} else {
+		u.applyServerConfig(&fileCfg.Server)
+		logger.Printc(nil, msg.InfoStreamConfigLoaded(u.cfg.StreamConfigFile))
+	}
+
+	u.fileMutex.Lock()
+	defer u.fileMutex.Unlock()
+
+	for name := range u.fileStreams {
+		if _, ok := fileCfg.Streams[name]; !ok {
+			u.stopStream(name)
+			logger.Printc(nil, msg.InfoStreamRemoved(name))
+		}
+	}
+	for name, cfg := range fileCfg.Streams {
+		old, ok := u.fileStreams[name]
+		if ok && isSameStreamSettings(&old, &cfg) {
+			continue
+		}
+		u.startStream(name, cfg)
+		if ok {
+			logger.Printc(nil, msg.InfoStreamChanged(name))
+		} else {
+			logger.Printc(nil, msg.InfoStreamAdded(name))
+		}
+	}
+	u.fileStreams = fileCfg.Streams
+
+	return nil
+}
+
+// watchConfig reloads the stream config file on SIGHUP
+// or when its modification time changes.
+func (u *StreamUseCase) watchConfig() {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+
+	ticker := time.NewTicker(time.Duration(u.cfg.StreamConfigCheckPeriodSeconds) * time.Second)
+	defer ticker.Stop()
+
+	modTime := configModTime(u.cfg.StreamConfigFile)
+	for {
+		select {
+		case sig := <-hangup:
+			logger.Printc(nil, msg.InfoRecivedSignal(sig))
+		case <-ticker.C:
+			if current := configModTime(u.cfg.StreamConfigFile); current.Equal(modTime) {
+				continue
+			}
+		}
+		modTime = configModTime(u.cfg.StreamConfigFile)
+		if err := u.ReloadConfig(); err != nil {
+			logger.Printc(nil, msg.ErrorCannotLoadStreamConfig(err))
+		}
+	}
+}
+
+// startStream registers the stream and starts its worker unless the stream
+// is on demand. A running stream with the same name is replaced, its viewers
+// are kept.
+func (u *StreamUseCase) startStream(name string, cfg sconfig.Stream) {
+	if cfg.Transport == "" {
+		cfg.Transport = stream.TransportTCP
+	}
+	if cfg.Transport == stream.TransportUDP {
+		logger.Printc(nil, msg.WarningStreamUDPTransportIsNotSupported(name))
+	}
+	cfg.ClientList = make(map[string]sconfig.Viewer)
+	cfg.Stop = make(chan struct{})
+	cfg.Codecs = nil
+	cfg.RunLock = false
+	cfg.Working = false
+
+	u.scfg.StreamsMutex.Lock()
+	if old, ok := u.scfg.Streams[name]; ok {
+		if old.Stop != nil {
+			close(old.Stop)
+		}
+		if !old.OnDemand {
+			u.scfg.StreamsCount--
+		}
+		cfg.ClientList = old.ClientList
+	}
+	u.scfg.Streams[name] = cfg
+	if !cfg.OnDemand {
+		u.scfg.StreamsCount++
+	}
+	u.scfg.StreamsMutex.Unlock()
+
+	if !cfg.OnDemand {
+		go u.rtspWorkerLoop(name, cfg.URL, cfg.OnDemand, cfg.DisableAudio, cfg.Debug)
+	}
+}
+
+func (u *StreamUseCase) stopStream(name string) {
+	u.scfg.StreamsMutex.Lock()
+	cfg, ok := u.scfg.Streams[name]
+	if ok {
+		if cfg.Stop != nil {
+			close(cfg.Stop)
+		}
+		if !cfg.OnDemand {
+			u.scfg.StreamsCount--
+		}
+		delete(u.scfg.Streams, name)
+	}
+	u.scfg.StreamsMutex.Unlock()
+
+	if ok {
+		u.publishEvent(stream.EventOffline, stream.State{Stream: name})
+	}
+}
+
+func (u *StreamUseCase) applyServerConfig(srv *sconfig.Server) {
+	u.scfg.Server.ICEUsernameMutex.Lock()
+	u.scfg.Server.ICEUsername = srv.ICEUsername
+	u.scfg.Server.ICEUsernameMutex.Unlock()
+
+	u.scfg.Server.ICECredentialMutex.Lock()
+	u.scfg.Server.ICECredential = srv.ICECredential
+	u.scfg.Server.ICECredentialMutex.Unlock()
+
+	u.scfg.Server.WebRTCPortMinMutex.Lock()
+	u.scfg.Server.WebRTCPortMin = srv.WebRTCPortMin
+	u.scfg.Server.WebRTCPortMinMutex.Unlock()
+
+	u.scfg.Server.WebRTCPortMaxMutex.Lock()
+	u.scfg.Server.WebRTCPortMax = srv.WebRTCPortMax
+	u.scfg.Server.WebRTCPortMaxMutex.Unlock()
+}
+
+func (u *StreamUseCase) getFileStreams() map[string]sconfig.Stream {
+	u.fileMutex.RLock()
+	defer u.fileMutex.RUnlock()
+	streams := make(map[string]sconfig.Stream, len(u.fileStreams))
+	for name, cfg := range u.fileStreams {
+		streams[name] = cfg
+	}
+	return streams
+}
+
+func isSameStreamSettings(a, b *sconfig.Stream) bool {
+	return a.URL == b.URL && a.Transport == b.Transport &&
+		a.Status == b.Status && a.OnDemand == b.OnDemand &&
+		a.DisableAudio == b.DisableAudio && a.Debug == b.Debug
+}
+
+func configModTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
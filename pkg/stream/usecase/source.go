@@ -0,0 +1,201 @@
+package usecase
+
+import (
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	msg "vhosting/internal/messages"
+	"vhosting/pkg/hasher"
+	"vhosting/pkg/logger"
+	"vhosting/pkg/stream"
+)
+
+const redactedText = "***"
+
+// SetSource moves credentials found in the URL into the source fields,
+// encrypts them and stores the source. A running stream picks up
+// the new URL on its next reconnection.
+func (u *StreamUseCase) SetSource(src *stream.Source) error {
+	srcURL, err := url.Parse(src.URL)
+	if err != nil {
+		return err
+	}
+	if srcURL.User != nil {
+		if src.Username == "" {
+			src.Username = srcURL.User.Username()
+		}
+		if password, ok := srcURL.User.Password(); ok && src.Password == "" {
+			src.Password = password
+		}
+		srcURL.User = nil
+	}
+	if src.Transport == "" {
+		src.Transport = stream.TransportTCP
+	}
+
+	stored := stream.Source{StreamId: src.StreamId, URL: srcURL.String(),
+		Transport: strings.ToLower(src.Transport)}
+	if stored.Username, err = hasher.EncryptString(src.Username, u.cfg.HashingCredentialsKey); err != nil {
+		return err
+	}
+	if stored.Password, err = hasher.EncryptString(src.Password, u.cfg.HashingCredentialsKey); err != nil {
+		return err
+	}
+	if err := u.streamRepo.SetSource(&stored); err != nil {
+		return err
+	}
+
+	strmg, err := u.streamRepo.GetStream(src.StreamId)
+	if err != nil {
+		return err
+	}
+	src.URL = stored.URL
+	src.Transport = stored.Transport
+	u.applySource(strmg.Stream.String, src)
+
+	return nil
+}
+
+func (u *StreamUseCase) GetSource(streamId int) (*stream.SourceView, error) {
+	src, err := u.streamRepo.GetSource(streamId)
+	if err != nil {
+		return nil, err
+	}
+	return &stream.SourceView{
+		StreamId:       src.StreamId,
+		URL:            src.URL,
+		Transport:      src.Transport,
+		HasCredentials: src.Username != "" || src.Password != "",
+	}, nil
+}
+
+func (u *StreamUseCase) DeleteSource(streamId int) error {
+	if err := u.streamRepo.DeleteSource(streamId); err != nil {
+		return err
+	}
+
+	strmg, err := u.streamRepo.GetStream(streamId)
+	if err != nil {
+		return err
+	}
+	u.applySource(strmg.Stream.String, nil)
+
+	return nil
+}
+
+func (u *StreamUseCase) IsSourceExists(streamId int) (bool, error) {
+	return u.streamRepo.IsSourceExists(streamId)
+}
+
+func (u *StreamUseCase) IsSourceURLValid(rawURL string) bool {
+	srcURL, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	return (srcURL.Scheme == "rtsp" || srcURL.Scheme == "rtsps") && srcURL.Host != ""
+}
+
+// IsSourceTransportValid accepts TCP only, the RTSP client cannot read
+// streams over UDP.
+func (u *StreamUseCase) IsSourceTransportValid(transport string) bool {
+	switch strings.ToLower(transport) {
+	case "", stream.TransportTCP:
+		return true
+	}
+	return false
+}
+
+// DefaultSourceURL is used for streams without their own source.
+func (u *StreamUseCase) DefaultSourceURL(name string) string {
+	return os.Getenv("RTSP_URL_MAIN") + name
+}
+
+func (u *StreamUseCase) BindJSONSource(ctx *gin.Context) (*stream.Source, error) {
+	var src stream.Source
+	if err := ctx.BindJSON(&src); err != nil {
+		return &src, err
+	}
+	return &src, nil
+}
+
+// getSourcesByName returns decrypted sources keyed by stream name. The
+// sources which cannot be decrypted are logged and left out, their streams
+// use the default source.
+func (u *StreamUseCase) getSourcesByName() (map[string]*stream.Source, error) {
+	sources, err := u.streamRepo.GetAllSources()
+	if err != nil {
+		return nil, err
+	}
+
+	ids := []int{}
+	for id := range sources {
+		ids = append(ids, id)
+	}
+	streams, err := u.streamRepo.GetStreamsByIds(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	var byName = map[string]*stream.Source{}
+	for id, strm := range streams {
+		src := sources[id]
+		if src.Username, err = hasher.DecryptString(src.Username, u.cfg.HashingCredentialsKey); err != nil {
+			logger.Printc(nil, msg.ErrorCannotDecryptStreamSource(strm.Stream.String, err))
+			continue
+		}
+		if src.Password, err = hasher.DecryptString(src.Password, u.cfg.HashingCredentialsKey); err != nil {
+			logger.Printc(nil, msg.ErrorCannotDecryptStreamSource(strm.Stream.String, err))
+			continue
+		}
+		byName[strm.Stream.String] = src
+	}
+
+	return byName, nil
+}
+
+// sourceURL builds the full URL with credentials to connect the stream.
+func (u *StreamUseCase) sourceURL(name string, src *stream.Source) string {
+	if src == nil {
+		return u.DefaultSourceURL(name)
+	}
+	srcURL, err := url.Parse(src.URL)
+	if err != nil {
+		return src.URL
+	}
+	if src.Password != "" {
+		srcURL.User = url.UserPassword(src.Username, src.Password)
+	} else if src.Username != "" {
+		srcURL.User = url.User(src.Username)
+	}
+	return srcURL.String()
+}
+
+func (u *StreamUseCase) applySource(name string, src *stream.Source) {
+	u.scfg.StreamsMutex.Lock()
+	defer u.scfg.StreamsMutex.Unlock()
+	cfg, ok := u.scfg.Streams[name]
+	if !ok {
+		return
+	}
+	cfg.URL = u.sourceURL(name, src)
+	cfg.Transport = stream.TransportTCP
+	if src != nil {
+		cfg.Transport = src.Transport
+	}
+	u.scfg.Streams[name] = cfg
+}
+
+// redactCredentials hides the credentials of the source URL in the text.
+func redactCredentials(text, rawURL string) string {
+	srcURL, err := url.Parse(rawURL)
+	if err != nil || srcURL.User == nil {
+		return text
+	}
+	text = strings.ReplaceAll(text, srcURL.User.String(), redactedText)
+	if password, ok := srcURL.User.Password(); ok && password != "" {
+		text = strings.ReplaceAll(text, password, redactedText)
+	}
+	return text
+}
@@ -0,0 +1,220 @@
+package usecase
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"vhosting/pkg/stream"
+)
+
+// GetTimeline builds the recorded ranges and gaps of the stream from the
+// segment start times. A segment lasts until the next one starts, but not
+// longer than the segment length, a later start is a gap in the recording.
+func (u *StreamUseCase) GetTimeline(streamId int, from, to time.Time, days bool) (*stream.Timeline, error) {
+	strmg, err := u.streamRepo.GetStream(streamId)
+	if err != nil {
+		return nil, err
+	}
+
+	starts := []time.Time{}
+	if pathStream := strmg.PathStream.String; pathStream != "" {
+		if starts, err = u.streamRepo.GetRecordTimes(pathStream, from, to); err != nil {
+			return nil, err
+		}
+	}
+
+	segment := time.Duration(u.cfg.StreamTimelineSegmentSeconds) * time.Second
+	tolerance := time.Duration(u.cfg.StreamTimelineGapToleranceSeconds) * time.Second
+
+	timeline, spans := buildTimeline(starts, from, to, segment, tolerance)
+	timeline.StreamId = streamId
+	if days {
+		timeline.Days = timelineDays(spans, from, to)
+	}
+
+	return timeline, nil
+}
+
+// ParseTimelinePeriod reads "from" and "to" of the URL in "2006-01-02 15:04:05"
+// local time or RFC3339. Without "to" the period ends now, without "from" it
+// starts a day before its end. The future part of the period is cut off.
+func (u *StreamUseCase) ParseTimelinePeriod(ctx *gin.Context) (time.Time, time.Time, bool) {
+	urlparams := ctx.Request.URL.Query()
+	now := time.Now()
+
+	to := now
+	if val := urlparams.Get("to"); val != "" {
+		var ok bool
+		if to, ok = parseTimelineDatetime(val); !ok {
+			return time.Time{}, time.Time{}, false
+		}
+		if to.After(now) {
+			to = now
+		}
+	}
+
+	from := to.AddDate(0, 0, -1)
+	if val := urlparams.Get("from"); val != "" {
+		var ok bool
+		if from, ok = parseTimelineDatetime(val); !ok {
+			return time.Time{}, time.Time{}, false
+		}
+	}
+
+	if !to.After(from) {
+		return time.Time{}, time.Time{}, false
+	}
+	return from, to, true
+}
+
+func (u *StreamUseCase) IsTimelinePeriodTooLong(from, to time.Time) bool {
+	return to.After(from.AddDate(0, 0, u.cfg.StreamTimelineMaxDays))
+}
+
+func (u *StreamUseCase) IsTimelineDaysRequested(ctx *gin.Context) bool {
+	days, _ := strconv.ParseBool(ctx.Request.URL.Query().Get("days"))
+	return days
+}
+
+// timelineSpan is a continuous recording within the period.
+type timelineSpan struct {
+	from, to time.Time
+	segments []*stream.TimelineSegment
+}
+
+func buildTimeline(starts []time.Time, from, to time.Time, segment, tolerance time.Duration) (*stream.Timeline, []*timelineSpan) {
+	timeline := &stream.Timeline{
+		From:   from.Format(stream.TimelineDatetimeLayout),
+		To:     to.Format(stream.TimelineDatetimeLayout),
+		Ranges: []*stream.TimelineRange{},
+		Gaps:   []*stream.TimelineRange{},
+	}
+
+	spans := []*timelineSpan{}
+
+	for i, start := range starts {
+		end := start.Add(segment)
+		if i+1 < len(starts) && !starts[i+1].After(end.Add(tolerance)) {
+			end = starts[i+1]
+		}
+
+		// Clip the segment to the period
+		segFrom, segTo := start, end
+		if segFrom.Before(from) {
+			segFrom = from
+		}
+		if segTo.After(to) {
+			segTo = to
+		}
+		if !segTo.After(segFrom) {
+			continue
+		}
+
+		seg := &stream.TimelineSegment{
+			Start:    segFrom.Format(stream.TimelineDatetimeLayout),
+			Duration: seconds(segTo.Sub(segFrom)),
+		}
+		if n := len(spans); n > 0 && !segFrom.After(spans[n-1].to) {
+			spans[n-1].to = segTo
+			spans[n-1].segments = append(spans[n-1].segments, seg)
+			continue
+		}
+		spans = append(spans, &timelineSpan{from: segFrom, to: segTo,
+			segments: []*stream.TimelineSegment{seg}})
+	}
+
+	gapFrom := from
+	var recorded time.Duration
+	for _, s := range spans {
+		if s.from.After(gapFrom) {
+			timeline.Gaps = append(timeline.Gaps, timelineRange(gapFrom, s.from))
+		}
+		rng := timelineRange(s.from, s.to)
+		rng.Segments = s.segments
+		timeline.Ranges = append(timeline.Ranges, rng)
+		recorded += s.to.Sub(s.from)
+		gapFrom = s.to
+	}
+	if to.After(gapFrom) {
+		timeline.Gaps = append(timeline.Gaps, timelineRange(gapFrom, to))
+	}
+
+	timeline.Recorded = seconds(recorded)
+	timeline.Coverage = percent(recorded, to.Sub(from))
+
+	return timeline, spans
+}
+
+// timelineDays sums the recorded time of every local calendar day of the
+// period, the first and the last days are counted within the period only.
+func timelineDays(spans []*timelineSpan, from, to time.Time) []*stream.TimelineDay {
+	days := []*stream.TimelineDay{}
+
+	dayStart := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.Local)
+	for dayStart.Before(to) {
+		dayEnd := dayStart.AddDate(0, 0, 1)
+
+		winFrom, winTo := dayStart, dayEnd
+		if winFrom.Before(from) {
+			winFrom = from
+		}
+		if winTo.After(to) {
+			winTo = to
+		}
+
+		var recorded time.Duration
+		for _, s := range spans {
+			spanFrom, spanTo := s.from, s.to
+			if spanFrom.Before(winFrom) {
+				spanFrom = winFrom
+			}
+			if spanTo.After(winTo) {
+				spanTo = winTo
+			}
+			if spanTo.After(spanFrom) {
+				recorded += spanTo.Sub(spanFrom)
+			}
+		}
+
+		days = append(days, &stream.TimelineDay{
+			Date:     dayStart.Format(stream.TimelineDateLayout),
+			Recorded: seconds(recorded),
+			Coverage: percent(recorded, winTo.Sub(winFrom)),
+		})
+		dayStart = dayEnd
+	}
+
+	return days
+}
+
+func timelineRange(from, to time.Time) *stream.TimelineRange {
+	return &stream.TimelineRange{
+		From:     from.Format(stream.TimelineDatetimeLayout),
+		To:       to.Format(stream.TimelineDatetimeLayout),
+		Duration: seconds(to.Sub(from)),
+	}
+}
+
+func parseTimelineDatetime(value string) (time.Time, bool) {
+	if t, err := time.ParseInLocation(stream.TimelineDatetimeLayout, value, time.Local); err == nil {
+		return t, true
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.In(time.Local), true
+	}
+	return time.Time{}, false
+}
+
+// seconds rounds the duration to milliseconds.
+func seconds(d time.Duration) float64 {
+	return math.Round(d.Seconds()*1000) / 1000
+}
+
+func percent(part, whole time.Duration) float64 {
+	if whole <= 0 {
+		return 0
+	}
+	return math.Round(float64(part)/float64(whole)*10000) / 100
+}
@@ -1,11 +1,14 @@
 package usecase
 
 import (
+	"crypto/rand"
 	"errors"
 	"fmt"
 
 	"image/jpeg"
 	"os"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/deepch/vdk/av"
@@ -21,6 +24,8 @@ import (
 	"vhosting/pkg/stream"
 )
 
+var errStreamStopped = errors.New("stream exit - worker stopped")
+
 const (
 	errorStreamExitNoViewer = "Stream was exited on demand - no Viewer"
 	snapshotPath            = "./media/%s/images"
@@ -29,22 +34,42 @@ const (
 )
 
 type StreamUseCase struct {
-	cfg        *config.Config
-	scfg       *sconfig.Config
-	streamRepo stream.StreamRepository
+	outboundBytes  uint64 // accessed atomically, kept first for alignment
+	outboundKbps   int64  // accessed atomically
+	cfg            *config.Config
+	scfg           *sconfig.Config
+	streamRepo     stream.StreamRepository
+	eventsMutex    sync.RWMutex
+	eventListeners map[string]chan stream.Event
+	eventsTickets  map[string]eventsTicket
+	quotaMutex     sync.Mutex
+	viewerSlots    map[string]viewerSlot
+	fileMutex      sync.RWMutex
+	fileStreams    map[string]sconfig.Stream
 }
 
 func NewStreamUseCase(cfg *config.Config, scfg *sconfig.Config, streamRepo stream.StreamRepository) *StreamUseCase {
 	return &StreamUseCase{
-		cfg:        cfg,
-		scfg:       scfg,
-		streamRepo: streamRepo,
+		cfg:            cfg,
+		scfg:           scfg,
+		streamRepo:     streamRepo,
+		eventListeners: map[string]chan stream.Event{},
+		eventsTickets:  map[string]eventsTicket{},
+		viewerSlots:    map[string]viewerSlot{},
+		fileStreams:    map[string]sconfig.Stream{},
 	}
 }
 
 func (u *StreamUseCase) ServeStreams() {
 	u.scfg.Streams = map[string]sconfig.Stream{}
 
+	if err := u.ReloadConfig(); err != nil {
+		logger.Printc(nil, msg.ErrorCannotLoadStreamConfig(err))
+	}
+	go u.watchConfig()
+
+	go u.measureOutboundBitrate()
+
 	go func() {
 		for {
 			u.startDefaultGetWorkingStreamsCycle()
@@ -66,37 +91,84 @@ func (u *StreamUseCase) startDefaultGetWorkingStreamsCycle() {
 			logger.Printc(nil, msg.ErrorCannotGetAllWorkingStreams(err))
 			return
 		}
+		// The sources are read again on the next tick, the cycle goes on
+		sources, err := u.getSourcesByName()
+		if err != nil {
+			logger.Printc(nil, msg.ErrorCannotGetStreamSources(err))
+			time.Sleep(time.Duration(u.cfg.StreamStreamsUpdatePeriodSeconds) * time.Second)
+			continue
+		}
 		for _, url := range *workingStreams {
-			if _, found := u.scfg.Streams[url]; !found {
-				u.scfg.Streams[url] = sconfig.Stream{ClientList: make(map[string]sconfig.Viewer), URL: url}
-				if !u.scfg.Streams[url].OnDemand {
-					go u.rtspWorkerLoop(url, u.scfg.Streams[url].URL, u.scfg.Streams[url].OnDemand, u.scfg.Streams[url].DisableAudio, u.scfg.Streams[url].Debug)
-					u.scfg.StreamsCount++
+			if !u.Exit(url) {
+				transport := stream.TransportTCP
+				if src, ok := sources[url]; ok {
+					transport = src.Transport
 				}
+				u.startStream(url, sconfig.Stream{URL: u.sourceURL(url, sources[url]), Transport: transport})
+			}
+		}
+		// Streams of the config file are restarted after being dropped as well
+		for name, cfg := range u.getFileStreams() {
+			if !u.Exit(name) {
+				u.startStream(name, cfg)
 			}
 		}
 		go func() {
 			time.Sleep(200 * time.Millisecond)
-			logger.Printc(nil, &logger.Log{Message: fmt.Sprintf("Working streams: %d", u.scfg.StreamsCount)})
+			u.scfg.StreamsMutex.RLock()
+			count := u.scfg.StreamsCount
+			u.scfg.StreamsMutex.RUnlock()
+			logger.Printc(nil, &logger.Log{Message: fmt.Sprintf("Working streams: %d", count)})
 		}()
 		time.Sleep(time.Duration(u.cfg.StreamStreamsUpdatePeriodSeconds) * time.Second)
 	}
 }
 
 func (u *StreamUseCase) rtspWorkerLoop(name, url string, onDemand, disableAudio, debug bool) {
+	u.scfg.StreamsMutex.RLock()
+	stop := u.scfg.Streams[name].Stop
+	u.scfg.StreamsMutex.RUnlock()
+
 	defer func() {
-		if _, found := u.scfg.Streams[name]; found {
+		// A stopped worker leaves the stream to the one which replaced it
+		u.scfg.StreamsMutex.Lock()
+		cfg, found := u.scfg.Streams[name]
+		dropped := found && cfg.Stop == stop
+		if dropped {
 			delete(u.scfg.Streams, name)
-			u.scfg.StreamDropped = true
 			u.scfg.StreamsCount--
+		}
+		u.scfg.StreamsMutex.Unlock()
+		if dropped {
+			u.scfg.StreamDropped = true
 			logger.Printc(nil, &logger.Log{Message: "Stream dropped. Stream: " + name})
+			u.publishEvent(stream.EventOffline, stream.State{Stream: name})
 		}
 	}()
-	defer u.runUnlock(name)
+	defer u.runUnlock(name, stop)
 	for {
+		select {
+		case <-stop:
+			logger.Printc(nil, msg.InfoStreamWorkerStopped(name))
+			return
+		default:
+		}
+
+		// The source may be changed while the stream is reconnecting
+		u.scfg.StreamsMutex.RLock()
+		if cfg, ok := u.scfg.Streams[name]; ok {
+			url = cfg.URL
+		}
+		u.scfg.StreamsMutex.RUnlock()
+
 		logger.Printc(nil, msg.InfoStreamTriesToConnect(name))
-		err := u.rtspWorker(name, url, onDemand, disableAudio, debug)
+		err := u.rtspWorker(name, url, stop, onDemand, disableAudio, debug)
+		if err == errStreamStopped {
+			logger.Printc(nil, msg.InfoStreamWorkerStopped(name))
+			return
+		}
 		if err != nil {
+			err = errors.New(redactCredentials(err.Error(), url))
 			u.scfg.LastError = err
 			logger.Printc(nil, msg.ErrorRTSPWorkerError(err))
 			return
@@ -109,13 +181,13 @@ func (u *StreamUseCase) rtspWorkerLoop(name, url string, onDemand, disableAudio,
 	}
 }
 
-func (u *StreamUseCase) rtspWorker(name, url string, onDemand, disableAudio, debug bool) error {
+func (u *StreamUseCase) rtspWorker(name, url string, stop chan struct{}, onDemand, disableAudio, debug bool) error {
 	keyTest := time.NewTimer(20 * time.Second)
 	clientTest := time.NewTimer(20 * time.Second)
 
 	// add next timeout
 	newRTSPClient := rtspv2.RTSPClientOptions{
-		URL:              os.Getenv("RTSP_URL_MAIN") + url,
+		URL:              url,
 		DisableAudio:     disableAudio,
 		DialTimeout:      3 * time.Second,
 		ReadWriteTimeout: 3 * time.Second,
@@ -166,6 +238,8 @@ func (u *StreamUseCase) rtspWorker(name, url string, onDemand, disableAudio, deb
 
 	for {
 		select {
+		case <-stop:
+			return errStreamStopped
 		case <-clientTest.C:
 			if onDemand {
 				if !u.isHasViewer(name) {
@@ -221,10 +295,18 @@ func IsPathExists(path string) bool {
 
 func (u *StreamUseCase) codecAdd(suuid string, codecs []av.CodecData) {
 	u.scfg.StreamsMutex.Lock()
-	defer u.scfg.StreamsMutex.Unlock()
 	t := u.scfg.Streams[suuid]
+	eventType := stream.EventCodec
+	if !t.Working {
+		eventType = stream.EventOnline
+	}
 	t.Codecs = codecs
+	t.Working = true
 	u.scfg.Streams[suuid] = t
+	state := streamState(suuid, t)
+	u.scfg.StreamsMutex.Unlock()
+
+	u.publishEvent(eventType, state)
 }
 
 func (u *StreamUseCase) isHasViewer(uuid string) bool {
@@ -246,11 +328,11 @@ func (u *StreamUseCase) cast(uuid string, pck av.Packet) {
 	}
 }
 
-func (u *StreamUseCase) runUnlock(uuid string) {
+func (u *StreamUseCase) runUnlock(uuid string, stop chan struct{}) {
 	u.scfg.StreamsMutex.Lock()
 	defer u.scfg.StreamsMutex.Unlock()
 	cfg, ok := u.scfg.Streams[uuid]
-	if !ok {
+	if !ok || cfg.Stop != stop {
 		return
 	}
 	if cfg.OnDemand && cfg.RunLock {
@@ -266,6 +348,21 @@ func (u *StreamUseCase) Exit(suuid string) bool {
 	return ok
 }
 
+// AddOnDemandStream adds the stream read from the default source when it
+// is requested, the existing stream is kept.
+func (u *StreamUseCase) AddOnDemandStream(suuid string) {
+	u.scfg.StreamsMutex.Lock()
+	defer u.scfg.StreamsMutex.Unlock()
+	if _, ok := u.scfg.Streams[suuid]; ok {
+		return
+	}
+	u.scfg.Streams[suuid] = sconfig.Stream{
+		URL:        u.DefaultSourceURL(suuid),
+		OnDemand:   true,
+		ClientList: make(map[string]sconfig.Viewer),
+	}
+}
+
 func (u *StreamUseCase) RunIfNotRun(uuid string) {
 	u.scfg.StreamsMutex.Lock()
 	defer u.scfg.StreamsMutex.Unlock()
@@ -338,17 +435,20 @@ func (u *StreamUseCase) GetWebRTCPortMax() uint16 {
 	return u.scfg.Server.WebRTCPortMax
 }
 
-func (u *StreamUseCase) WritePackets(url string, muxerWebRTC *webrtc.Muxer, audioOnly bool) {
+// WritePackets sends the stream to the viewer until it disconnects and
+// returns the bytes sent.
+func (u *StreamUseCase) WritePackets(url string, muxerWebRTC *webrtc.Muxer, audioOnly bool) int64 {
 	cid, ch := u.CastListAdd(url)
 	defer u.CastListDelete(url, cid)
 	defer muxerWebRTC.Close()
 	videoStart := false
+	var sent int64
 	noVideo := time.NewTimer(videoTimeoutSeconds * time.Second)
 	for {
 		select {
 		case <-noVideo.C:
 			logger.Printc(nil, msg.InfoNoVideo())
-			return
+			return sent
 		case pck := <-ch:
 			if pck.IsKeyFrame || audioOnly {
 				noVideo.Reset(videoTimeoutSeconds * time.Second)
@@ -360,24 +460,38 @@ func (u *StreamUseCase) WritePackets(url string, muxerWebRTC *webrtc.Muxer, audi
 			err := muxerWebRTC.WritePacket(pck)
 			if err != nil {
 				logger.Printc(nil, msg.ErrorWritePacketError(err))
-				return
+				return sent
 			}
+			atomic.AddUint64(&u.outboundBytes, uint64(len(pck.Data)))
+			sent += int64(len(pck.Data))
 		}
 	}
 }
 
 func (u *StreamUseCase) CastListAdd(suuid string) (string, chan av.Packet) {
 	u.scfg.StreamsMutex.Lock()
-	defer u.scfg.StreamsMutex.Unlock()
+	cuuid := pseudoUUID()
 	ch := make(chan av.Packet, 100)
-	u.scfg.Streams[suuid].ClientList[suuid] = sconfig.Viewer{Cast: ch}
-	return suuid, ch
+	u.scfg.Streams[suuid].ClientList[cuuid] = sconfig.Viewer{Cast: ch}
+	state := streamState(suuid, u.scfg.Streams[suuid])
+	u.scfg.StreamsMutex.Unlock()
+
+	u.publishEvent(stream.EventViewers, state)
+	return cuuid, ch
 }
 
 func (u *StreamUseCase) CastListDelete(suuid, cuuid string) {
 	u.scfg.StreamsMutex.Lock()
-	defer u.scfg.StreamsMutex.Unlock()
-	delete(u.scfg.Streams[suuid].ClientList, cuuid)
+	cfg, ok := u.scfg.Streams[suuid]
+	if !ok {
+		u.scfg.StreamsMutex.Unlock()
+		return
+	}
+	delete(cfg.ClientList, cuuid)
+	state := streamState(suuid, cfg)
+	u.scfg.StreamsMutex.Unlock()
+
+	u.publishEvent(stream.EventViewers, state)
 }
 
 func (u *StreamUseCase) List() (string, []string) {
@@ -393,3 +507,12 @@ func (u *StreamUseCase) List() (string, []string) {
 	}
 	return first, res
 }
+
+func pseudoUUID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		logger.Printc(nil, msg.ErrorPseudoUUIDReadError(err))
+		return fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+	return fmt.Sprintf("%X-%X-%X-%X-%X", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"strconv"
+
 	"github.com/gin-gonic/gin"
 	msg "vhosting/internal/messages"
 	sess "vhosting/internal/session"
@@ -172,6 +174,17 @@ func (h *UserHandler) UpdateUserPassword(ctx *gin.Context) {
 		return
 	}
 
+	userId, err := h.useCase.GetUserId(inputNamepass.Username)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotCheckUserExistence(err))
+		return
+	}
+
+	if err := h.sessUseCase.RevokeUserSessions(userId, h.authUseCase.ReadHeader(ctx)); err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotRevokeSessions(err))
+		return
+	}
+
 	h.logUseCase.Report(ctx, log, msg.InfoUserPasswordChanged())
 }
 
@@ -216,6 +229,14 @@ func (h *UserHandler) PartiallyUpdateUser(ctx *gin.Context) {
 		return
 	}
 
+	// A new password signs the user out everywhere except this session.
+	if inputUser.PasswordHash != "" {
+		if err := h.sessUseCase.RevokeUserSessions(reqId, h.authUseCase.ReadHeader(ctx)); err != nil {
+			h.logUseCase.Report(ctx, log, msg.ErrorCannotRevokeSessions(err))
+			return
+		}
+	}
+
 	h.logUseCase.Report(ctx, log, msg.InfoUserPartiallyUpdated())
 }
 
@@ -254,6 +275,111 @@ func (h *UserHandler) DeleteUser(ctx *gin.Context) {
 	h.logUseCase.Report(ctx, log, msg.InfoUserDeleted())
 }
 
+func (h *UserHandler) GetUserSessions(ctx *gin.Context) {
+	actPermission := "get_user_sessions"
+
+	log := logger.Init(ctx)
+
+	hasPerms, _ := h.isPermsGranted_getUserId(ctx, log, actPermission)
+	if !hasPerms {
+		return
+	}
+
+	reqId, ok := h.getRequestedUserId(ctx, log)
+	if !ok {
+		return
+	}
+
+	sessions, err := h.sessUseCase.GetUserSessions(reqId, h.authUseCase.ReadHeader(ctx))
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotGetSessions(err))
+		return
+	}
+
+	h.logUseCase.Report(ctx, log, msg.InfoGotSessions(sessions))
+}
+
+func (h *UserHandler) DeleteUserSession(ctx *gin.Context) {
+	actPermission := "delete_user_sessions"
+
+	log := logger.Init(ctx)
+
+	hasPerms, _ := h.isPermsGranted_getUserId(ctx, log, actPermission)
+	if !hasPerms {
+		return
+	}
+
+	reqId, ok := h.getRequestedUserId(ctx, log)
+	if !ok {
+		return
+	}
+
+	sessionId, err := strconv.Atoi(ctx.Param("session_id"))
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotConvertRequestedIDToTypeInt(err))
+		return
+	}
+
+	found, err := h.sessUseCase.RevokeUserSession(reqId, sessionId)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotDeleteSession(err))
+		return
+	}
+	if !found {
+		h.logUseCase.Report(ctx, log, msg.ErrorSessionWithRequestedIDIsNotExist())
+		return
+	}
+
+	h.logUseCase.Report(ctx, log, msg.InfoSessionRevoked())
+}
+
+// DeleteUserSessions signs the user out everywhere, the session making the
+// request is kept.
+func (h *UserHandler) DeleteUserSessions(ctx *gin.Context) {
+	actPermission := "delete_user_sessions"
+
+	log := logger.Init(ctx)
+
+	hasPerms, _ := h.isPermsGranted_getUserId(ctx, log, actPermission)
+	if !hasPerms {
+		return
+	}
+
+	reqId, ok := h.getRequestedUserId(ctx, log)
+	if !ok {
+		return
+	}
+
+	if err := h.sessUseCase.RevokeUserSessions(reqId, h.authUseCase.ReadHeader(ctx)); err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotRevokeSessions(err))
+		return
+	}
+
+	h.logUseCase.Report(ctx, log, msg.InfoUserSessionsRevoked())
+}
+
+// getRequestedUserId reads the user ID of the URL and checks that the user
+// exists.
+func (h *UserHandler) getRequestedUserId(ctx *gin.Context, log *logger.Log) (int, bool) {
+	reqId, err := h.useCase.AtoiRequestedId(ctx)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotConvertRequestedIDToTypeInt(err))
+		return -1, false
+	}
+
+	exists, err := h.useCase.IsUserExists(reqId)
+	if err != nil {
+		h.logUseCase.Report(ctx, log, msg.ErrorCannotCheckUserExistence(err))
+		return -1, false
+	}
+	if !exists {
+		h.logUseCase.Report(ctx, log, msg.ErrorUserWithRequestedIDIsNotExist())
+		return -1, false
+	}
+
+	return reqId, true
+}
+
 func (h *UserHandler) isPermsGranted_getUserId(ctx *gin.Context, log *logger.Log, permission string) (bool, int) {
 	headerToken := h.authUseCase.ReadHeader(ctx)
 	if !h.authUseCase.IsTokenExists(headerToken) {
@@ -261,7 +387,7 @@ func (h *UserHandler) isPermsGranted_getUserId(ctx *gin.Context, log *logger.Log
 		return false, -1
 	}
 
-	session, err := h.sessUseCase.GetSessionAndDate(headerToken)
+	session, err := h.sessUseCase.GetSessionAndDate(ctx, headerToken)
 	if err != nil {
 		h.logUseCase.Report(ctx, log, msg.ErrorCannotGetSessionAndDate(err))
 		return false, -1
@@ -271,7 +397,7 @@ func (h *UserHandler) isPermsGranted_getUserId(ctx *gin.Context, log *logger.Log
 		return false, -1
 	}
 
-	if timedate.IsDateExpired(session.CreationDate, h.cfg.SessionTTLHours) {
+	if timedate.IsDateExpired(session.RefreshDate, h.cfg.SessionTTLHours) {
 		if err := h.sessUseCase.DeleteSession(headerToken); err != nil {
 			h.logUseCase.Report(ctx, log, msg.ErrorCannotDeleteSession(err))
 			return false, -1
@@ -280,13 +406,7 @@ func (h *UserHandler) isPermsGranted_getUserId(ctx *gin.Context, log *logger.Log
 		return false, -1
 	}
 
-	headerNamepass, err := h.authUseCase.ParseToken(headerToken)
-	if err != nil {
-		h.logUseCase.Report(ctx, log, msg.ErrorCannotParseToken(err))
-		return false, -1
-	}
-
-	gottenUserId, err := h.useCase.GetUserId(headerNamepass.Username)
+	gottenUserId, err := h.useCase.GetUserId(session.Username)
 	if err != nil {
 		h.logUseCase.Report(ctx, log, msg.ErrorCannotCheckUserExistence(err))
 		return false, -1
@@ -300,11 +420,26 @@ func (h *UserHandler) isPermsGranted_getUserId(ctx *gin.Context, log *logger.Log
 		return false, -1
 	}
 
-	log.SessionOwner = headerNamepass.Username
+	log.SessionOwner = session.Username
+
+	// The API keys are granted the permissions of their scopes which their
+	// users still hold
+	if session.ApiKeyId != 0 {
+		granted, err := h.sessUseCase.IsApiKeyGranted(session, permission)
+		if err != nil {
+			h.logUseCase.Report(ctx, log, msg.ErrorCannotCheckApiKeyScopes(err))
+			return false, -1
+		}
+		if !granted {
+			h.logUseCase.Report(ctx, log, msg.ErrorYouHaveNotEnoughPermissions())
+			return false, -1
+		}
+		return true, gottenUserId
+	}
 
 	isSUorStaff := false
 	hasPersonalPerm := false
-	if isSUorStaff, err = h.useCase.IsUserSuperuserOrStaff(headerNamepass.Username); err != nil {
+	if isSUorStaff, err = h.useCase.IsUserSuperuserOrStaff(session.Username); err != nil {
 		h.logUseCase.Report(ctx, log, msg.ErrorCannotCheckSuperuserStaffPermissions(err))
 		return false, -1
 	}
@@ -21,5 +21,8 @@ func RegisterHTTPEndpoints(router *gin.Engine, cfg *config.Config, uc user.UserU
 		userRoute.POST("/change_password", h.UpdateUserPassword)
 		userRoute.PATCH(":id", h.PartiallyUpdateUser)
 		userRoute.DELETE(":id", h.DeleteUser)
+		userRoute.GET(":id/sessions", h.GetUserSessions)
+		userRoute.DELETE(":id/sessions", h.DeleteUserSessions)
+		userRoute.DELETE(":id/sessions/:session_id", h.DeleteUserSession)
 	}
 }
@@ -92,7 +92,7 @@ func (u *UserUseCase) BindJSONUser(ctx *gin.Context) (*user.User, error) {
 		return &usr, err
 	}
 	if usr.PasswordHash != "" {
-		usr.PasswordHash = hasher.GeneratePasswordHash(usr.PasswordHash, u.cfg.HashingPasswordSalt)
+		usr.PasswordHash = hasher.GeneratePasswordHash(usr.PasswordHash)
 	}
 	return &usr, nil
 }
@@ -10,8 +10,11 @@ let config = {
 const pc = new RTCPeerConnection(config);
 pc.onnegotiationneeded = handleNegotiationNeededEvent;
 
+// The messages may carry server text, they are added as text, not HTML
 let log = msg => {
-  document.getElementById('div').innerHTML += msg + '<br>'
+  let div = document.getElementById('div')
+  div.appendChild(document.createTextNode(msg))
+  div.appendChild(document.createElement('br'))
 }
 
 pc.ontrack = function(event) {
@@ -28,11 +31,120 @@ async function handleNegotiationNeededEvent() {
   getRemoteSdp();
 }
 
+// The tokens are passed once in the URL fragment, which is not sent to the
+// server, and kept for this tab only. The fragment is removed right away so
+// the tokens do not stay in the address bar and the history.
+let fragment = new URLSearchParams(window.location.hash.substring(1));
+if (fragment.get('token')) {
+  sessionStorage.setItem('token', fragment.get('token'));
+  sessionStorage.setItem('refreshToken', fragment.get('refreshToken') || '');
+  history.replaceState(null, '', window.location.pathname + window.location.search);
+}
+let token = sessionStorage.getItem('token');
+
 $(document).ready(function() {
   $('#' + suuid).addClass('active');
   getCodecInfo();
+  subscribeStreamEvents();
 });
 
+function streamListItem(name) {
+  let item = $('#streamList a').filter(function() {
+    return $(this).attr('name') === name;
+  });
+  if (item.length === 0) {
+    item = $('<a class="list-group-item list-group-item-action d-flex justify-content-between align-items-center"></a>')
+      .attr({href: name, id: name, name: name})
+      .text(name)
+      .append('<span class="badge badge-pill"></span>');
+    $('#streamList').append(item);
+    $('#streamList a').sort(function(a, b) {
+      return $(a).attr('name').localeCompare($(b).attr('name'));
+    }).appendTo('#streamList');
+  }
+  return item;
+}
+
+function updateStreamState(state) {
+  let item = streamListItem(state.stream);
+  let badge = item.find('.badge');
+  if (state.online) {
+    badge.removeClass('badge-secondary').addClass('badge-success').text(state.viewers + ' viewers');
+  } else {
+    badge.removeClass('badge-success').addClass('badge-secondary').text('offline');
+  }
+  item.attr('title', (state.codecs || []).join(', '));
+}
+
+// refreshTokens renews the access token, which lives only a few minutes,
+// with the refresh token. The refresh token is rotated on every use.
+function refreshTokens() {
+  let refreshToken = sessionStorage.getItem('refreshToken');
+  if (!refreshToken) {
+    return $.Deferred().reject().promise();
+  }
+  return $.ajax({
+    method: 'POST',
+    url: '/auth/refresh',
+    contentType: 'application/json',
+    data: JSON.stringify({refreshToken: refreshToken})
+  }).then(function(data) {
+    token = data.token;
+    sessionStorage.setItem('token', data.token);
+    sessionStorage.setItem('refreshToken', data.refreshToken);
+  }, function() {
+    token = null;
+    sessionStorage.removeItem('token');
+    sessionStorage.removeItem('refreshToken');
+    return $.Deferred().reject().promise();
+  });
+}
+
+// getEventsTicket asks for a single-use ticket for the events, the token
+// itself is never put in the URL. An expired token is refreshed once.
+function getEventsTicket(retried) {
+  return $.ajax({
+    method: 'POST',
+    url: '/events/ticket',
+    headers: {Authorization: token}
+  }).then(function(data) {
+    return data.ticket;
+  }, function(xhr) {
+    if (retried || xhr.status !== 403) {
+      return $.Deferred().reject().promise();
+    }
+    return refreshTokens().then(function() {
+      return getEventsTicket(true);
+    });
+  });
+}
+
+function subscribeStreamEvents() {
+  if (!window.EventSource || !token) {
+    return;
+  }
+  getEventsTicket(false).then(function(ticket) {
+    let events = new EventSource('/events/streams?ticket=' + encodeURIComponent(ticket));
+    events.addEventListener('snapshot', function(e) {
+      $('#streamList a').each(function() {
+        updateStreamState({stream: $(this).attr('name'), online: false});
+      });
+      JSON.parse(e.data).forEach(updateStreamState);
+    });
+    ['online', 'offline', 'codec', 'viewers'].forEach(function(type) {
+      events.addEventListener(type, function(e) {
+        updateStreamState(JSON.parse(e.data).state);
+      });
+    });
+    // The ticket is used up, so the connection is made again with a new one
+    events.onerror = function() {
+      events.close();
+      setTimeout(subscribeStreamEvents, 1000);
+    };
+  }, function() {
+    log('Live stream status is not available, sign in again');
+  });
+}
 
 function getCodecInfo() {
   $.get("/stream/codec/" + suuid, function(data) {
@@ -57,7 +169,8 @@ let sendChannel = null;
 function getRemoteSdp() {
   $.post("/stream/receiver/"+ suuid, {
     suuid: suuid,
-    data: btoa(pc.localDescription.sdp)
+    data: btoa(pc.localDescription.sdp),
+    token: token || ''
   }, function(data) {
     console.log(data)
     try {
@@ -69,5 +182,7 @@ function getRemoteSdp() {
     } catch (e) {
       console.warn(e);
     }
+  }).fail(function(xhr) {
+    log('Stream rejected: ' + xhr.responseText);
   });
 }